@@ -0,0 +1,120 @@
+package pars
+
+const arenaChunkSize = 1024
+
+// Arena allocates the Children of Results in bulk to reduce the garbage
+// created while parsing. Attach an Arena to a State with SetArena and the
+// combinators will allocate the Children of their results from the Arena.
+// Once the results are no longer needed, Reset the Arena to reuse its memory
+// for the next parse. Results which must outlive the Arena should be copied
+// out with Result.Clone before calling Reset.
+//
+// The Children of the results of failed attempts are reused immediately, so a
+// Map must not retain the Children of a Result beyond the parse. An Arena
+// must not be shared by States which are used concurrently.
+type Arena struct {
+	chunks  [][]Result
+	chunk   int
+	off     int
+	scratch []Result
+}
+
+// NewArena creates a new empty Arena.
+func NewArena() *Arena { return &Arena{} }
+
+// arenaMark is a position in an Arena.
+type arenaMark struct {
+	chunk int
+	off   int
+}
+
+func (a *Arena) mark() arenaMark { return arenaMark{a.chunk, a.off} }
+
+func clearResults(v []Result) {
+	for i := range v {
+		v[i] = Result{}
+	}
+}
+
+// alloc returns a slice of n zeroed results.
+func (a *Arena) alloc(n int) []Result {
+	for a.chunk < len(a.chunks) {
+		c := a.chunks[a.chunk]
+		if a.off+n <= len(c) {
+			v := c[a.off : a.off+n : a.off+n]
+			a.off += n
+			return v
+		}
+		a.chunk++
+		a.off = 0
+	}
+	size := arenaChunkSize
+	if size < n {
+		size = n
+	}
+	a.chunks = append(a.chunks, make([]Result, size))
+	a.off = n
+	return a.chunks[a.chunk][:n:n]
+}
+
+// rollback releases all results allocated since the given mark.
+func (a *Arena) rollback(m arenaMark) {
+	for a.chunk > m.chunk {
+		clearResults(a.chunks[a.chunk][:a.off])
+		a.chunk--
+		a.off = len(a.chunks[a.chunk])
+	}
+	clearResults(a.chunks[a.chunk][m.off:a.off])
+	a.off = m.off
+}
+
+// Reset releases all results allocated from the Arena so its memory can be
+// reused. All Results parsed with the Arena are invalidated.
+func (a *Arena) Reset() {
+	if len(a.chunks) > 0 {
+		a.rollback(arenaMark{0, 0})
+	}
+}
+
+// collector gathers the results of a Parser which matches repeatedly. With
+// an Arena, the results are gathered in the scratch space of the Arena which
+// is shared in a last in, first out manner by nested collectors.
+type collector struct {
+	arena *Arena
+	base  int
+	v     []Result
+}
+
+func newCollector(a *Arena) collector {
+	if a == nil {
+		return collector{v: []Result{}}
+	}
+	return collector{arena: a, base: len(a.scratch)}
+}
+
+func (c *collector) add(r Result) {
+	if c.arena == nil {
+		c.v = append(c.v, r)
+		return
+	}
+	c.arena.scratch = append(c.arena.scratch, r)
+}
+
+func (c *collector) results() []Result {
+	if c.arena == nil {
+		return c.v
+	}
+	a := c.arena
+	v := a.alloc(len(a.scratch) - c.base)
+	copy(v, a.scratch[c.base:])
+	clearResults(a.scratch[c.base:])
+	a.scratch = a.scratch[:c.base]
+	return v
+}
+
+// SetArena sets the Arena from which the results parsed from the state are
+// allocated. Setting a nil Arena will allocate the results on the heap.
+func (s *State) SetArena(a *Arena) { s.arena = a }
+
+// Arena returns the Arena set for the state, or nil if there is none.
+func (s *State) Arena() *Arena { return s.arena }
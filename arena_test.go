@@ -0,0 +1,116 @@
+package pars
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-ascii/ascii"
+)
+
+var arenaParser = Many(Any(
+	Seq(Word(ascii.IsLetter), '=', Delim(Int, ','), ';'),
+	Seq(Word(ascii.IsLetter), ';'),
+))
+
+const arenaInput = "a=1,2,3;b;c=4;d;e=5,6;"
+
+func TestArena(t *testing.T) {
+	e, err := arenaParser.Parse(FromString(arenaInput))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	arena := NewArena()
+	for i := 0; i < 3; i++ {
+		state := FromString(arenaInput)
+		state.SetArena(arena)
+		result, err := arenaParser.Parse(state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !same(result, e) {
+			t.Fatalf("result = %v, want %v", result, e)
+		}
+		clone := result.Clone()
+		arena.Reset()
+		if !same(clone, e) {
+			t.Errorf("clone = %v, want %v", clone, e)
+		}
+		if result.Children[0].Children != nil && result.Children[0].Children[0].Token != nil {
+			t.Errorf("result was not released by Reset")
+		}
+		if len(arena.chunks) != 1 {
+			t.Errorf("arena has %d chunks, want 1", len(arena.chunks))
+		}
+	}
+
+	// A failed attempt releases the results allocated by the attempt.
+	state := FromString("a;b=1,2,x")
+	state.SetArena(arena)
+	if _, err := arenaParser.Parse(state); err != nil {
+		t.Fatal(err)
+	}
+	if arena.off != 3 || len(arena.scratch) != 0 {
+		t.Errorf("arena offset %d with %d scratch results, want 3 and 0", arena.off, len(arena.scratch))
+	}
+	arena.Reset()
+
+	// Slices larger than a chunk are allocated separately.
+	long := strings.Repeat("a;", arenaChunkSize+1)
+	state = FromString(long)
+	state.SetArena(arena)
+	result, err := arenaParser.Parse(state)
+	if err != nil || len(result.Children) != arenaChunkSize+1 {
+		t.Fatalf("parsed %d records with error %v", len(result.Children), err)
+	}
+	arena.Reset()
+	if arena.chunk != 0 || arena.off != 0 {
+		t.Errorf("arena at chunk %d offset %d after Reset", arena.chunk, arena.off)
+	}
+}
+
+func TestArenaAllocs(t *testing.T) {
+	in := []byte(arenaInput)
+	run := func(arena *Arena) float64 {
+		return testing.AllocsPerRun(100, func() {
+			state := FromBytes(in)
+			state.SetArena(arena)
+			arenaParser(state, &Result{})
+			if arena != nil {
+				arena.Reset()
+			}
+		})
+	}
+
+	// The remaining allocations are for the errors and values.
+	heap, arena := run(nil), run(NewArena())
+	if heap-arena < 10 {
+		t.Errorf("%v allocations with an arena, want fewer than %v", arena, heap-10)
+	}
+}
+
+func TestResultClone(t *testing.T) {
+	p := []byte("abc")
+	r := Result{Children: []Result{{Token: p}, {Value: 42, Name: "n"}}}
+	c := r.Clone()
+	if !same(c, r) {
+		t.Fatalf("clone = %v, want %v", c, r)
+	}
+	p[0] = 'x'
+	r.Children[1].Value = nil
+	if string(c.Children[0].Token) != "abc" || c.Children[1].Value != 42 {
+		t.Errorf("clone shares memory with the original: %v", c)
+	}
+}
+
+func TestScannerArena(t *testing.T) {
+	arena := NewArena()
+	s := NewScanner(strings.NewReader("a=1\nb=2\nc=3\n"), record)
+	s.SetArena(arena)
+	if v, e := scanAll(s), []string{"a:1", "b:2", "c:3"}; !same(v, e) {
+		t.Errorf("records = %v, want %v", v, e)
+	}
+	if arena.chunk != 0 || arena.off > 4 {
+		t.Errorf("arena was not reset between records")
+	}
+}
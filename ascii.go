@@ -42,7 +42,7 @@ func Filter(filter ascii.Filter) Parser {
 	name := fmt.Sprintf("Filter(%s)", rep)
 	what := fmt.Sprintf("expected to match filter `%s`", rep)
 
-	return func(state *State, result *Result) error {
+	return describe(&Node{Kind: KindFilter, Name: rep}, func(state *State, result *Result) error {
 		c, err := Next(state)
 		if err != nil {
 			return NewNestedError(name, err)
@@ -50,10 +50,10 @@ func Filter(filter ascii.Filter) Parser {
 		if !filter(c) {
 			return NewError(what, state.Position())
 		}
+		result.SetToken(state.Buffer())
 		state.Advance()
-		result.SetToken([]byte{c})
 		return nil
-	}
+	})
 }
 
 // Word creates a Parser which will attempt to match a group of bytes which
@@ -64,7 +64,7 @@ func Word(filter ascii.Filter) Parser {
 	rep := f.Name()
 	what := fmt.Sprintf("expected word of `%s`", rep)
 
-	return func(state *State, result *Result) error {
+	return describe(&Node{Kind: KindWord, Name: rep}, func(state *State, result *Result) error {
 		state.Push()
 		c, err := Next(state)
 		for err == nil && filter(c) {
@@ -77,5 +77,5 @@ func Word(filter ascii.Filter) Parser {
 		}
 		result.SetToken(p)
 		return nil
-	}
+	})
 }
@@ -1,5 +1,7 @@
 package pars
 
+import "io"
+
 // Epsilon will do nothing.
 func Epsilon(state *State, result *Result) error {
 	return nil
@@ -14,12 +16,17 @@ func Head(state *State, result *Result) error {
 }
 
 // End will match if the state buffer has been exhausted and no more bytes can
-// be read from the io.Reader object.
+// be read from the io.Reader object. For a push state which is still open,
+// End returns ErrNeedMore as more bytes may yet be fed.
 func End(state *State, result *Result) error {
-	if state.Request(1) == nil {
+	switch err := state.Request(1); err {
+	case nil:
 		return NewError("state is not at end", state.Position())
+	case io.EOF:
+		return nil
+	default:
+		return err
 	}
-	return nil
 }
 
 // Cut will disable backtracking beyond the cut position.
@@ -0,0 +1,240 @@
+package pars
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// fixedParser creates a Parser which will match the given number of bytes and
+// convert them to a value with the given function.
+func fixedParser(name string, n int, convert func(p []byte) interface{}) Parser {
+	return primitive(name, func(state *State, result *Result) error {
+		if err := state.Request(n); err != nil {
+			return NewNestedError(name, err)
+		}
+		result.SetValue(convert(state.Buffer()))
+		state.Advance()
+		return nil
+	})
+}
+
+// Parsers for matching fixed-width binary integers. The BE and LE suffixes
+// denote the big endian and little endian byte orders respectively. The
+// values are of the Go type of the same name.
+var (
+	Uint8 = fixedParser("Uint8", 1, func(p []byte) interface{} { return p[0] })
+	Int8  = fixedParser("Int8", 1, func(p []byte) interface{} { return int8(p[0]) })
+
+	Uint16BE = fixedParser("Uint16BE", 2, func(p []byte) interface{} { return binary.BigEndian.Uint16(p) })
+	Uint16LE = fixedParser("Uint16LE", 2, func(p []byte) interface{} { return binary.LittleEndian.Uint16(p) })
+	Uint32BE = fixedParser("Uint32BE", 4, func(p []byte) interface{} { return binary.BigEndian.Uint32(p) })
+	Uint32LE = fixedParser("Uint32LE", 4, func(p []byte) interface{} { return binary.LittleEndian.Uint32(p) })
+	Uint64BE = fixedParser("Uint64BE", 8, func(p []byte) interface{} { return binary.BigEndian.Uint64(p) })
+	Uint64LE = fixedParser("Uint64LE", 8, func(p []byte) interface{} { return binary.LittleEndian.Uint64(p) })
+
+	Int16BE = fixedParser("Int16BE", 2, func(p []byte) interface{} { return int16(binary.BigEndian.Uint16(p)) })
+	Int16LE = fixedParser("Int16LE", 2, func(p []byte) interface{} { return int16(binary.LittleEndian.Uint16(p)) })
+	Int32BE = fixedParser("Int32BE", 4, func(p []byte) interface{} { return int32(binary.BigEndian.Uint32(p)) })
+	Int32LE = fixedParser("Int32LE", 4, func(p []byte) interface{} { return int32(binary.LittleEndian.Uint32(p)) })
+	Int64BE = fixedParser("Int64BE", 8, func(p []byte) interface{} { return int64(binary.BigEndian.Uint64(p)) })
+	Int64LE = fixedParser("Int64LE", 8, func(p []byte) interface{} { return int64(binary.LittleEndian.Uint64(p)) })
+)
+
+// Parsers for matching IEEE 754 binary floating point numbers. The values are
+// of the Go type float32 or float64.
+var (
+	Float32BE = fixedParser("Float32BE", 4, func(p []byte) interface{} {
+		return math.Float32frombits(binary.BigEndian.Uint32(p))
+	})
+	Float32LE = fixedParser("Float32LE", 4, func(p []byte) interface{} {
+		return math.Float32frombits(binary.LittleEndian.Uint32(p))
+	})
+	Float64BE = fixedParser("Float64BE", 8, func(p []byte) interface{} {
+		return math.Float64frombits(binary.BigEndian.Uint64(p))
+	})
+	Float64LE = fixedParser("Float64LE", 8, func(p []byte) interface{} {
+		return math.Float64frombits(binary.LittleEndian.Uint64(p))
+	})
+)
+
+var errVarintOverflow = errors.New("varint overflows a 64-bit integer")
+
+// scanVarint returns the bytes of the varint at the current offset.
+func scanVarint(state *State) ([]byte, error) {
+	for i := 1; i <= binary.MaxVarintLen64; i++ {
+		if err := state.Request(i); err != nil {
+			return nil, err
+		}
+		if p := state.Buffer(); p[i-1] < 0x80 {
+			return p, nil
+		}
+	}
+	return nil, errVarintOverflow
+}
+
+// Uvarint will match an unsigned LEB128 varint as used by Protocol Buffers
+// and encoding/binary, and convert it to a uint64.
+func Uvarint(state *State, result *Result) error {
+	p, err := scanVarint(state)
+	if err != nil {
+		return NewNestedError("Uvarint", err)
+	}
+	v, n := binary.Uvarint(p)
+	if n <= 0 {
+		return NewNestedError("Uvarint", errVarintOverflow)
+	}
+	result.SetValue(v)
+	state.Advance()
+	return nil
+}
+
+// Varint will match a zigzag encoded signed varint as used by the sint types
+// of Protocol Buffers and encoding/binary, and convert it to an int64.
+func Varint(state *State, result *Result) error {
+	p, err := scanVarint(state)
+	if err != nil {
+		return NewNestedError("Varint", err)
+	}
+	v, n := binary.Varint(p)
+	if n <= 0 {
+		return NewNestedError("Varint", errVarintOverflow)
+	}
+	result.SetValue(v)
+	state.Advance()
+	return nil
+}
+
+func init() {
+	declare("Uvarint", Uvarint)
+	declare("Varint", Varint)
+}
+
+// Take creates a Parser which will match the given number of bytes.
+func Take(n int) Parser {
+	name := fmt.Sprintf("Take(%d)", n)
+	return primitive(name, func(state *State, result *Result) error {
+		if err := state.Request(n); err != nil {
+			return NewNestedError(name, err)
+		}
+		result.SetToken(state.Buffer())
+		state.Advance()
+		return nil
+	})
+}
+
+// lengthValue converts the value of a length Parser to an int.
+func lengthValue(v interface{}) (int, bool) {
+	var n int64
+	switch v := v.(type) {
+	case int:
+		n = int64(v)
+	case int8:
+		n = int64(v)
+	case int16:
+		n = int64(v)
+	case int32:
+		n = int64(v)
+	case int64:
+		n = v
+	case uint:
+		n = int64(v)
+	case uint8:
+		n = int64(v)
+	case uint16:
+		n = int64(v)
+	case uint32:
+		n = int64(v)
+	case uint64:
+		if v > math.MaxInt32 {
+			return 0, false
+		}
+		n = int64(v)
+	default:
+		return 0, false
+	}
+	if n < 0 || n > math.MaxInt32 {
+		return 0, false
+	}
+	return int(n), true
+}
+
+// LengthPrefixed creates a Parser which will match a length with the first
+// Parser and then match the second Parser against exactly that many of the
+// following bytes. The value of the length must be an integer, such as the
+// value of Uint16BE or Uvarint, and must not exceed the given maximum, which
+// is checked before any of the bytes are read. If the second Parser is nil,
+// the bytes will be the Token of the result. Otherwise, the result of the
+// second Parser is used and it is an error for the second Parser to leave any
+// of the bytes unmatched. The state is restored to the position prior to
+// matching on an error.
+func LengthPrefixed(q, body interface{}, max int) Parser {
+	p := AsParser(q)
+	var b Parser
+	children := []Parser{p}
+	if body != nil {
+		b = AsParser(body)
+		children = append(children, b)
+	}
+
+	p = direct(p)
+	if b != nil {
+		b = direct(b)
+	}
+	return describe(&Node{Kind: KindLengthPrefixed, children: children}, func(state *State, result *Result) error {
+		state.Push()
+		if err := p(state, result); err != nil {
+			state.Pop()
+			return NewNestedError("LengthPrefixed", err)
+		}
+		n, ok := lengthValue(result.Value)
+		if !ok {
+			state.Pop()
+			return NewError(fmt.Sprintf("invalid length %v", result.Value), state.Position())
+		}
+		if n > max {
+			state.Pop()
+			return NewError(fmt.Sprintf("length %d exceeds the maximum of %d", n, max), state.Position())
+		}
+
+		// Read the bytes in pieces so a length beyond the end of the input
+		// fails after buffering only the bytes of the input.
+		for m := 0; ; {
+			if m += state.size; m > n {
+				m = n
+			}
+			if err := state.Request(m); err != nil {
+				state.Pop()
+				return NewNestedError("LengthPrefixed", err)
+			}
+			if m == n {
+				break
+			}
+		}
+		if b == nil {
+			result.SetToken(state.Buffer())
+			state.Advance()
+			state.Drop()
+			return nil
+		}
+
+		// Match the body against a state limited to the prefixed bytes.
+		sub := FromBytes(state.Buffer())
+		sub.pos, sub.base, sub.arena = state.pos, state.base+state.off, state.arena
+		sub.depth, sub.memo = state.depth, state.memo
+		*result = Result{}
+		if err := b(sub, result); err != nil {
+			state.Pop()
+			return NewNestedError("LengthPrefixed", err)
+		}
+		if err := End(sub, nil); err != nil {
+			state.Pop()
+			return NewNestedError("LengthPrefixed", err)
+		}
+		state.memo = sub.memo
+		state.Advance()
+		state.Drop()
+		return nil
+	})
+}
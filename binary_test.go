@@ -0,0 +1,180 @@
+package pars
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+var binaryTests = []struct {
+	name string
+	p    Parser
+	in   []byte
+	out  interface{}
+}{
+	{"Uint8", Uint8, []byte{0xfe}, uint8(0xfe)},
+	{"Int8", Int8, []byte{0xfe}, int8(-2)},
+	{"Uint16BE", Uint16BE, []byte{0x12, 0x34}, uint16(0x1234)},
+	{"Uint16LE", Uint16LE, []byte{0x12, 0x34}, uint16(0x3412)},
+	{"Uint32BE", Uint32BE, []byte{1, 2, 3, 4}, uint32(0x01020304)},
+	{"Uint32LE", Uint32LE, []byte{1, 2, 3, 4}, uint32(0x04030201)},
+	{"Uint64BE", Uint64BE, []byte{1, 2, 3, 4, 5, 6, 7, 8}, uint64(0x0102030405060708)},
+	{"Uint64LE", Uint64LE, []byte{1, 2, 3, 4, 5, 6, 7, 8}, uint64(0x0807060504030201)},
+	{"Int16BE", Int16BE, []byte{0xff, 0xfe}, int16(-2)},
+	{"Int16LE", Int16LE, []byte{0xfe, 0xff}, int16(-2)},
+	{"Int32BE", Int32BE, []byte{0xff, 0xff, 0xff, 0xfe}, int32(-2)},
+	{"Int32LE", Int32LE, []byte{0xfe, 0xff, 0xff, 0xff}, int32(-2)},
+	{"Int64BE", Int64BE, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe}, int64(-2)},
+	{"Int64LE", Int64LE, []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, int64(-2)},
+	{"Float32BE", Float32BE, []byte{0x3f, 0xc0, 0, 0}, float32(1.5)},
+	{"Float32LE", Float32LE, []byte{0, 0, 0xc0, 0x3f}, float32(1.5)},
+	{"Float64BE", Float64BE, []byte{0x3f, 0xf8, 0, 0, 0, 0, 0, 0}, float64(1.5)},
+	{"Float64LE", Float64LE, []byte{0, 0, 0, 0, 0, 0, 0xf8, 0x3f}, float64(1.5)},
+	{"Uvarint", Uvarint, []byte{0xac, 0x02}, uint64(300)},
+	{"Uvarint", Uvarint, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}, uint64(math.MaxUint64)},
+	{"Varint", Varint, []byte{0x03}, int64(-2)},
+	{"Varint", Varint, []byte{0xac, 0x02}, int64(150)},
+}
+
+func TestBinary(t *testing.T) {
+	for _, tt := range binaryTests {
+		state := FromBytes(append(tt.in, 0))
+		result, err := tt.p.Parse(state)
+		if err != nil {
+			t.Errorf("%s(% x): %v", tt.name, tt.in, err)
+			continue
+		}
+		if !same(result.Value, tt.out) {
+			t.Errorf("%s(% x) = %#v, want %#v", tt.name, tt.in, result.Value, tt.out)
+		}
+		if state.Offset() != len(tt.in) {
+			t.Errorf("%s(% x) consumed %d bytes, want %d", tt.name, tt.in, state.Offset(), len(tt.in))
+		}
+
+		// Short inputs fail without consuming any input.
+		state = FromBytes(tt.in[:len(tt.in)-1])
+		if tt.p(state, &Result{}) == nil || state.Offset() != 0 {
+			t.Errorf("%s(% x): expected error at the beginning", tt.name, tt.in[:len(tt.in)-1])
+		}
+	}
+
+	overflow := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}
+	for _, p := range []Parser{Uvarint, Varint} {
+		if err := p(FromBytes(overflow), &Result{}); !errors.Is(err, errVarintOverflow) {
+			t.Errorf("expected %v, got %v", errVarintOverflow, err)
+		}
+		if err := p(FromBytes(overflow[9:]), &Result{}); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	p := make([]byte, binary.MaxVarintLen64)
+	p = p[:binary.PutUvarint(p, 1<<63)]
+	if result, err := Parser(Uvarint).Parse(FromBytes(p)); err != nil || result.Value != uint64(1<<63) {
+		t.Errorf("Uvarint(% x) = %v, %v", p, result.Value, err)
+	}
+}
+
+func TestTake(t *testing.T) {
+	state := FromString("hello")
+	result, err := Take(3).Parse(state)
+	if err != nil || string(result.Token) != "hel" {
+		t.Errorf("Take(3) = %q, %v", result.Token, err)
+	}
+	if err := Take(3)(state, &Result{}); !errors.Is(err, io.EOF) || state.Offset() != 3 {
+		t.Errorf("Take(3) = %v at %d, want %v at 3", err, state.Offset(), io.EOF)
+	}
+}
+
+func TestLengthPrefixed(t *testing.T) {
+	in := []byte{3, 'a', 'b', 'c', 'd'}
+	result, err := LengthPrefixed(Uint8, nil, 255).Parse(FromBytes(in))
+	if err != nil || string(result.Token) != "abc" {
+		t.Errorf("LengthPrefixed(Uint8, nil, 255) = %q, %v", result.Token, err)
+	}
+
+	in = []byte{0, 4, 0, 1, 0, 2, 9}
+	state := FromBytes(in)
+	result, err = LengthPrefixed(Uint16BE, Many(Uint16BE), 1024).Parse(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := []interface{}{result.Children[0].Value, result.Children[1].Value}; !same(v, []interface{}{uint16(1), uint16(2)}) {
+		t.Errorf("LengthPrefixed(Uint16BE, Many(Uint16BE), 1024) = %v", v)
+	}
+	if state.Offset() != 6 {
+		t.Errorf("consumed %d bytes, want 6", state.Offset())
+	}
+
+	// The body must consume all of the bytes and may not read beyond them.
+	for _, tt := range []struct {
+		p  Parser
+		in []byte
+	}{
+		{LengthPrefixed(Uint8, Uint8, 255), []byte{2, 1, 2}},
+		{LengthPrefixed(Uint8, Uint16BE, 255), []byte{1, 1, 2}},
+		{LengthPrefixed(Uint8, nil, 255), []byte{2, 1}},
+		{LengthPrefixed(Int8, nil, 255), []byte{0xff, 1}},
+		{LengthPrefixed(Take(1), nil, 255), []byte{1, 1}},
+	} {
+		state := FromBytes(tt.in)
+		if tt.p(state, &Result{}) == nil || state.Offset() != 0 {
+			t.Errorf("% x: expected error at the beginning", tt.in)
+		}
+	}
+
+	// Errors in the body are reported at their position in the input.
+	_, err = Seq(Uint8, LengthPrefixed(Uint8, Seq(Uint8, 'x'), 255)).Parse(FromBytes([]byte{0, 2, 1, 'y'}))
+	var pe Error
+	if !errors.As(err, &pe) || pe.Position() != (Position{0, 3}) {
+		t.Errorf("error = %v, want error at %v", err, Position{0, 3})
+	}
+}
+
+func TestLengthPrefixedLimits(t *testing.T) {
+	in := "\x7f\xff\xff\xffabc"
+
+	// A length beyond the maximum is rejected before reading the bytes.
+	state := NewState(strings.NewReader(in))
+	var pe Error
+	if _, err := LengthPrefixed(Uint32BE, nil, 1<<20).Parse(state); !errors.As(err, &pe) || pe.Position() != (Position{0, 0}) {
+		t.Errorf("LengthPrefixed(Uint32BE, nil, 1<<20) = %v, want error at %v", err, Position{0, 0})
+	}
+
+	// A length beyond the end of the input fails having buffered the input.
+	state = NewState(strings.NewReader(in))
+	before := runtime.MemStats{}
+	runtime.ReadMemStats(&before)
+	_, err := LengthPrefixed(Uint32BE, nil, math.MaxInt32).Parse(state)
+	after := runtime.MemStats{}
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, io.EOF) || state.Offset() != 0 {
+		t.Errorf("LengthPrefixed(Uint32BE, nil, math.MaxInt32) = %v at %d, want %v at 0", err, state.Offset(), io.EOF)
+	}
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("allocated %d bytes for a %d byte input", n, len(in))
+	}
+
+	// Nesting limits and memoized results carry over to the body.
+	var parens Parser
+	parens = Nested(Any('x', Seq('(', &parens, ')')), 3)
+	p := Nested(LengthPrefixed(Uint8, &parens, 255), 3)
+	if _, err := p.Parse(FromString("\x03(x)")); err != nil {
+		t.Errorf("LengthPrefixed(Uint8, Nested): %v", err)
+	}
+	if _, err := p.Parse(FromString("\x05((x))")); err == nil {
+		t.Errorf("LengthPrefixed(Uint8, Nested) matched beyond the nesting limit")
+	}
+	calls := 0
+	key := Memoize(func(state *State, result *Result) error {
+		calls++
+		return Uint8(state, result)
+	})
+	p = Any(Seq(LengthPrefixed(Uint8, key, 255), 'a'), Seq(Uint8, key, 'b'))
+	if _, err := p.Parse(FromString("\x01\x02b")); err != nil || calls != 1 {
+		t.Errorf("memoized body applied %d times with error %v, want once", calls, err)
+	}
+}
@@ -0,0 +1,124 @@
+package pars
+
+import (
+	"fmt"
+	"io"
+)
+
+// The bit-level parsers read the bits of each byte from the most significant
+// bit to the least significant bit. The number of bits of the current byte
+// which have been consumed is kept by the state alongside the position and is
+// reported by State.Bit, so it is restored by Pop like the position. Byte-level
+// parsers applied to a partially consumed byte will treat it as a whole byte,
+// so use Align to skip the remaining bits of the byte explicitly.
+
+// peekBits returns the next n bits of the state without consuming them.
+func peekBits(state *State, n int) (uint64, error) {
+	bit := state.bit
+	if err := state.Request((bit + n + 7) / 8); err != nil {
+		return 0, err
+	}
+	p := state.Buffer()
+	v := uint64(0)
+	for i := bit; i < bit+n; i++ {
+		v = v<<1 | uint64(p[i/8]>>(7-i%8)&1)
+	}
+	return v, nil
+}
+
+// bitError returns an error for a bit-level Parser which failed to read the
+// given number of bits, giving the bit of the state as well as the position
+// if the input has ended.
+func bitError(state *State, name string, n int, err error) error {
+	if err != io.EOF {
+		return NewNestedError(name, err)
+	}
+	what := fmt.Sprintf("expected %d bits", n)
+	if n == 1 {
+		what = "expected a bit"
+	}
+	return Error{what, state.pos, state.bit}
+}
+
+// skipBits consumes the n bits returned by peekBits.
+func skipBits(state *State, n int) {
+	total := state.bit + n
+	state.Request(total / 8)
+	state.Advance()
+	state.bit = total % 8
+	state.autoclear()
+}
+
+// Bits creates a Parser which will match the given number of bits, which must
+// be between 1 and 64, and convert them to a uint64 in big endian bit order.
+func Bits(n int) Parser {
+	if n < 1 || n > 64 {
+		panic(fmt.Errorf("invalid number of bits %d", n))
+	}
+	name := fmt.Sprintf("Bits(%d)", n)
+
+	return primitive(name, func(state *State, result *Result) error {
+		v, err := peekBits(state, n)
+		if err != nil {
+			return bitError(state, name, n, err)
+		}
+		skipBits(state, n)
+		result.SetValue(v)
+		return nil
+	})
+}
+
+// Flag will match a single bit and convert it to a bool.
+func Flag(state *State, result *Result) error {
+	v, err := peekBits(state, 1)
+	if err != nil {
+		return bitError(state, "Flag", 1, err)
+	}
+	skipBits(state, 1)
+	result.SetValue(v == 1)
+	return nil
+}
+
+// Align will skip the remaining bits of a partially consumed byte. It always
+// matches, as the byte has already been read, and does nothing at a byte
+// boundary.
+func Align(state *State, result *Result) error {
+	if state.bit != 0 {
+		skipBits(state, 8-state.bit)
+	}
+	return nil
+}
+
+func init() {
+	declare("Flag", Flag)
+	declare("Align", Align)
+}
+
+// BitsEnum creates a Parser which will match the given number of bits like
+// Bits and convert the value to the corresponding value of the given map.
+// The Parser will fail without consuming the bits if the value is not in the
+// map. The map is copied so it may be modified afterwards.
+func BitsEnum(n int, values map[uint64]interface{}) Parser {
+	if n < 1 || n > 64 {
+		panic(fmt.Errorf("invalid number of bits %d", n))
+	}
+	name := fmt.Sprintf("BitsEnum(%d)", n)
+	m := make(map[uint64]interface{}, len(values))
+	for k, v := range values {
+		m[k] = v
+	}
+
+	return primitive(name, func(state *State, result *Result) error {
+		v, err := peekBits(state, n)
+		if err != nil {
+			return bitError(state, name, n, err)
+		}
+		e, ok := m[v]
+		if !ok {
+			return Error{fmt.Sprintf("unexpected value %d", v), state.pos, state.bit}
+		}
+		skipBits(state, n)
+		result.SetValue(e)
+		return nil
+	})
+}
@@ -0,0 +1,119 @@
+package pars
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBits(t *testing.T) {
+	// The first two bytes of an IPv4 header followed by a DNS flags word.
+	in := []byte{0x45, 0x28, 0x81, 0x80}
+	version, ihl := Bits(4), Bits(4)
+	dscp, ecn := Bits(6), Bits(2)
+	opcode := BitsEnum(4, map[uint64]interface{}{0: "QUERY", 1: "IQUERY", 2: "STATUS"})
+	flags := Seq(Flag, opcode, Flag, Flag, Flag, Flag, Bits(3), Bits(4))
+
+	result, err := Seq(version, ihl, dscp, ecn, flags).Parse(FromBytes(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := []interface{}{}
+	for _, child := range result.Children[:4] {
+		v = append(v, child.Value)
+	}
+	for _, child := range result.Children[4].Children {
+		v = append(v, child.Value)
+	}
+	e := []interface{}{
+		uint64(4), uint64(5), uint64(10), uint64(0),
+		true, "QUERY", false, false, true, true, uint64(0), uint64(0),
+	}
+	if !same(v, e) {
+		t.Errorf("fields = %v, want %v", v, e)
+	}
+
+	state := FromBytes([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f})
+	result, err = Seq(Bits(1), Bits(64)).Parse(state)
+	if err != nil || result.Children[1].Value != uint64(1<<64-2) {
+		t.Errorf("Bits(64) = %v, %v", result.Children[1].Value, err)
+	}
+	if pos, bit := state.Position(), state.Bit(); pos != (Position{0, 8}) || bit != 1 {
+		t.Errorf("state.Position(), state.Bit() = %v, %d", pos, bit)
+	}
+}
+
+func TestBitsBacktrack(t *testing.T) {
+	state := FromBytes([]byte{0xa5, 'x'})
+	if err := Bits(3)(state, &Result{}); err != nil {
+		t.Fatal(err)
+	}
+	state.Push()
+	if err := Bits(7)(state, &Result{}); err != nil {
+		t.Fatal(err)
+	}
+	if pos, bit := state.Position(), state.Bit(); pos != (Position{0, 1}) || bit != 2 {
+		t.Errorf("state.Position(), state.Bit() = %v, %d", pos, bit)
+	}
+	state.Pop()
+	if pos, bit := state.Position(), state.Bit(); pos != (Position{0, 0}) || bit != 3 {
+		t.Errorf("state.Position(), state.Bit() = %v, %d after Pop", pos, bit)
+	}
+
+	// Mismatches and short inputs do not consume any bits.
+	enum := BitsEnum(5, map[uint64]interface{}{0: nil})
+	var pe Error
+	if err := enum(state, &Result{}); !errors.As(err, &pe) || pe.Position() != (Position{0, 0}) || pe.Bit() != 3 {
+		t.Errorf("BitsEnum(5) = %v, want error at bit 3", err)
+	}
+	if pe.Error() != "unexpected value 5 at line 1, byte 1, bit 4" {
+		t.Errorf("error = %q", pe.Error())
+	}
+	if err := Bits(14)(state, &Result{}); !errors.As(err, &pe) || pe.Position() != (Position{0, 0}) || pe.Bit() != 3 ||
+		state.Position() != (Position{0, 0}) || state.Bit() != 3 {
+		t.Errorf("Bits(14) = %v at %v, bit %d, want error at bit 3", err, state.Position(), state.Bit())
+	}
+	pe = Error{}
+	if err := Seq(Bits(12), Bits(2))(state, &Result{}); !errors.As(err, &pe) || pe.Position() != (Position{0, 1}) || pe.Bit() != 7 {
+		t.Errorf("Seq(Bits(12), Bits(2)) = %v, want error at bit 7 of byte 2", err)
+	}
+	pe = Error{}
+	if err := Seq(Bits(13), Flag)(state, &Result{}); !errors.As(err, &pe) || pe.Position() != (Position{0, 2}) {
+		t.Errorf("Seq(Bits(13), Flag) = %v, want error at byte 3", err)
+	}
+
+	// Align skips to the next byte so byte-level parsers can continue.
+	result, err := Seq(Bits(2), Align, 'x', Align).Parse(state)
+	if err != nil || result.Children[0].Value != uint64(0) {
+		t.Errorf("Seq(Bits(2), Align, 'x', Align) = %v, %v", result, err)
+	}
+	if pos, bit := state.Position(), state.Bit(); pos != (Position{0, 2}) || bit != 0 {
+		t.Errorf("state.Position(), state.Bit() = %v, %d", pos, bit)
+	}
+}
+
+func TestBitsCommit(t *testing.T) {
+	state := FromBytes([]byte{0xff})
+	state.Push()
+	Bits(3)(state, &Result{})
+	state.Commit()
+	Bits(2)(state, &Result{})
+
+	// Backtracking stops at the bit of the commit point.
+	state.Pop()
+	if pos, bit := state.Position(), state.Bit(); pos != (Position{0, 0}) || bit != 3 {
+		t.Errorf("state.Position(), state.Bit() = %v, %d, want %v, 3", pos, bit, Position{0, 0})
+	}
+}
+
+func TestBitsMany(t *testing.T) {
+	// Consuming a bit is progress even within the same byte.
+	state := FromBytes([]byte{0xff})
+	result, err := Many(Flag).Parse(state)
+	if err != nil || len(result.Children) != 8 {
+		t.Errorf("Many(Flag) = %d children, %v, want 8", len(result.Children), err)
+	}
+	result, err = Delim(Bits(2), Epsilon).Parse(FromBytes([]byte{0xff}))
+	if err != nil || len(result.Children) != 4 {
+		t.Errorf("Delim(Bits(2), Epsilon) = %d children, %v, want 4", len(result.Children), err)
+	}
+}
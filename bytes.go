@@ -14,21 +14,21 @@ import (
 func Byte(p ...byte) Parser {
 	switch len(p) {
 	case 0:
-		return func(state *State, result *Result) error {
+		return describe(&Node{Kind: KindByte}, func(state *State, result *Result) error {
 			if err := state.Request(1); err != nil {
 				return NewNestedError("Byte", err)
 			}
-			result.SetToken([]byte{state.Buffer()[0]})
+			result.SetToken(state.Buffer())
 			state.Advance()
 			return nil
-		}
+		})
 	case 1:
 		e := p[0]
 		rep := ascii.Rep(e)
 		name := fmt.Sprintf("Byte(%s)", rep)
 		what := fmt.Sprintf("expected `%s`", rep)
 
-		return func(state *State, result *Result) error {
+		return describe(&Node{Kind: KindByte, Literal: p}, func(state *State, result *Result) error {
 			c, err := Next(state)
 			if err != nil {
 				return NewNestedError(name, err)
@@ -36,10 +36,10 @@ func Byte(p ...byte) Parser {
 			if c != e {
 				return NewError(what, state.Position())
 			}
-			result.SetToken([]byte{c})
+			result.SetToken(state.Buffer())
 			state.Advance()
 			return nil
-		}
+		})
 	default:
 		reps := strings.Join(ascii.Reps(p), ", ")
 		name := fmt.Sprintf("Byte(%s)", reps)
@@ -48,7 +48,7 @@ func Byte(p ...byte) Parser {
 		s := string(p)
 		mismatch := func(c byte) bool { return strings.IndexByte(s, c) < 0 }
 
-		return func(state *State, result *Result) error {
+		return describe(&Node{Kind: KindByte, Literal: p}, func(state *State, result *Result) error {
 			c, err := Next(state)
 			if err != nil {
 				return NewNestedError(name, err)
@@ -56,10 +56,10 @@ func Byte(p ...byte) Parser {
 			if mismatch(c) {
 				return NewError(what, state.Position())
 			}
-			result.SetToken([]byte{c})
+			result.SetToken(state.Buffer())
 			state.Advance()
 			return nil
-		}
+		})
 	}
 }
 
@@ -71,7 +71,7 @@ func ByteRange(begin, end byte) Parser {
 		name := fmt.Sprintf("ByteRange(%s, %s)", rbegin, rend)
 		what := fmt.Sprintf("expected in range %s-%s", rbegin, rend)
 
-		return func(state *State, result *Result) error {
+		return describe(&Node{Kind: KindByteRange, Literal: []byte{begin, end}}, func(state *State, result *Result) error {
 			c, err := Next(state)
 			if err != nil {
 				return NewNestedError(name, err)
@@ -79,10 +79,10 @@ func ByteRange(begin, end byte) Parser {
 			if c < begin || end < c {
 				return NewError(what, state.Position())
 			}
-			result.SetToken([]byte{c})
+			result.SetToken(state.Buffer())
 			state.Advance()
 			return nil
-		}
+		})
 	}
 	panic("invalid byte range")
 }
@@ -93,7 +93,7 @@ func Bytes(p []byte) Parser {
 	name := fmt.Sprintf("Bytes([%s])", reps)
 	what := fmt.Sprintf("expected [%s]", reps)
 
-	return func(state *State, result *Result) error {
+	return describe(&Node{Kind: KindBytes, Literal: p}, func(state *State, result *Result) error {
 		if err := state.Request(len(p)); err != nil {
 			return NewNestedError(name, err)
 		}
@@ -103,5 +103,5 @@ func Bytes(p []byte) Parser {
 		result.SetToken(p)
 		state.Advance()
 		return nil
-	}
+	})
 }
@@ -0,0 +1,81 @@
+// Command pars-gen generates a Go source file implementing a grammar written
+// in the grammar text notation of the peg package. The generated file uses
+// specialized functions over pars.State and pars.Result instead of closures
+// and declares a Rules function which yields the same Parsers as compiling the
+// grammar with peg.Compile.
+//
+// Usage:
+//
+//   pars-gen [-p package] [-o output] grammar.peg
+//
+// The package name defaults to the name of the output directory and the output
+// defaults to the standard output.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/go-pars/pars/peg"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: pars-gen [-p package] [-o output] grammar.peg\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func packageName(output string) string {
+	dir, err := filepath.Abs(filepath.Dir(output))
+	if err != nil {
+		return "main"
+	}
+	return filepath.Base(dir)
+}
+
+func run(input, output, pkg string) error {
+	text, err := ioutil.ReadFile(input)
+	if err != nil {
+		return err
+	}
+
+	g, err := peg.Parse(string(text))
+	if err != nil {
+		return fmt.Errorf("%s: %v", input, err)
+	}
+
+	if pkg == "" {
+		pkg = packageName(output)
+	}
+
+	buf := bytes.Buffer{}
+	if err := g.Generate(&buf, pkg); err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return ioutil.WriteFile(output, buf.Bytes(), 0644)
+}
+
+func main() {
+	pkg := flag.String("p", "", "package name of the generated file")
+	output := flag.String("o", "", "output file name")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		usage()
+	}
+
+	if err := run(flag.Arg(0), *output, *pkg); err != nil {
+		fmt.Fprintf(os.Stderr, "pars-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -8,12 +8,48 @@ import (
 // restore the pre-matching state even if the given Parser matches.
 func Dry(q interface{}) Parser {
 	p := AsParser(q)
-	return func(state *State, result *Result) error {
+	n := &Node{Kind: KindDry, children: []Parser{p}}
+	p = direct(p)
+	return describe(n, func(state *State, result *Result) error {
 		state.Push()
 		err := p(state, result)
 		state.Pop()
 		return err
-	}
+	})
+}
+
+// Not creates a Parser which will match only if the given Parser does not
+// match. The state is always restored to the pre-matching state.
+func Not(q interface{}) Parser {
+	p := AsParser(q)
+	n := &Node{Kind: KindNot, children: []Parser{p}}
+	p = direct(p)
+	return describe(n, func(state *State, result *Result) error {
+		state.Push()
+		err := p(state, &Result{})
+		state.Pop()
+		if err == nil {
+			return NewError("unexpected match", state.Position())
+		}
+		return nil
+	})
+}
+
+// Capture creates a Parser which will attempt to match the given Parser and
+// name the result on a match. The names of the children of a result can be
+// used to look up a child with Result.Get or the Field mapping, which is
+// robust to changes in the number or order of the children unlike indices.
+func Capture(name string, q interface{}) Parser {
+	p := AsParser(q)
+	n := &Node{Kind: KindCapture, Name: name, children: []Parser{p}}
+	p = direct(p)
+	return describe(n, func(state *State, result *Result) error {
+		if err := p(state, result); err != nil {
+			return err
+		}
+		result.Name = name
+		return nil
+	})
 }
 
 // Seq creates a Parser which will attempt to match all of the given Parsers
@@ -23,20 +59,41 @@ func Dry(q interface{}) Parser {
 func Seq(qs ...interface{}) Parser {
 	name := fmt.Sprintf("Seq(%d)", len(qs))
 	ps := AsParsers(qs...)
+	pool := newResultPool(len(ps))
 
-	return func(state *State, result *Result) error {
-		v := make([]Result, len(ps))
+	n := &Node{Kind: KindSeq, children: ps}
+	ps = directs(ps)
+	return describe(n, func(state *State, result *Result) error {
+		if a := state.arena; a != nil {
+			m := a.mark()
+			v := a.alloc(len(ps))
+			state.Push()
+			for i, p := range ps {
+				if err := p(state, &v[i]); err != nil {
+					state.Pop()
+					a.rollback(m)
+					return NewNestedError(name, err)
+				}
+			}
+			state.Drop()
+			result.SetChildren(v)
+			return nil
+		}
+
+		h := pool.get()
+		v := *h
 		state.Push()
 		for i, p := range ps {
 			if err := p(state, &v[i]); err != nil {
 				state.Pop()
+				pool.release(h)
 				return NewNestedError(name, err)
 			}
 		}
 		state.Drop()
-		result.SetChildren(v)
+		result.SetChildren(pool.keep(h))
 		return nil
-	}
+	})
 }
 
 // Any creates a Parser which will attempt to match any of the given Parsers.
@@ -48,7 +105,9 @@ func Any(qs ...interface{}) Parser {
 	name := fmt.Sprintf("Any(%d)", len(qs))
 	ps := AsParsers(qs...)
 
-	return func(state *State, result *Result) (err error) {
+	n := &Node{Kind: KindAny, children: ps}
+	ps = directs(ps)
+	return describe(n, func(state *State, result *Result) (err error) {
 		state.Push()
 		for _, p := range ps {
 			if err = p(state, result); err == nil {
@@ -61,7 +120,7 @@ func Any(qs ...interface{}) Parser {
 		}
 		state.Pop()
 		return NewNestedError(name, err)
-	}
+	})
 }
 
 // Maybe creates a Parser which will attempt to match the given Parser but
@@ -70,7 +129,9 @@ func Any(qs ...interface{}) Parser {
 func Maybe(q interface{}) Parser {
 	p := AsParser(q)
 
-	return func(state *State, result *Result) error {
+	n := &Node{Kind: KindMaybe, children: []Parser{p}}
+	p = direct(p)
+	return describe(n, func(state *State, result *Result) error {
 		state.Push()
 		if err := p(state, result); err != nil {
 			if !state.Pushed() {
@@ -81,25 +142,54 @@ func Maybe(q interface{}) Parser {
 		}
 		state.Drop()
 		return nil
-	}
+	})
 }
 
 // Many creates a Parser which will attempt to match the given Parser as many
-// times as possible.
+// times as possible. Matching stops once the given Parser fails or matches
+// without consuming any input, so a Parser which may match the empty string
+// cannot loop forever. The result always has Children, one for each match
+// which consumed input, which is empty if the first match did not consume any
+// input.
 func Many(q interface{}) Parser {
 	p := AsParser(q)
 
-	return func(state *State, result *Result) error {
-		v := []Result{}
-		start := state.Position()
+	n := &Node{Kind: KindMany, children: []Parser{p}}
+	p = direct(p)
+	return describe(n, func(state *State, result *Result) error {
+		c := newCollector(state.arena)
+		start := state.consumed()
 		for p(state, result) == nil {
-			if start == state.Position() {
-				return nil
+			// Stop if the Parser matched without consuming any input.
+			if start == state.consumed() {
+				break
 			}
-			v = append(v, *result)
+			c.add(*result)
 			*result = Result{}
+			start = state.consumed()
 		}
-		result.SetChildren(v)
+		result.SetChildren(c.results())
 		return nil
-	}
+	})
+}
+
+// Nested creates a Parser which will attempt to match the given Parser unless
+// the state is already within n Nested Parsers, in which case it will fail.
+// Wrapping the recursive part of a grammar with Nested bounds the depth of
+// the recursion, which would otherwise be limited only by the stack.
+func Nested(q interface{}, n int) Parser {
+	p := AsParser(q)
+	what := fmt.Sprintf("exceeded maximum nesting depth of %d", n)
+
+	node := &Node{Kind: KindWrap, Name: fmt.Sprintf("Nested(%d)", n), children: []Parser{p}}
+	p = direct(p)
+	return describe(node, func(state *State, result *Result) error {
+		if state.depth >= n {
+			return NewError(what, state.Position())
+		}
+		state.depth++
+		err := p(state, result)
+		state.depth--
+		return err
+	})
 }
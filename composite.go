@@ -24,12 +24,47 @@ func Count(q interface{}, n int) Parser {
 // times like Many, but with the second Parser in between.
 func Delim(q, s interface{}) Parser {
 	p, d := AsParser(q), AsParser(s)
-	return Seq(p, Many(Seq(d, p).Map(Child(1)))).Map(func(result *Result) error {
-		head, tail := result.Children[0], result.Children[1].Children
-		children := make([]Result, len(tail)+1)
-		children[0] = head
-		copy(children[1:], tail)
-		result.SetChildren(children)
+	n := &Node{Kind: KindDelim, children: []Parser{p, d}}
+	p, d = direct(p), direct(d)
+	return describe(n, func(state *State, result *Result) error {
+		a := state.arena
+		c := newCollector(a)
+		state.Push()
+		if err := p(state, result); err != nil {
+			state.Pop()
+			return NewNestedError("Seq(2)", err)
+		}
+		c.add(*result)
+		start := state.consumed()
+		for {
+			var m arenaMark
+			if a != nil {
+				m = a.mark()
+			}
+			*result = Result{}
+			state.Push()
+			err := d(state, result)
+			if err == nil {
+				*result = Result{}
+				err = p(state, result)
+			}
+			if err != nil {
+				state.Pop()
+				if a != nil {
+					a.rollback(m)
+				}
+				break
+			}
+			state.Drop()
+			// Stop if the Parsers matched without consuming any input.
+			if start == state.consumed() {
+				break
+			}
+			c.add(*result)
+			start = state.consumed()
+		}
+		state.Drop()
+		result.SetChildren(c.results())
 		return nil
 	})
 }
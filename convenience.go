@@ -14,7 +14,7 @@ import (
 func untilByte(e byte) Parser {
 	name := fmt.Sprintf("Until(%s)", ascii.Rep(e))
 
-	return func(state *State, result *Result) error {
+	return describe(&Node{Kind: KindUntil, Literal: []byte{e}}, func(state *State, result *Result) error {
 		state.Push()
 
 		c, err := Next(state)
@@ -35,7 +35,7 @@ func untilByte(e byte) Parser {
 		p, _ := Trail(state)
 		result.SetToken(p)
 		return nil
-	}
+	})
 }
 
 func untilBytes(e []byte) Parser {
@@ -47,7 +47,7 @@ func untilBytes(e []byte) Parser {
 	default:
 		name := fmt.Sprintf("Until(%s)", strings.Join(ascii.Reps(e), ", "))
 
-		return func(state *State, result *Result) error {
+		return describe(&Node{Kind: KindUntil, Literal: e}, func(state *State, result *Result) error {
 			state.Push()
 
 			for {
@@ -64,7 +64,7 @@ func untilBytes(e []byte) Parser {
 
 				Skip(state, 1)
 			}
-		}
+		})
 	}
 }
 
@@ -73,7 +73,7 @@ func untilFilter(filter ascii.Filter) Parser {
 	f := runtime.FuncForPC(v.Pointer())
 	name := fmt.Sprintf("Until(%s)", f.Name())
 
-	return func(state *State, result *Result) error {
+	return describe(&Node{Kind: KindUntil, Name: f.Name()}, func(state *State, result *Result) error {
 		state.Push()
 
 		c, err := Next(state)
@@ -94,7 +94,7 @@ func untilFilter(filter ascii.Filter) Parser {
 		p, _ := Trail(state)
 		result.SetToken(p)
 		return nil
-	}
+	})
 }
 
 // Until creates a Parser which will advance the state until the given Parser
@@ -119,7 +119,9 @@ func Until(q interface{}) Parser {
 	default:
 		p := AsParser(q)
 
-		return func(state *State, result *Result) error {
+		n := &Node{Kind: KindUntil, children: []Parser{p}}
+		p = direct(p)
+		return describe(n, func(state *State, result *Result) error {
 			// Backtrack point for later.
 			state.Push()
 
@@ -141,7 +143,7 @@ func Until(q interface{}) Parser {
 			}
 			result.SetToken(p)
 			return nil
-		}
+		})
 	}
 }
 
@@ -150,28 +152,34 @@ func Until(q interface{}) Parser {
 //   a line feed (LF, '\n')
 //   a carriage return + line feed (CRLF, "\r\n")
 //   end of state
+// For a push state which is still open, EOL returns ErrNeedMore at the end of
+// the fed bytes or after a carriage return ending them, as the line may yet
+// continue or the carriage return be followed by a line feed.
 func EOL(state *State, result *Result) error {
 	c, err := Next(state)
+	if err == ErrNeedMore {
+		return err
+	}
 	if err != nil {
 		result.SetToken(nil)
 		return nil
 	}
 
 	if c == '\n' {
-		result.SetToken([]byte{'\n'})
+		result.SetToken(state.Buffer())
 		state.Advance()
 		return nil
 	}
 
 	if c == '\r' {
-		state.Advance()
-		c, err = Next(state)
-		if err == nil && c == '\n' {
-			state.Advance()
-			result.SetToken([]byte{'\r', '\n'})
-			return nil
+		switch err := state.Request(2); {
+		case err == ErrNeedMore:
+			return err
+		case err != nil || state.Buffer()[1] != '\n':
+			state.Request(1)
 		}
-		result.SetToken([]byte{'\r'})
+		result.SetToken(state.Buffer())
+		state.Advance()
 		return nil
 	}
 
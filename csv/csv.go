@@ -0,0 +1,301 @@
+// Package csv implements parsers for comma-separated values and the many
+// dialects which differ in the separator, quoting, escaping, and comments.
+// The RFC4180 dialect follows RFC 4180, where a quote inside a quoted field
+// is written as two quotes. Records are read one at a time by a Reader so
+// arbitrarily large inputs can be processed with bounded memory, and errors
+// report the exact position of a malformed field.
+package csv
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-pars/pars"
+)
+
+// Dialect describes the syntax of a CSV file.
+type Dialect struct {
+	// Comma is the field separator.
+	Comma byte
+
+	// Quote is the byte enclosing quoted fields, which may contain separators
+	// and line breaks. Quoting is disabled if Quote is zero.
+	Quote byte
+
+	// Escape is the byte which causes the following byte to be taken
+	// literally, both in quoted and unquoted fields. If Escape is zero, a
+	// quote inside a quoted field is written as two quotes.
+	Escape byte
+
+	// Comment is the byte which starts a comment line if it is the first byte
+	// of a line. Comments are disabled if Comment is zero.
+	Comment byte
+
+	// TrimLeadingSpace causes the spaces and tabs at the beginning of each
+	// field to be ignored.
+	TrimLeadingSpace bool
+}
+
+// Common dialects.
+var (
+	// RFC4180 is the dialect of RFC 4180 and encoding/csv.
+	RFC4180 = Dialect{Comma: ',', Quote: '"'}
+
+	// TSV is the dialect of tab-separated values without quoting.
+	TSV = Dialect{Comma: '\t'}
+)
+
+// isEnd tests if the byte ends a field.
+func (d Dialect) isEnd(c byte) bool {
+	return c == d.Comma || c == '\r' || c == '\n'
+}
+
+// skipSpace skips the leading spaces of a field if the dialect trims them.
+func (d Dialect) skipSpace(state *pars.State) {
+	if !d.TrimLeadingSpace {
+		return
+	}
+	for {
+		c, err := pars.Next(state)
+		if err != nil || c == d.Comma || (c != ' ' && c != '\t') {
+			return
+		}
+		state.Advance()
+	}
+}
+
+// unescape removes the escape bytes from a field.
+func (d Dialect) unescape(p []byte) string {
+	q := make([]byte, 0, len(p))
+	for i := 0; i < len(p); i++ {
+		if p[i] == d.Escape && i+1 < len(p) {
+			i++
+		}
+		q = append(q, p[i])
+	}
+	return string(q)
+}
+
+// unquoted matches the bytes of a field up to the next separator or line
+// break. A quote is not allowed in an unquoted field.
+func (d Dialect) unquoted(state *pars.State, result *pars.Result) error {
+	state.Push()
+	escaped := false
+	for {
+		c, err := pars.Next(state)
+		switch {
+		case err != nil || d.isEnd(c):
+			p, _ := pars.Trail(state)
+			if escaped {
+				result.SetValue(d.unescape(p))
+			} else {
+				result.SetValue(string(p))
+			}
+			return nil
+		case d.Quote != 0 && c == d.Quote:
+			pos := state.Position()
+			state.Pop()
+			return pars.NewError(fmt.Sprintf("unexpected %q in unquoted field", rune(c)), pos)
+		case d.Escape != 0 && c == d.Escape:
+			state.Advance()
+			if _, err := pars.Next(state); err != nil {
+				pos := state.Position()
+				state.Pop()
+				return pars.NewError("unexpected end of input after escape", pos)
+			}
+			escaped = true
+		}
+		state.Advance()
+	}
+}
+
+// doubled matches a quoted field in which a quote is written as two quotes.
+func (d Dialect) doubled(state *pars.State, result *pars.Result) error {
+	start := state.Position()
+	state.Push()
+	state.Advance()
+	p := []byte{}
+	for {
+		c, err := pars.Next(state)
+		if err != nil {
+			pos := state.Position()
+			state.Pop()
+			return pars.NewError(fmt.Sprintf("unterminated quoted field starting at %s", start), pos)
+		}
+		state.Advance()
+		switch {
+		case c == d.Escape && d.Escape != 0:
+			if c, err = pars.Next(state); err != nil {
+				continue
+			}
+			state.Advance()
+		case c == d.Quote:
+			if c, err := pars.Next(state); err != nil || c != d.Quote {
+				state.Drop()
+				result.SetValue(string(p))
+				return nil
+			}
+			state.Advance()
+		}
+		p = append(p, c)
+	}
+}
+
+// quoted creates a Parser for a quoted field followed by a separator, a line
+// break, or the end of the input.
+func (d Dialect) quoted() pars.Parser {
+	var body pars.Parser = d.doubled
+	if d.Escape == '\\' {
+		// Quoted handles backslash escapes itself.
+		body = pars.Quoted(d.Quote).Map(func(result *pars.Result) error {
+			result.SetValue(d.unescape(result.Token))
+			return nil
+		})
+	}
+	what := fmt.Sprintf("expected %q or end of line after quoted field", rune(d.Comma))
+
+	return func(state *pars.State, result *pars.Result) error {
+		state.Push()
+		if err := body(state, result); err != nil {
+			state.Pop()
+			return err
+		}
+		if c, err := pars.Next(state); err == nil && !d.isEnd(c) {
+			pos := state.Position()
+			state.Pop()
+			return pars.NewError(what, pos)
+		}
+		state.Drop()
+		return nil
+	}
+}
+
+// Field creates a Parser which will match a single field of the dialect. The
+// value of the Result is the string content of the field with the quotes and
+// escapes removed.
+func (d Dialect) Field() pars.Parser {
+	quoted := d.quoted()
+
+	return func(state *pars.State, result *pars.Result) error {
+		d.skipSpace(state)
+		if c, err := pars.Next(state); err == nil && d.Quote != 0 && c == d.Quote {
+			return quoted(state, result)
+		}
+		return d.unquoted(state, result)
+	}
+}
+
+// Record creates a Parser which will match a single record of the dialect
+// including the line break at its end. The value of the Result is a []string
+// of the fields of the record. Comment lines and empty lines are matched with
+// a nil value.
+func (d Dialect) Record() pars.Parser {
+	field := d.Field()
+	fields := pars.Delim(field, d.Comma)
+
+	return func(state *pars.State, result *pars.Result) error {
+		c, err := pars.Next(state)
+		switch {
+		case err == nil && d.Comment != 0 && c == d.Comment:
+			pars.Line(state, result)
+			result.SetValue(nil)
+			return nil
+		case err == nil && (c == '\r' || c == '\n'):
+			pars.EOL(state, result)
+			result.SetValue(nil)
+			return nil
+		}
+
+		state.Push()
+		if err := fields(state, result); err != nil {
+			state.Pop()
+			return err
+		}
+		if err := pars.EOL(state, &pars.Result{}); err != nil {
+			// Delim stops before a separator if the following field fails so
+			// report the error of the field instead.
+			if c, _ := pars.Next(state); c == d.Comma {
+				state.Advance()
+				if ferr := field(state, &pars.Result{}); ferr != nil {
+					err = ferr
+				}
+			}
+			state.Pop()
+			return err
+		}
+		state.Drop()
+
+		v := make([]string, len(result.Children))
+		for i, child := range result.Children {
+			v[i] = child.Value.(string)
+		}
+		result.SetValue(v)
+		return nil
+	}
+}
+
+// Reader reads records from an io.Reader one at a time.
+type Reader struct {
+	// FieldsPerRecord is the number of fields required in each record. If
+	// FieldsPerRecord is zero, it is set to the number of fields of the first
+	// record. If FieldsPerRecord is negative, the number of fields may vary.
+	FieldsPerRecord int
+
+	scanner *pars.Scanner
+	record  pars.Parser
+}
+
+// NewReader creates a new Reader for the given io.Reader and Dialect.
+func NewReader(r io.Reader, d Dialect) *Reader {
+	rd := &Reader{record: d.Record()}
+	rd.scanner = pars.NewScanner(r, rd.parse)
+	return rd
+}
+
+// parse matches a record and checks the number of fields.
+func (r *Reader) parse(state *pars.State, result *pars.Result) error {
+	pos := state.Position()
+	if err := r.record(state, result); err != nil {
+		return err
+	}
+	v, ok := result.Value.([]string)
+	switch {
+	case !ok || r.FieldsPerRecord < 0:
+	case r.FieldsPerRecord == 0:
+		r.FieldsPerRecord = len(v)
+	case r.FieldsPerRecord != len(v):
+		return pars.NewError(fmt.Sprintf("wrong number of fields %d, want %d", len(v), r.FieldsPerRecord), pos)
+	}
+	return nil
+}
+
+// Read reads the next record. It returns io.EOF if there are no more
+// records. A malformed record results in a pars.RecordError wrapping a
+// pars.Error at the position of the offending byte.
+func (r *Reader) Read() ([]string, error) {
+	for r.scanner.Scan() {
+		if v, ok := r.scanner.Result().Value.([]string); ok {
+			return v, nil
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// ReadAll reads all of the remaining records.
+func (r *Reader) ReadAll() ([][]string, error) {
+	v := [][]string{}
+	for {
+		record, err := r.Read()
+		switch err {
+		case nil:
+			v = append(v, record)
+		case io.EOF:
+			return v, nil
+		default:
+			return v, err
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package csv
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-pars/pars"
+)
+
+func same(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+var readTestCases = []struct {
+	name    string
+	dialect Dialect
+	in      string
+	out     [][]string
+}{
+	{
+		"RFC4180", RFC4180,
+		"a,b,c\r\n1,\"x, \"\"y\"\"\",\n\"multi\nline\",,\"\"",
+		[][]string{{"a", "b", "c"}, {"1", `x, "y"`, ""}, {"multi\nline", "", ""}},
+	},
+	{
+		"TSV", TSV,
+		"a\tb\n\"1\"\t2\n\n",
+		[][]string{{"a", "b"}, {`"1"`, "2"}},
+	},
+	{
+		"semicolon", Dialect{Comma: ';', Quote: '"', Escape: '\\', Comment: '#'},
+		"# header\nname;note\n\"a\\\"b\";c\\;d\n#x;y\n",
+		[][]string{{"name", "note"}, {`a"b`, "c;d"}},
+	},
+	{
+		"pipe", Dialect{Comma: '|', Quote: '\'', Escape: '^', TrimLeadingSpace: true},
+		"a | 'b|c'|  'it^'s'\n",
+		[][]string{{"a ", "b|c", "it's"}},
+	},
+}
+
+func TestReader(t *testing.T) {
+	for _, tt := range readTestCases {
+		out, err := NewReader(strings.NewReader(tt.in), tt.dialect).ReadAll()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !same(out, tt.out) {
+			t.Errorf("%s: ReadAll() = %q, want %q", tt.name, out, tt.out)
+		}
+	}
+}
+
+func TestReaderStream(t *testing.T) {
+	r := NewReader(strings.NewReader("a,b\n1,2\n"), RFC4180)
+	for _, e := range [][]string{{"a", "b"}, {"1", "2"}} {
+		if v, err := r.Read(); err != nil || !same(v, e) {
+			t.Errorf("Read() = %q, %v, want %q", v, err, e)
+		}
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("Read() = %v, want %v", err, io.EOF)
+	}
+}
+
+var readErrorTestCases = []struct {
+	dialect Dialect
+	in      string
+	record  int
+	pos     pars.Position
+}{
+	{RFC4180, "a,b\n1,x\"y\n", 1, pars.Position{Line: 1, Byte: 3}},
+	{RFC4180, "a,\"b\"c\n", 0, pars.Position{Line: 0, Byte: 5}},
+	{RFC4180, "\"a\"x,b\n", 0, pars.Position{Line: 0, Byte: 3}},
+	{RFC4180, "a,b\n1,\"2\n3\n", 1, pars.Position{Line: 3, Byte: 0}},
+	{RFC4180, "a,b\n1,2,3\n", 1, pars.Position{Line: 1, Byte: 0}},
+	{Dialect{Comma: ';', Quote: '"', Escape: '\\'}, "a;\"b", 0, pars.Position{Line: 0, Byte: 2}},
+	{Dialect{Comma: ';', Escape: '\\'}, "a;b\\", 0, pars.Position{Line: 0, Byte: 4}},
+}
+
+func TestReaderError(t *testing.T) {
+	for _, tt := range readErrorTestCases {
+		_, err := NewReader(strings.NewReader(tt.in), tt.dialect).ReadAll()
+		var re pars.RecordError
+		var pe pars.Error
+		if !errors.As(err, &re) || re.Record() != tt.record || !errors.As(err, &pe) || pe.Position() != tt.pos {
+			t.Errorf("ReadAll(%q) = %v, want error in record %d at %v", tt.in, err, tt.record+1, tt.pos)
+		}
+	}
+
+	r := NewReader(strings.NewReader("a,b\n1,2,3\n"), RFC4180)
+	r.FieldsPerRecord = -1
+	if out, err := r.ReadAll(); err != nil || len(out) != 2 {
+		t.Errorf("ReadAll() = %q, %v", out, err)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	result, err := RFC4180.Record().Parse(pars.FromString("x,\"y\"\nz"))
+	if err != nil || !same(result.Value, []string{"x", "y"}) {
+		t.Errorf("Record() = %q, %v", result.Value, err)
+	}
+}
@@ -0,0 +1,399 @@
+package pars
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DecodeError is an error describing why a Result could not be decoded into
+// a Go value.
+type DecodeError struct {
+	path string
+	err  error
+}
+
+// NewDecodeError creates a new DecodeError.
+func NewDecodeError(path string, err error) error {
+	return DecodeError{path, err}
+}
+
+// Error satisfies the error interface.
+func (e DecodeError) Error() string {
+	if e.path == "" {
+		return fmt.Sprintf("cannot decode result: %s", e.err)
+	}
+	return fmt.Sprintf("cannot decode result into %s: %s", e.path, e.err)
+}
+
+// Path returns the path to the value being decoded when the error occurred.
+func (e DecodeError) Path() string { return e.path }
+
+// Unwrap returns the internal error value.
+func (e DecodeError) Unwrap() error { return e.err }
+
+// Decode stores the given Result in the value pointed to by v. A Result is
+// decoded according to the kind of the destination value:
+//
+//   pointer:   a new value is allocated if nil and the result is decoded
+//              into the pointed value.
+//   interface: the Value field if set, the Token field as a string if set,
+//              or the Children decoded into a []interface{}.
+//   string:    the Token field or a string Value.
+//   []byte:    a copy of the Token field.
+//   numbers:   a numeric Value converted to the destination type if it does
+//              not overflow, or the Token field parsed as a number.
+//   bool:      a bool Value or the Token field parsed with strconv.ParseBool.
+//   slice:     each of the Children decoded into an element.
+//   array:     each of the Children decoded into an element, requiring the
+//              number of children to equal the array length.
+//   map:       each of the named Children decoded into an element, with the
+//              name decoded into the key as a Token.
+//   struct:    the Children decoded into the struct fields according to their
+//              `pars` tags.
+//
+// Any Value which is directly assignable to the destination takes precedence
+// over the rules above, and an empty result sets a pointer, interface, map,
+// or slice to nil. A struct field tag is a child index or the name of a child
+// given to Capture, or a dotted path of them such as `pars:"1.name"` to decode
+// a nested child. A tag ending with `,optional` leaves the field untouched if
+// the child is missing instead of returning an error. Untagged fields and
+// fields tagged with `pars:"-"` are left untouched.
+func Decode(result *Result, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return NewDecodeError("", fmt.Errorf("expected a non-nil pointer, got %T", v))
+	}
+	return decode(result, rv.Elem(), rv.Elem().Type().String())
+}
+
+// Into creates a Map which will decode the result into a newly allocated value
+// of the type pointed to by v and set the pointer to the new value as the
+// result Value. The given value is only used for its type.
+func Into(v interface{}) Map {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Ptr {
+		panic(fmt.Errorf("Into requires a pointer, got %T", v))
+	}
+	t = t.Elem()
+
+	return func(result *Result) error {
+		rv := reflect.New(t)
+		if err := decode(result, rv.Elem(), t.String()); err != nil {
+			return err
+		}
+		result.SetValue(rv.Interface())
+		return nil
+	}
+}
+
+// Into will decode the result into a newly allocated value of the type pointed
+// to by v. See Decode for details on how the result is decoded.
+func (p Parser) Into(v interface{}) Parser { return p.Map(Into(v)) }
+
+func decodeMismatch(result *Result, v reflect.Value, path string) error {
+	var what string
+	switch {
+	case result.Value != nil:
+		what = fmt.Sprintf("value of type %T", result.Value)
+	case result.Token != nil:
+		what = "token"
+	case result.Children != nil:
+		what = fmt.Sprintf("%d children", len(result.Children))
+	default:
+		what = "empty result"
+	}
+	return NewDecodeError(path, fmt.Errorf("cannot use %s as %s", what, v.Type()))
+}
+
+func decode(result *Result, v reflect.Value, path string) error {
+	if result.Value != nil {
+		rv := reflect.ValueOf(result.Value)
+		if rv.Type().AssignableTo(v.Type()) {
+			v.Set(rv)
+			return nil
+		}
+	} else if result.Token == nil && result.Children == nil {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decode(result, v.Elem(), path)
+
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			v.Set(reflect.ValueOf(decodeInterface(result)))
+			return nil
+		}
+
+	case reflect.String:
+		if result.Token != nil {
+			v.SetString(string(result.Token))
+			return nil
+		}
+		if s, ok := result.Value.(string); ok {
+			v.SetString(s)
+			return nil
+		}
+
+	case reflect.Bool:
+		if result.Token != nil {
+			b, err := strconv.ParseBool(string(result.Token))
+			if err != nil {
+				return NewDecodeError(path, err)
+			}
+			v.SetBool(b)
+			return nil
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr, reflect.Float32, reflect.Float64:
+		if result.Token != nil {
+			return decodeNumberToken(result.Token, v, path)
+		}
+		if result.Value != nil {
+			return decodeNumberValue(reflect.ValueOf(result.Value), v, path)
+		}
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 && result.Token != nil {
+			p := make([]byte, len(result.Token))
+			copy(p, result.Token)
+			v.SetBytes(p)
+			return nil
+		}
+		if result.Children != nil {
+			s := reflect.MakeSlice(v.Type(), len(result.Children), len(result.Children))
+			for i := range result.Children {
+				elem := fmt.Sprintf("%s[%d]", path, i)
+				if err := decode(&result.Children[i], s.Index(i), elem); err != nil {
+					return err
+				}
+			}
+			v.Set(s)
+			return nil
+		}
+
+	case reflect.Array:
+		if result.Children != nil {
+			if len(result.Children) != v.Len() {
+				err := fmt.Errorf("expected %d children, got %d", v.Len(), len(result.Children))
+				return NewDecodeError(path, err)
+			}
+			for i := range result.Children {
+				elem := fmt.Sprintf("%s[%d]", path, i)
+				if err := decode(&result.Children[i], v.Index(i), elem); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+	case reflect.Map:
+		if result.Children != nil {
+			return decodeMap(result, v, path)
+		}
+
+	case reflect.Struct:
+		if result.Children != nil {
+			return decodeStruct(result, v, path)
+		}
+	}
+
+	return decodeMismatch(result, v, path)
+}
+
+func decodeInterface(result *Result) interface{} {
+	switch {
+	case result.Value != nil:
+		return result.Value
+	case result.Token != nil:
+		return string(result.Token)
+	case result.Children != nil:
+		s := make([]interface{}, len(result.Children))
+		for i := range result.Children {
+			s[i] = decodeInterface(&result.Children[i])
+		}
+		return s
+	default:
+		return nil
+	}
+}
+
+func decodeNumberToken(p []byte, v reflect.Value, path string) error {
+	s := string(p)
+	var err error
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(s, 0, v.Type().Bits()); err == nil {
+			v.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		if n, err = strconv.ParseUint(s, 0, v.Type().Bits()); err == nil {
+			v.SetUint(n)
+		}
+	default:
+		var f float64
+		if f, err = strconv.ParseFloat(s, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	}
+	if err != nil {
+		return NewDecodeError(path, err)
+	}
+	return nil
+}
+
+func decodeNumberValue(rv, v reflect.Value, path string) error {
+	overflow := func() error {
+		return NewDecodeError(path, fmt.Errorf("value %v overflows %s", rv, v.Type()))
+	}
+
+	var f float64
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := rv.Int()
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if v.OverflowInt(n) {
+				return overflow()
+			}
+			v.SetInt(n)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if n < 0 || v.OverflowUint(uint64(n)) {
+				return overflow()
+			}
+			v.SetUint(uint64(n))
+			return nil
+		}
+		f = float64(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := rv.Uint()
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if n > math.MaxInt64 || v.OverflowInt(int64(n)) {
+				return overflow()
+			}
+			v.SetInt(int64(n))
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if v.OverflowUint(n) {
+				return overflow()
+			}
+			v.SetUint(n)
+			return nil
+		}
+		f = float64(n)
+	case reflect.Float32, reflect.Float64:
+		f = rv.Float()
+	default:
+		return NewDecodeError(path, fmt.Errorf("cannot use value of type %s as %s", rv.Type(), v.Type()))
+	}
+
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if v.OverflowFloat(f) {
+			return overflow()
+		}
+		v.SetFloat(f)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 || v.OverflowInt(int64(f)) {
+			return overflow()
+		}
+		v.SetInt(int64(f))
+	default:
+		if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 || v.OverflowUint(uint64(f)) {
+			return overflow()
+		}
+		v.SetUint(uint64(f))
+	}
+	return nil
+}
+
+// lookupChild will find the child result specified by the tag path.
+func lookupChild(result *Result, tag string) (*Result, error) {
+	for _, key := range strings.Split(tag, ".") {
+		if result.Children == nil {
+			return nil, fmt.Errorf("no children for %q in tag %q", key, tag)
+		}
+		i, err := strconv.Atoi(key)
+		if err != nil {
+			child := result.Get(key)
+			if child == nil {
+				return nil, fmt.Errorf("no child named %q in tag %q", key, tag)
+			}
+			result = child
+			continue
+		}
+		if i < 0 || len(result.Children) <= i {
+			return nil, fmt.Errorf(
+				"index %d in tag %q out of range for %d children",
+				i, tag, len(result.Children),
+			)
+		}
+		result = &result.Children[i]
+	}
+	return result, nil
+}
+
+func decodeMap(result *Result, v reflect.Value, path string) error {
+	t := v.Type()
+	m := reflect.MakeMapWithSize(t, len(result.Children))
+	for i := range result.Children {
+		child := &result.Children[i]
+		if child.Name == "" {
+			continue
+		}
+		elem := fmt.Sprintf("%s[%q]", path, child.Name)
+		key := reflect.New(t.Key()).Elem()
+		if err := decode(&Result{Token: []byte(child.Name)}, key, elem); err != nil {
+			return err
+		}
+		value := reflect.New(t.Elem()).Elem()
+		if err := decode(child, value, elem); err != nil {
+			return err
+		}
+		m.SetMapIndex(key, value)
+	}
+	v.Set(m)
+	return nil
+}
+
+func decodeStruct(result *Result, v reflect.Value, path string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("pars")
+		if !ok || tag == "-" || field.PkgPath != "" {
+			continue
+		}
+		optional := strings.HasSuffix(tag, ",optional")
+		tag = strings.TrimSuffix(tag, ",optional")
+		name := fmt.Sprintf("%s.%s", path, field.Name)
+		child, err := lookupChild(result, tag)
+		if err != nil {
+			if optional {
+				continue
+			}
+			return NewDecodeError(name, err)
+		}
+		if err := decode(child, v.Field(i), name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
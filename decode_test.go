@@ -0,0 +1,186 @@
+package pars
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-ascii/ascii"
+)
+
+type testAssign struct {
+	Name  string   `pars:"0"`
+	Value int      `pars:"4"`
+	Tags  []string `pars:"6.1"`
+	Raw   []byte   `pars:"0"`
+	Any   interface{}
+}
+
+var (
+	testIdent      = Word(ascii.IsLetter)
+	testTags       = Seq('[', Delim(testIdent, ','), ']')
+	testAssignment = Seq(testIdent, Spaces, '=', Spaces, Int, Spaces, testTags)
+)
+
+func TestDecode(t *testing.T) {
+	in := "answer = 42 [foo,bar]"
+	result, err := testAssignment.Parse(FromString(in))
+	if err != nil {
+		t.Errorf("parser(%q): %v", in, err)
+		return
+	}
+
+	var v testAssign
+	if err := Decode(&result, &v); err != nil {
+		t.Errorf("Decode(): %v", err)
+		return
+	}
+	e := testAssign{"answer", 42, []string{"foo", "bar"}, []byte("answer"), nil}
+	if !same(v, e) {
+		t.Errorf("Decode() = %#v, want %#v", v, e)
+	}
+}
+
+func TestInto(t *testing.T) {
+	in := "answer = 42 [foo]"
+	result, err := testAssignment.Into(&testAssign{}).Parse(FromString(in))
+	if err != nil {
+		t.Errorf("parser(%q): %v", in, err)
+		return
+	}
+	e := &testAssign{"answer", 42, []string{"foo"}, []byte("answer"), nil}
+	if !same(result.Value, e) {
+		t.Errorf("result.Value = %#v, want %#v", result.Value, e)
+	}
+}
+
+type testNamed struct {
+	Name  string `pars:"name"`
+	Value int    `pars:"value"`
+	First string `pars:"tags.1.0"`
+}
+
+func TestDecodeNamed(t *testing.T) {
+	parser := Seq(
+		Capture("name", testIdent), Spaces, '=', Spaces,
+		Capture("value", Int), Spaces,
+		Capture("tags", testTags),
+	).Into(&testNamed{})
+
+	in := "answer = 42 [foo,bar]"
+	result, err := parser.Parse(FromString(in))
+	if err != nil {
+		t.Errorf("parser(%q): %v", in, err)
+		return
+	}
+	e := &testNamed{"answer", 42, "foo"}
+	if !same(result.Value, e) {
+		t.Errorf("result.Value = %#v, want %#v", result.Value, e)
+	}
+
+	var v testNamed
+	err = Decode(AsResults("answer"), &v)
+	if de, ok := err.(DecodeError); !ok || de.Path() != "pars.testNamed.Name" {
+		t.Errorf("Decode() = %v, want DecodeError", err)
+	}
+
+	// Optional fields are left untouched if the child is missing.
+	var o struct {
+		A int `pars:"a,optional"`
+		C int `pars:"c,optional"`
+	}
+	o.C = -1
+	if err := Decode(testMembers, &o); err != nil || o.A != 1 || o.C != -1 {
+		t.Errorf("Decode() = %+v, %v, want {A:1 C:-1}", o, err)
+	}
+}
+
+var decodeTests = []struct {
+	name string
+	in   *Result
+	out  interface{}
+}{
+	{"string", AsResult([]byte("foo")), "foo"},
+	{"string value", AsResult("foo"), "foo"},
+	{"int token", AsResult([]byte("0x2a")), 42},
+	{"int8 value", AsResult(42), int8(42)},
+	{"uint value", AsResult(int64(42)), uint(42)},
+	{"float value", AsResult(42), 42.0},
+	{"int from float", AsResult(42.0), 42},
+	{"bool", AsResult(true), true},
+	{"bool token", AsResult([]byte("true")), true},
+	{"interface", AsResults([]byte("foo"), 42), []interface{}{"foo", 42}},
+	{"pointer", AsResult(42), func() *int { n := 42; return &n }()},
+	{"array", AsResults(1, 2), [2]int{1, 2}},
+	{"slice", AsResults(1, 2, 3), []float64{1, 2, 3}},
+	{"nil pointer", &Result{}, (*int)(nil)},
+	{"nil slice", &Result{}, []int(nil)},
+	{"map", testMembers, map[string]int{"a": 1, "b": 2}},
+	{"map int keys", &Result{Children: []Result{{Name: "0x10", Value: "x"}}}, map[int]string{16: "x"}},
+}
+
+var testMembers = &Result{Children: []Result{
+	{Name: "a", Value: 1},
+	{Value: 3},
+	{Name: "b", Value: 2},
+}}
+
+func reflectNew(v interface{}) interface{} {
+	return reflect.New(reflect.TypeOf(v)).Interface()
+}
+
+func reflectElem(v interface{}) interface{} {
+	return reflect.ValueOf(v).Elem().Interface()
+}
+
+func TestDecodeValues(t *testing.T) {
+	for _, tt := range decodeTests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := reflectNew(tt.out)
+			if err := Decode(tt.in, v); err != nil {
+				t.Errorf("Decode(): %v", err)
+				return
+			}
+			if out := reflectElem(v); !same(out, tt.out) {
+				t.Errorf("Decode() = %#v, want %#v", out, tt.out)
+			}
+		})
+	}
+}
+
+var decodeErrorTests = []struct {
+	name string
+	in   *Result
+	out  interface{}
+	path string
+}{
+	{"overflow", AsResult(300), new(int8), "int8"},
+	{"negative", AsResult(-1), new(uint), "uint"},
+	{"fraction", AsResult(1.5), new(int), "int"},
+	{"syntax", AsResult([]byte("foo")), new(int), "int"},
+	{"mismatch", AsResult(42), new(string), "string"},
+	{"array length", AsResults(1, 2, 3), new([2]int), "[2]int"},
+	{"element", AsResults(1, "foo"), new([]int), "[]int[1]"},
+	{"field", AsResults("foo"), new(testAssign), "pars.testAssign.Value"},
+	{"map key", testMembers, new(map[int]int), `map[int]int["a"]`},
+}
+
+func TestDecodeErrors(t *testing.T) {
+	if err := Decode(AsResult(42), 42); err == nil {
+		t.Errorf("Decode(42): expected error")
+	}
+
+	for _, tt := range decodeErrorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Decode(tt.in, tt.out)
+			var de DecodeError
+			if !errors.As(err, &de) {
+				t.Errorf("Decode() = %v, want DecodeError", err)
+				return
+			}
+			if de.Path() != tt.path {
+				t.Errorf("err.Path() = %q, want %q", de.Path(), tt.path)
+			}
+		})
+	}
+}
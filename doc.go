@@ -0,0 +1,17 @@
+// Package pars is a parser combinator library for parsing the contents of any
+// object with an io.Reader interface.
+//
+// A Parser is a function which attempts to match the input of a State and
+// stores the outcome in a Result. Parsers are combined into larger Parsers
+// with combinators such as Seq, Any, Many, and Delim, and the results are
+// converted into values with Map.
+//
+// Concurrency
+//
+// A Parser created by this package holds no mutable state of its own, so a
+// single Parser may be used by any number of goroutines at once. Each
+// goroutine must use its own State, Result, and Arena, which are not safe for
+// concurrent use. A Map or a user defined Parser which modifies shared data
+// must synchronize the access itself. ParseChunks uses this to parse
+// independent parts of an input in parallel.
+package pars
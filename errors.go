@@ -11,13 +11,26 @@ var errNoChildren = errors.New("result does not have children")
 type Error struct {
 	what string
 	pos  Position
+	bit  int
 }
 
 // NewError creates a new Error.
-func NewError(what string, pos Position) error { return Error{what, pos} }
+func NewError(what string, pos Position) error { return Error{what, pos, 0} }
 
 // Error satisfies the error interface.
-func (e Error) Error() string { return fmt.Sprintf("%s at %s", e.what, e.pos) }
+func (e Error) Error() string {
+	if e.bit != 0 {
+		return fmt.Sprintf("%s at %s, bit %d", e.what, e.pos, e.bit+1)
+	}
+	return fmt.Sprintf("%s at %s", e.what, e.pos)
+}
+
+// Position returns the position at which the error occurred.
+func (e Error) Position() Position { return e.pos }
+
+// Bit returns the number of bits of the byte at the position of the error
+// consumed by the bit-level parsers, which is zero at a byte boundary.
+func (e Error) Bit() int { return e.bit }
 
 // NestedError is a nested error type.
 type NestedError struct {
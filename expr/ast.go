@@ -0,0 +1,384 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/go-pars/pars"
+)
+
+// Func is a function which can be called from an expression.
+type Func func(args ...interface{}) (interface{}, error)
+
+// Env binds the variables and functions of an expression to their values. A
+// variable may be bound to a bool, a string, or any integer or float type,
+// which is converted to a float64. A function is bound to a Func.
+type Env map[string]interface{}
+
+// Node is a node of the syntax tree of an expression.
+type Node interface {
+	// Eval evaluates the node to a float64, a bool, or a string.
+	Eval(env Env) (interface{}, error)
+
+	// Position returns the position of the node in the expression.
+	Position() pars.Position
+
+	// String returns the node as a fully parenthesized expression.
+	String() string
+}
+
+// Literal is a number, a string, or a boolean constant.
+type Literal struct {
+	Value interface{}
+	At    pars.Position
+}
+
+// Eval returns the value of the literal.
+func (n *Literal) Eval(env Env) (interface{}, error) { return n.Value, nil }
+
+// Position returns the position of the literal.
+func (n *Literal) Position() pars.Position { return n.At }
+
+func (n *Literal) String() string {
+	switch v := n.Value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Variable is a name bound in the Env.
+type Variable struct {
+	Name string
+	At   pars.Position
+}
+
+// normalize converts the value of a variable to the type used by the
+// operators.
+func normalize(x interface{}) (interface{}, bool) {
+	switch x := x.(type) {
+	case float64, bool, string:
+		return x, true
+	}
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Bool:
+		return v.Bool(), true
+	case reflect.String:
+		return v.String(), true
+	default:
+		return nil, false
+	}
+}
+
+// Eval returns the value of the variable in the Env.
+func (n *Variable) Eval(env Env) (interface{}, error) {
+	x, ok := env[n.Name]
+	if !ok {
+		return nil, pars.NewError(fmt.Sprintf("undefined variable `%s`", n.Name), n.At)
+	}
+	v, ok := normalize(x)
+	if !ok {
+		return nil, pars.NewError(fmt.Sprintf("variable `%s` has unsupported type %T", n.Name, x), n.At)
+	}
+	return v, nil
+}
+
+// Position returns the position of the variable.
+func (n *Variable) Position() pars.Position { return n.At }
+
+func (n *Variable) String() string { return n.Name }
+
+// Unary is the application of one of the unary operators `-`, `+`, and `!`.
+type Unary struct {
+	Op string
+	X  Node
+	At pars.Position
+}
+
+// Eval applies the operator to the value of the operand.
+func (n *Unary) Eval(env Env) (interface{}, error) {
+	x, err := n.X.Eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch v := x.(type) {
+	case float64:
+		switch n.Op {
+		case "-":
+			return -v, nil
+		case "+":
+			return v, nil
+		}
+	case bool:
+		if n.Op == "!" {
+			return !v, nil
+		}
+	}
+	return nil, pars.NewError(fmt.Sprintf("operator %s is not defined for %s", n.Op, typeName(x)), n.At)
+}
+
+// Position returns the position of the operator.
+func (n *Unary) Position() pars.Position { return n.At }
+
+func (n *Unary) String() string { return fmt.Sprintf("(%s%s)", n.Op, n.X) }
+
+// Binary is the application of a binary operator. The operators from the
+// lowest to the highest precedence are:
+//
+//   ||
+//   &&
+//   ==  !=
+//   <   <=  >   >=
+//   +   -
+//   *   /   %
+//
+// All of the operators are left associative, and `&&` and `||` only
+// evaluate the right operand if needed.
+type Binary struct {
+	Op   string
+	X, Y Node
+	At   pars.Position
+}
+
+func typeName(x interface{}) string {
+	switch x.(type) {
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	case string:
+		return "string"
+	default:
+		return fmt.Sprintf("%T", x)
+	}
+}
+
+// boolean evaluates an operand of the `&&` and `||` operators.
+func (n *Binary) boolean(env Env, operand Node) (bool, error) {
+	x, err := operand.Eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := x.(bool)
+	if !ok {
+		return false, pars.NewError(fmt.Sprintf("operator %s is not defined for %s", n.Op, typeName(x)), n.At)
+	}
+	return b, nil
+}
+
+// logical evaluates the `&&` and `||` operators.
+func (n *Binary) logical(env Env) (interface{}, error) {
+	x, err := n.boolean(env, n.X)
+	if err != nil || x == (n.Op == "||") {
+		return x, err
+	}
+	return n.boolean(env, n.Y)
+}
+
+// Eval applies the operator to the values of the operands. The operands of
+// `==` and `!=` must be of the same type.
+func (n *Binary) Eval(env Env) (interface{}, error) {
+	if n.Op == "&&" || n.Op == "||" {
+		return n.logical(env)
+	}
+	x, err := n.X.Eval(env)
+	if err != nil {
+		return nil, err
+	}
+	y, err := n.Y.Eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.Op {
+	case "==":
+		if typeName(x) == typeName(y) {
+			return x == y, nil
+		}
+	case "!=":
+		if typeName(x) == typeName(y) {
+			return x != y, nil
+		}
+	}
+
+	switch a := x.(type) {
+	case float64:
+		b, ok := y.(float64)
+		if !ok {
+			break
+		}
+		switch n.Op {
+		case "+":
+			return a + b, nil
+		case "-":
+			return a - b, nil
+		case "*":
+			return a * b, nil
+		case "/", "%":
+			if b == 0 {
+				return nil, pars.NewError("division by zero", n.At)
+			}
+			if n.Op == "/" {
+				return a / b, nil
+			}
+			return math.Mod(a, b), nil
+		case "<":
+			return a < b, nil
+		case "<=":
+			return a <= b, nil
+		case ">":
+			return a > b, nil
+		case ">=":
+			return a >= b, nil
+		}
+	case string:
+		b, ok := y.(string)
+		if !ok {
+			break
+		}
+		switch n.Op {
+		case "+":
+			return a + b, nil
+		case "<":
+			return a < b, nil
+		case "<=":
+			return a <= b, nil
+		case ">":
+			return a > b, nil
+		case ">=":
+			return a >= b, nil
+		}
+	}
+
+	return nil, pars.NewError(fmt.Sprintf("operator %s is not defined for %s and %s", n.Op, typeName(x), typeName(y)), n.At)
+}
+
+// Position returns the position of the operator.
+func (n *Binary) Position() pars.Position { return n.At }
+
+func (n *Binary) String() string { return fmt.Sprintf("(%s %s %s)", n.X, n.Op, n.Y) }
+
+// Call is a call of a function bound in the Env or one of the Builtins.
+type Call struct {
+	Name string
+	Args []Node
+	At   pars.Position
+}
+
+// Eval calls the function with the values of the arguments.
+func (n *Call) Eval(env Env) (interface{}, error) {
+	var f Func
+	switch x := env[n.Name].(type) {
+	case Func:
+		f = x
+	case func(...interface{}) (interface{}, error):
+		f = x
+	case nil:
+		ok := false
+		if f, ok = Builtins[n.Name]; !ok {
+			return nil, pars.NewError(fmt.Sprintf("undefined function `%s`", n.Name), n.At)
+		}
+	default:
+		return nil, pars.NewError(fmt.Sprintf("`%s` is not a function", n.Name), n.At)
+	}
+	args := make([]interface{}, len(n.Args))
+	for i, arg := range n.Args {
+		v, err := arg.Eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	v, err := f(args...)
+	if err != nil {
+		return nil, pars.NewError(fmt.Sprintf("in call to `%s`: %v", n.Name, err), n.At)
+	}
+	r, ok := normalize(v)
+	if !ok {
+		return nil, pars.NewError(fmt.Sprintf("`%s` returned unsupported type %T", n.Name, v), n.At)
+	}
+	return r, nil
+}
+
+// Position returns the position of the function name.
+func (n *Call) Position() pars.Position { return n.At }
+
+func (n *Call) String() string {
+	s := n.Name + "("
+	for i, arg := range n.Args {
+		if i > 0 {
+			s += ", "
+		}
+		s += arg.String()
+	}
+	return s + ")"
+}
+
+// numbers converts the arguments of a function to float64 values.
+func numbers(min int, args []interface{}) ([]float64, error) {
+	if len(args) < min {
+		return nil, fmt.Errorf("expected at least %d arguments, got %d", min, len(args))
+	}
+	v := make([]float64, len(args))
+	for i, arg := range args {
+		f, ok := arg.(float64)
+		if !ok {
+			return nil, fmt.Errorf("argument %d is a %s, not a number", i+1, typeName(arg))
+		}
+		v[i] = f
+	}
+	return v, nil
+}
+
+// monadic creates a Func for a function of one number.
+func monadic(f func(float64) float64) Func {
+	return func(args ...interface{}) (interface{}, error) {
+		v, err := numbers(1, args)
+		if err != nil {
+			return nil, err
+		}
+		if len(v) != 1 {
+			return nil, fmt.Errorf("expected 1 argument, got %d", len(v))
+		}
+		return f(v[0]), nil
+	}
+}
+
+// fold creates a Func which combines one or more numbers.
+func fold(f func(float64, float64) float64) Func {
+	return func(args ...interface{}) (interface{}, error) {
+		v, err := numbers(1, args)
+		if err != nil {
+			return nil, err
+		}
+		r := v[0]
+		for _, x := range v[1:] {
+			r = f(r, x)
+		}
+		return r, nil
+	}
+}
+
+// Builtins are the functions available to every expression. A function of
+// the same name in the Env takes precedence.
+var Builtins = map[string]Func{
+	"abs":   monadic(math.Abs),
+	"ceil":  monadic(math.Ceil),
+	"floor": monadic(math.Floor),
+	"round": monadic(math.Round),
+	"sqrt":  monadic(math.Sqrt),
+	"min":   fold(math.Min),
+	"max":   fold(math.Max),
+}
@@ -0,0 +1,399 @@
+// Package expr implements infix expressions with variables, which extend the
+// prefix arithmetic of the polish example. An expression is compiled into a
+// tree of Nodes once and may then be evaluated against many environments, so
+// it is suitable for user-defined rules such as:
+//
+//   errors / max(requests, 1) > 0.05 && service == "api"
+//
+// An expression is defined to be as follows in EBNF:
+//
+//   expression = or
+//   or         = and, { `||`, and }
+//   and        = equality, { `&&`, equality }
+//   equality   = comparison, { ( `==` | `!=` ), comparison }
+//   comparison = additive, { ( `<=` | `>=` | `<` | `>` ), additive }
+//   additive   = term, { ( `+` | `-` ), term }
+//   term       = unary, { ( `*` | `/` | `%` ), unary }
+//   unary      = ( `-` | `+` | `!` ), unary | primary
+//   primary    = number | string | `true` | `false`
+//              | name, [ `(`, [ expression, { `,`, expression } ], `)` ]
+//              | `(`, expression, `)`
+//   name       = ( letter | `_` ), { letter | digit | `_` | `.` }
+//
+// Numbers are decimal floating point numbers and strings are double quoted
+// JSON strings. Spaces may appear between any two tokens. Parentheses,
+// function calls, and unary operators may be nested at most MaxDepth deep.
+package expr
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/go-pars/pars"
+	"github.com/go-pars/pars/json"
+)
+
+const unexpectedEOF = "unexpected end of input"
+
+// MaxDepth is the maximum nesting of parentheses, function calls, and unary
+// operators in an expression.
+const MaxDepth = 1000
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '_'
+}
+
+func isName(c byte) bool { return isLetter(c) || isDigit(c) || c == '.' }
+
+func skipSpace(state *pars.State) {
+	for {
+		c, err := pars.Next(state)
+		if err != nil || !isSpace(c) {
+			return
+		}
+		state.Advance()
+	}
+}
+
+// unexpected returns an error describing the byte at the current position.
+func unexpected(state *pars.State, what string) error {
+	c, err := pars.Next(state)
+	if err != nil {
+		return pars.NewError(unexpectedEOF, state.Position())
+	}
+	return pars.NewError(fmt.Sprintf("unexpected %q, expected %s", c, what), state.Position())
+}
+
+// operator matches one of the given operators followed by spaces. The
+// operators must be ordered so that no operator is preceded by its prefix.
+func operator(state *pars.State, ops []string) (string, pars.Position, bool) {
+	pos := state.Position()
+	for _, op := range ops {
+		if state.Request(len(op)) == nil && string(state.Buffer()) == op {
+			state.Advance()
+			skipSpace(state)
+			return op, pos, true
+		}
+	}
+	return "", pos, false
+}
+
+// levels are the binary operators from the lowest to the highest precedence.
+var levels = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<=", ">=", "<", ">"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+// binary matches the binary operators of the given precedence level.
+func binary(state *pars.State, level int) (Node, error) {
+	if level == len(levels) {
+		return unary(state)
+	}
+	x, err := binary(state, level+1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, pos, ok := operator(state, levels[level])
+		if !ok {
+			return x, nil
+		}
+		y, err := binary(state, level+1)
+		if err != nil {
+			return nil, err
+		}
+		x = &Binary{Op: op, X: x, Y: y, At: pos}
+	}
+}
+
+// node creates a Parser which will set the value to the Node matched by the
+// given function.
+func node(f func(*pars.State) (Node, error)) pars.Parser {
+	return func(state *pars.State, result *pars.Result) error {
+		node, err := f(state)
+		if err != nil {
+			return err
+		}
+		result.SetValue(node)
+		return nil
+	}
+}
+
+// The recursive parts of the grammar, which are bounded by MaxDepth.
+var (
+	group   pars.Parser // an expression in parentheses or a call argument
+	operand pars.Parser // the operand of a unary operator
+)
+
+func init() {
+	group = pars.Nested(node(func(state *pars.State) (Node, error) {
+		return binary(state, 0)
+	}), MaxDepth)
+	operand = pars.Nested(node(unary), MaxDepth)
+}
+
+// nested matches a Node with one of the recursive parts of the grammar.
+func nested(state *pars.State, p pars.Parser) (Node, error) {
+	result := pars.Result{}
+	if err := p(state, &result); err != nil {
+		return nil, err
+	}
+	return result.Value.(Node), nil
+}
+
+var unaryOps = []string{"-", "+", "!"}
+
+// unary matches a primary expression preceded by unary operators.
+func unary(state *pars.State) (Node, error) {
+	if c, err := pars.Next(state); err == nil && c == '!' {
+		// Do not mistake an `!=` for a negation.
+		if state.Request(2) == nil && state.Buffer()[1] == '=' {
+			return nil, unexpected(state, "an operand")
+		}
+	}
+	op, pos, ok := operator(state, unaryOps)
+	if !ok {
+		return primary(state)
+	}
+	x, err := nested(state, operand)
+	if err != nil {
+		return nil, err
+	}
+	return &Unary{Op: op, X: x, At: pos}, nil
+}
+
+// number matches a decimal floating point number.
+func number(state *pars.State) (Node, error) {
+	pos := state.Position()
+	digits := func() int {
+		n := 0
+		for {
+			c, err := pars.Next(state)
+			if err != nil || !isDigit(c) {
+				return n
+			}
+			state.Advance()
+			n++
+		}
+	}
+
+	state.Push()
+	digits()
+	if c, err := pars.Next(state); err == nil && c == '.' {
+		state.Advance()
+		if digits() == 0 {
+			err := unexpected(state, "a digit")
+			state.Pop()
+			return nil, err
+		}
+	}
+	if c, err := pars.Next(state); err == nil && (c == 'e' || c == 'E') {
+		state.Advance()
+		if c, err := pars.Next(state); err == nil && (c == '+' || c == '-') {
+			state.Advance()
+		}
+		if digits() == 0 {
+			err := unexpected(state, "a digit")
+			state.Pop()
+			return nil, err
+		}
+	}
+	p, _ := pars.Trail(state)
+	f, err := strconv.ParseFloat(string(p), 64)
+	if err != nil {
+		return nil, pars.NewError(fmt.Sprintf("invalid number %q", p), pos)
+	}
+	return &Literal{Value: f, At: pos}, nil
+}
+
+// name matches a variable, a function call, or a boolean constant.
+func name(state *pars.State) (Node, error) {
+	pos := state.Position()
+	state.Push()
+	for {
+		c, err := pars.Next(state)
+		if err != nil || !isName(c) {
+			break
+		}
+		state.Advance()
+	}
+	p, _ := pars.Trail(state)
+	s := string(p)
+	skipSpace(state)
+
+	if c, err := pars.Next(state); err != nil || c != '(' {
+		switch s {
+		case "true":
+			return &Literal{Value: true, At: pos}, nil
+		case "false":
+			return &Literal{Value: false, At: pos}, nil
+		}
+		return &Variable{Name: s, At: pos}, nil
+	}
+
+	state.Advance()
+	skipSpace(state)
+	call := &Call{Name: s, At: pos}
+	if c, err := pars.Next(state); err == nil && c == ')' {
+		state.Advance()
+		return call, nil
+	}
+	for {
+		arg, err := nested(state, group)
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+		c, err := pars.Next(state)
+		switch {
+		case err == nil && c == ',':
+			state.Advance()
+			skipSpace(state)
+		case err == nil && c == ')':
+			state.Advance()
+			return call, nil
+		default:
+			return nil, unexpected(state, "`,` or `)`")
+		}
+	}
+}
+
+// primary matches an operand of the operators and the spaces after it.
+func primary(state *pars.State) (node Node, err error) {
+	c, err := pars.Next(state)
+	if err != nil {
+		return nil, pars.NewError(unexpectedEOF, state.Position())
+	}
+	pos := state.Position()
+	switch {
+	case isDigit(c) || c == '.':
+		node, err = number(state)
+	case c == '"':
+		result := &pars.Result{}
+		if err = json.String(state, result); err == nil {
+			node = &Literal{Value: result.Value, At: pos}
+		}
+	case isLetter(c):
+		node, err = name(state)
+	case c == '(':
+		state.Advance()
+		skipSpace(state)
+		if node, err = nested(state, group); err == nil {
+			if c, e := pars.Next(state); e != nil || c != ')' {
+				err = unexpected(state, "`)`")
+			} else {
+				state.Advance()
+			}
+		}
+	default:
+		err = unexpected(state, "an operand")
+	}
+	if err != nil {
+		return nil, err
+	}
+	skipSpace(state)
+	return node, nil
+}
+
+// Expression matches an expression and the spaces around it, and sets the
+// value to the Node at the root of its syntax tree.
+func Expression(state *pars.State, result *pars.Result) error {
+	skipSpace(state)
+	node, err := binary(state, 0)
+	if err != nil {
+		return err
+	}
+	result.SetValue(node)
+	return nil
+}
+
+// Expr is a compiled expression.
+type Expr struct {
+	root Node
+}
+
+// Compile parses the given expression.
+func Compile(s string) (*Expr, error) {
+	state := pars.FromString(s)
+	result := &pars.Result{}
+	if err := Expression(state, result); err != nil {
+		return nil, err
+	}
+	if _, err := pars.Next(state); err == nil {
+		return nil, unexpected(state, "an operator")
+	}
+	return &Expr{result.Value.(Node)}, nil
+}
+
+// MustCompile is like Compile but panics if the expression cannot be parsed.
+func MustCompile(s string) *Expr {
+	e, err := Compile(s)
+	if err != nil {
+		panic(fmt.Errorf("expr: Compile(%q): %v", s, err))
+	}
+	return e
+}
+
+// Root returns the Node at the root of the syntax tree.
+func (e *Expr) Root() Node { return e.root }
+
+// Eval evaluates the expression with the given Env. The value is a float64,
+// a bool, or a string. Errors are pars.Error values giving the position of
+// the operator, variable, or function call which failed.
+func (e *Expr) Eval(env Env) (interface{}, error) { return e.root.Eval(env) }
+
+// EvalBool evaluates the expression with the given Env and requires the
+// value to be a bool.
+func (e *Expr) EvalBool(env Env) (bool, error) {
+	v, err := e.root.Eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, pars.NewError(fmt.Sprintf("expression is a %s, not a bool", typeName(v)), e.root.Position())
+	}
+	return b, nil
+}
+
+// Variables returns the sorted names of the variables in the expression.
+func (e *Expr) Variables() []string {
+	seen := make(map[string]bool)
+	var walk func(Node)
+	walk = func(node Node) {
+		switch n := node.(type) {
+		case *Variable:
+			seen[n.Name] = true
+		case *Unary:
+			walk(n.X)
+		case *Binary:
+			walk(n.X)
+			walk(n.Y)
+		case *Call:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		}
+	}
+	walk(e.root)
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String returns the expression fully parenthesized.
+func (e *Expr) String() string { return e.root.String() }
@@ -0,0 +1,229 @@
+package expr
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-pars/pars"
+)
+
+var testEnv = Env{
+	"requests":  200,
+	"errors":    uint8(15),
+	"latency":   float32(0.25),
+	"service":   "api",
+	"enabled":   true,
+	"http.code": 503,
+	"double": Func(func(args ...interface{}) (interface{}, error) {
+		v, err := numbers(1, args)
+		if err != nil {
+			return nil, err
+		}
+		return 2 * v[0], nil
+	}),
+	"fail": func(args ...interface{}) (interface{}, error) {
+		return nil, errors.New("failed")
+	},
+}
+
+var evalTests = []struct {
+	in  string
+	out interface{}
+}{
+	{"42", 42.0},
+	{"1.5e2", 150.0},
+	{".5", 0.5},
+	{"1 + 2 * 3", 7.0},
+	{"(1 + 2) * 3", 9.0},
+	{strings.Repeat("(", MaxDepth) + "1" + strings.Repeat(")", MaxDepth), 1.0},
+	{"10 - 4 - 3", 3.0},
+	{"2 * 3 % 4", 2.0},
+	{"-2 * -3", 6.0},
+	{"- - 2", 2.0},
+	{"+2", 2.0},
+	{"7 / 2", 3.5},
+	{`"a" + "b"`, "ab"},
+	{`"a\tb"`, "a\tb"},
+	{"true", true},
+	{"!false", true},
+	{"!!true", true},
+	{"1 < 2", true},
+	{"2 <= 1", false},
+	{"2 > 1 == true", true},
+	{"1 >= 1", true},
+	{`"abc" < "abd"`, true},
+	{"1 == 1", true},
+	{"1 != 1", false},
+	{"true || false && false", true},
+	{"(true || false) && false", false},
+	{"requests", 200.0},
+	{"errors / requests", 0.075},
+	{"latency", 0.25},
+	{`service == "api"`, true},
+	{"enabled && !false", true},
+	{"http.code >= 500", true},
+	{"errors / max(requests, 1) > 0.05 && service == \"api\"", true},
+	{"abs(-3) + floor(1.5) + ceil(1.5) + round(2.5)", 9.0},
+	{"min(3, 1, 2)", 1.0},
+	{"sqrt(16)", 4.0},
+	{"double(21)", 42.0},
+	{" ( 1 +\n 2 ) ", 3.0},
+	{"false && undefined", false},
+	{"true || undefined", true},
+}
+
+func TestEval(t *testing.T) {
+	for _, tt := range evalTests {
+		e, err := Compile(tt.in)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.in, err)
+			continue
+		}
+		out, err := e.Eval(testEnv)
+		if err != nil {
+			t.Errorf("Compile(%q).Eval(): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(out, tt.out) {
+			t.Errorf("Compile(%q).Eval() = %#v, want %#v", tt.in, out, tt.out)
+		}
+	}
+}
+
+var compileErrorTests = []struct {
+	in  string
+	pos pars.Position
+}{
+	{"", pars.Position{Byte: 0}},
+	{"1 +", pars.Position{Byte: 3}},
+	{"1 2", pars.Position{Byte: 2}},
+	{"(1 + 2", pars.Position{Byte: 6}},
+	{"1 = 2", pars.Position{Byte: 2}},
+	{"1 & 2", pars.Position{Byte: 2}},
+	{"1.", pars.Position{Byte: 2}},
+	{"1e+", pars.Position{Byte: 3}},
+	{"max(1 2)", pars.Position{Byte: 6}},
+	{"max(1,)", pars.Position{Byte: 6}},
+	{`"abc`, pars.Position{Byte: 4}},
+	{"1 == != 2", pars.Position{Byte: 5}},
+	{"@", pars.Position{Byte: 0}},
+	{strings.Repeat("(", 3000000), pars.Position{Byte: MaxDepth + 1}},
+	{strings.Repeat("-", 3000000) + "1", pars.Position{Byte: MaxDepth + 1}},
+	{strings.Repeat("abs(", 3000000), pars.Position{Byte: 4 * (MaxDepth + 1)}},
+}
+
+func TestCompileError(t *testing.T) {
+	for _, tt := range compileErrorTests {
+		_, err := Compile(tt.in)
+		if err == nil {
+			t.Errorf("Compile(%q) expected an error", tt.in)
+			continue
+		}
+		var perr pars.Error
+		if !errors.As(err, &perr) {
+			t.Errorf("Compile(%q): %v is not a pars.Error", tt.in, err)
+			continue
+		}
+		if perr.Position() != tt.pos {
+			t.Errorf("Compile(%q): error %q at %v, want %v", tt.in, err, perr.Position(), tt.pos)
+		}
+	}
+}
+
+var evalErrorTests = []struct {
+	in  string
+	pos pars.Position
+}{
+	{"missing + 1", pars.Position{Byte: 0}},
+	{"1 + missing()", pars.Position{Byte: 4}},
+	{"requests()", pars.Position{Byte: 0}},
+	{`1 + "a"`, pars.Position{Byte: 2}},
+	{`"a" == 1`, pars.Position{Byte: 4}},
+	{"1 / 0", pars.Position{Byte: 2}},
+	{"5 % (1 - 1)", pars.Position{Byte: 2}},
+	{"-true", pars.Position{Byte: 0}},
+	{"!1", pars.Position{Byte: 0}},
+	{"1 && true", pars.Position{Byte: 2}},
+	{"true && 1", pars.Position{Byte: 5}},
+	{`"a" - "b"`, pars.Position{Byte: 4}},
+	{"true < false", pars.Position{Byte: 5}},
+	{"abs(1, 2)", pars.Position{Byte: 0}},
+	{"abs(true)", pars.Position{Byte: 0}},
+	{"max()", pars.Position{Byte: 0}},
+	{"fail()", pars.Position{Byte: 0}},
+	{"1 +\n  missing", pars.Position{Line: 1, Byte: 2}},
+}
+
+func TestEvalError(t *testing.T) {
+	for _, tt := range evalErrorTests {
+		e, err := Compile(tt.in)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.in, err)
+			continue
+		}
+		_, err = e.Eval(testEnv)
+		if err == nil {
+			t.Errorf("Compile(%q).Eval() expected an error", tt.in)
+			continue
+		}
+		var perr pars.Error
+		if !errors.As(err, &perr) {
+			t.Errorf("Compile(%q).Eval(): %v is not a pars.Error", tt.in, err)
+			continue
+		}
+		if perr.Position() != tt.pos {
+			t.Errorf("Compile(%q).Eval(): error %q at %v, want %v", tt.in, err, perr.Position(), tt.pos)
+		}
+	}
+}
+
+func TestEvalBool(t *testing.T) {
+	e := MustCompile("errors > 10")
+	b, err := e.EvalBool(testEnv)
+	if err != nil || !b {
+		t.Errorf("EvalBool() = %v, %v, want true, nil", b, err)
+	}
+	if _, err := MustCompile("errors + 10").EvalBool(testEnv); err == nil {
+		t.Error("EvalBool() expected an error for a number")
+	}
+}
+
+func TestString(t *testing.T) {
+	e := MustCompile(`-a.b + 2 * f(x, "s") > 1 || !ok`)
+	want := `((((-a.b) + (2 * f(x, "s"))) > 1) || (!ok))`
+	if s := e.String(); s != want {
+		t.Errorf("String() = %s, want %s", s, want)
+	}
+	if _, err := Compile(e.String()); err != nil {
+		t.Errorf("Compile(%q): %v", e.String(), err)
+	}
+}
+
+func TestVariables(t *testing.T) {
+	e := MustCompile("b + a * f(c, b) > 0 && !d")
+	want := []string{"a", "b", "c", "d"}
+	if v := e.Variables(); !reflect.DeepEqual(v, want) {
+		t.Errorf("Variables() = %v, want %v", v, want)
+	}
+}
+
+func TestMustCompilePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustCompile expected to panic")
+		}
+	}()
+	MustCompile("1 +")
+}
+
+func BenchmarkEval(b *testing.B) {
+	e := MustCompile(`errors / max(requests, 1) > 0.05 && service == "api"`)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := e.Eval(testEnv); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
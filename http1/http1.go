@@ -0,0 +1,472 @@
+// Package http1 implements parsers for the heads of HTTP/1.1 messages as
+// defined by RFC 9112: the request line or the status line followed by the
+// header field lines up to the empty line which precedes the body.
+//
+// A Config determines how strictly the grammar is followed and limits the
+// sizes of the lines, the head, and the number of fields, so a peer cannot
+// make the parser buffer an unbounded amount of input. The bytes of a head are
+// read from a pars.State, which is left at the first byte of the body. As a
+// State is an io.Reader itself, the body can be read from the State:
+//
+//   state := pars.NewState(conn)
+//   req, err := http1.ReadRequest(state)
+//   ...
+//   body := io.LimitReader(state, length)
+//
+// For a connection handled by an event loop, the parsers can be applied to a
+// state created with pars.NewPushState with ParsePartial, which reports
+// pars.ErrNeedMore until the entire head has been fed:
+//
+//   state.Feed(p)
+//   result, err := http1.Default.Request().ParsePartial(state)
+//   if err == pars.ErrNeedMore {
+//       // Wait for more bytes.
+//   }
+package http1
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-pars/pars"
+)
+
+const unexpectedEOF = "unexpected end of input"
+
+// Field is a header field.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Header is a list of header fields in the order they appeared. The names
+// are kept as they were written and are compared case-insensitively.
+type Header []Field
+
+// Get returns the value of the first field with the given name.
+func (h Header) Get(name string) string {
+	for _, f := range h {
+		if strings.EqualFold(f.Name, name) {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Values returns the values of all of the fields with the given name.
+func (h Header) Values(name string) []string {
+	var v []string
+	for _, f := range h {
+		if strings.EqualFold(f.Name, name) {
+			v = append(v, f.Value)
+		}
+	}
+	return v
+}
+
+// Request is the head of a request message.
+type Request struct {
+	Method string
+	Target string
+	Major  int
+	Minor  int
+	Header Header
+}
+
+// Response is the head of a response message.
+type Response struct {
+	Major  int
+	Minor  int
+	Status int
+	Reason string
+	Header Header
+}
+
+// Config configures the strictness and the size limits of the parsers. A
+// limit of zero means there is no limit.
+type Config struct {
+	// Lenient allows what RFC 9112 permits a recipient to accept for the
+	// sake of robustness: a bare LF as a line ending, runs of whitespace
+	// between the parts of the start line, and a status line without the
+	// space before an empty reason phrase.
+	Lenient bool
+
+	// ObsFold replaces each obsolete line folding in a field value with a
+	// space instead of rejecting the message.
+	ObsFold bool
+
+	// MaxLineBytes limits the length of each line of the head.
+	MaxLineBytes int
+
+	// MaxHeadBytes limits the length of the entire head.
+	MaxHeadBytes int
+
+	// MaxFields limits the number of header fields.
+	MaxFields int
+}
+
+// Predefined configurations.
+var (
+	// Default follows the grammar strictly and sets limits suitable for a
+	// server.
+	Default = Config{MaxLineBytes: 8 << 10, MaxHeadBytes: 64 << 10, MaxFields: 100}
+
+	// Lenient accepts the deviations from the grammar which recipients are
+	// permitted to accept, including obsolete line folding.
+	Lenient = Config{Lenient: true, ObsFold: true, MaxLineBytes: 8 << 10, MaxHeadBytes: 64 << 10, MaxFields: 100}
+)
+
+func isTchar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+}
+
+func isVchar(c byte) bool { return 0x21 <= c && c <= 0x7e }
+
+// isFieldByte tests if the byte may appear in a field value or a reason
+// phrase, which includes the obsolete non-ASCII bytes.
+func isFieldByte(c byte) bool { return c == '\t' || c >= ' ' && c != 0x7f }
+
+func isOWS(c byte) bool { return c == ' ' || c == '\t' }
+
+// reader reads the lines of a message head within the limits of a Config.
+type reader struct {
+	Config
+	state *pars.State
+	size  int
+}
+
+// at returns the position of the i'th byte of a line starting at pos.
+func at(pos pars.Position, i int) pars.Position {
+	return pars.Position{Line: pos.Line, Byte: pos.Byte + i}
+}
+
+// line matches a line and its line ending and returns the bytes of the line.
+func (r *reader) line() ([]byte, pars.Position, error) {
+	state := r.state
+	pos := state.Position()
+	state.Push()
+	n := 0
+	for {
+		c, err := pars.Next(state)
+		if err != nil || c == '\r' || c == '\n' {
+			break
+		}
+		n++
+		if r.MaxLineBytes > 0 && n > r.MaxLineBytes {
+			state.Pop()
+			return nil, pos, pars.NewError(fmt.Sprintf("line is longer than %d bytes", r.MaxLineBytes), pos)
+		}
+		if r.MaxHeadBytes > 0 && r.size+n > r.MaxHeadBytes {
+			state.Pop()
+			return nil, pos, pars.NewError(fmt.Sprintf("message head is longer than %d bytes", r.MaxHeadBytes), pos)
+		}
+		state.Advance()
+	}
+	p, _ := pars.Trail(state)
+
+	end := state.Position()
+	eol := pars.Result{}
+	if err := pars.EOL(state, &eol); err != nil {
+		return nil, pos, err
+	}
+	switch string(eol.Token) {
+	case "\r\n":
+	case "\n":
+		if !r.Lenient {
+			return nil, pos, pars.NewError("expected CRLF", end)
+		}
+	case "":
+		return nil, pos, pars.NewError(unexpectedEOF, end)
+	default:
+		return nil, pos, pars.NewError("expected LF after CR", end)
+	}
+	r.size += n + len(eol.Token)
+	if r.MaxHeadBytes > 0 && r.size > r.MaxHeadBytes {
+		return nil, pos, pars.NewError(fmt.Sprintf("message head is longer than %d bytes", r.MaxHeadBytes), end)
+	}
+	return p, pos, nil
+}
+
+// part is a part of a start line and its index in the line.
+type part struct {
+	p []byte
+	i int
+}
+
+// split splits a start line into at most n parts separated by a space, or
+// by runs of whitespace if lenient. The last part is the rest of the line.
+func (r *reader) split(p []byte, n int) []part {
+	isSep := func(c byte) bool {
+		return c == ' ' || r.Lenient && (c == '\t' || c == '\v' || c == '\f')
+	}
+	skip := func(i int) int {
+		for r.Lenient && i < len(p) && isSep(p[i]) {
+			i++
+		}
+		return i
+	}
+
+	parts := []part{}
+	i := skip(0)
+	for len(parts) < n-1 {
+		j := i
+		for j < len(p) && !isSep(p[j]) {
+			j++
+		}
+		parts = append(parts, part{p[i:j], i})
+		if j == len(p) {
+			return parts
+		}
+		i = skip(j + 1)
+	}
+	rest := p[i:]
+	for r.Lenient && len(rest) > 0 && isSep(rest[len(rest)-1]) {
+		rest = rest[:len(rest)-1]
+	}
+	return append(parts, part{rest, i})
+}
+
+// version parses an HTTP version.
+func version(p part, pos pars.Position) (int, int, error) {
+	b := p.p
+	if len(b) != 8 || string(b[:5]) != "HTTP/" || b[6] != '.' ||
+		b[5] < '0' || '9' < b[5] || b[7] < '0' || '9' < b[7] {
+		return 0, 0, pars.NewError("invalid HTTP version", at(pos, p.i))
+	}
+	return int(b[5] - '0'), int(b[7] - '0'), nil
+}
+
+// request matches a request line.
+func (r *reader) request() (*Request, error) {
+	var p []byte
+	var pos pars.Position
+	// A server should ignore empty lines before the request line.
+	for len(p) == 0 {
+		var err error
+		if p, pos, err = r.line(); err != nil {
+			return nil, err
+		}
+	}
+
+	parts := r.split(p, 3)
+	method, target := parts[0], part{nil, len(p)}
+	if len(parts) > 1 {
+		target = parts[1]
+	}
+	if len(method.p) == 0 {
+		return nil, pars.NewError("expected a method", at(pos, method.i))
+	}
+	for i, c := range method.p {
+		if !isTchar(c) {
+			return nil, pars.NewError(fmt.Sprintf("invalid byte %q in method", c), at(pos, method.i+i))
+		}
+	}
+	if len(target.p) == 0 {
+		return nil, pars.NewError("expected a request target", at(pos, target.i))
+	}
+	for i, c := range target.p {
+		if !isVchar(c) {
+			return nil, pars.NewError(fmt.Sprintf("invalid byte %q in request target", c), at(pos, target.i+i))
+		}
+	}
+	if len(parts) < 3 {
+		return nil, pars.NewError("expected an HTTP version", at(pos, len(p)))
+	}
+	major, minor, err := version(parts[2], pos)
+	if err != nil {
+		return nil, err
+	}
+	return &Request{Method: string(method.p), Target: string(target.p), Major: major, Minor: minor}, nil
+}
+
+// response matches a status line.
+func (r *reader) response() (*Response, error) {
+	p, pos, err := r.line()
+	if err != nil {
+		return nil, err
+	}
+	parts := r.split(p, 3)
+	major, minor, err := version(parts[0], pos)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) < 2 {
+		return nil, pars.NewError("expected a status code", at(pos, len(p)))
+	}
+	code := parts[1]
+	if len(code.p) != 3 || strings.Trim(string(code.p), "0123456789") != "" {
+		return nil, pars.NewError("invalid status code", at(pos, code.i))
+	}
+	if len(parts) < 3 && !r.Lenient {
+		return nil, pars.NewError("expected a space after the status code", at(pos, len(p)))
+	}
+	res := &Response{Major: major, Minor: minor}
+	res.Status = int(code.p[0]-'0')*100 + int(code.p[1]-'0')*10 + int(code.p[2]-'0')
+	if len(parts) == 3 {
+		reason := parts[2]
+		for i, c := range reason.p {
+			if !isFieldByte(c) {
+				return nil, pars.NewError(fmt.Sprintf("invalid byte %q in reason phrase", c), at(pos, reason.i+i))
+			}
+		}
+		res.Reason = string(reason.p)
+	}
+	return res, nil
+}
+
+// value validates a field value and removes the whitespace around it.
+func value(p []byte, i int, pos pars.Position) (string, error) {
+	for j, c := range p[i:] {
+		if !isFieldByte(c) {
+			return "", pars.NewError(fmt.Sprintf("invalid byte %q in field value", c), at(pos, i+j))
+		}
+	}
+	return strings.Trim(string(p[i:]), " \t"), nil
+}
+
+// field parses a field line.
+func field(p []byte, pos pars.Position) (Field, error) {
+	n := 0
+	for n < len(p) && isTchar(p[n]) {
+		n++
+	}
+	switch {
+	case n == 0:
+		return Field{}, pars.NewError("expected a field name", pos)
+	case n == len(p):
+		return Field{}, pars.NewError("expected `:`", at(pos, n))
+	case isOWS(p[n]):
+		return Field{}, pars.NewError("whitespace between field name and `:`", at(pos, n))
+	case p[n] != ':':
+		return Field{}, pars.NewError(fmt.Sprintf("invalid byte %q in field name", p[n]), at(pos, n))
+	}
+	v, err := value(p, n+1, pos)
+	if err != nil {
+		return Field{}, err
+	}
+	return Field{string(p[:n]), v}, nil
+}
+
+// header matches the field lines up to and including the empty line.
+func (r *reader) header() (Header, error) {
+	h := Header{}
+	for {
+		p, pos, err := r.line()
+		if err != nil {
+			return nil, err
+		}
+		if len(p) == 0 {
+			return h, nil
+		}
+
+		if isOWS(p[0]) {
+			switch {
+			case len(h) == 0:
+				return nil, pars.NewError("unexpected whitespace before the first field", pos)
+			case !r.ObsFold:
+				return nil, pars.NewError("obsolete line folding", pos)
+			}
+			v, err := value(p, 0, pos)
+			if err != nil {
+				return nil, err
+			}
+			if last := &h[len(h)-1]; last.Value == "" {
+				last.Value = v
+			} else if v != "" {
+				last.Value += " " + v
+			}
+			continue
+		}
+
+		if r.MaxFields > 0 && len(h) == r.MaxFields {
+			return nil, pars.NewError(fmt.Sprintf("more than %d header fields", r.MaxFields), pos)
+		}
+		f, err := field(p, pos)
+		if err != nil {
+			return nil, err
+		}
+		h = append(h, f)
+	}
+}
+
+// parser creates a Parser which will apply the given function with a new
+// reader and set the value to its return value.
+func (c Config) parser(f func(r *reader) (interface{}, error)) pars.Parser {
+	return func(state *pars.State, result *pars.Result) error {
+		state.Push()
+		v, err := f(&reader{Config: c, state: state})
+		if err != nil {
+			state.Pop()
+			return err
+		}
+		state.Drop()
+		result.SetValue(v)
+		return nil
+	}
+}
+
+// Request creates a Parser which will match the head of a request message
+// and set the value to a *Request.
+func (c Config) Request() pars.Parser {
+	return c.parser(func(r *reader) (interface{}, error) {
+		req, err := r.request()
+		if err != nil {
+			return nil, err
+		}
+		if req.Header, err = r.header(); err != nil {
+			return nil, err
+		}
+		return req, nil
+	})
+}
+
+// Response creates a Parser which will match the head of a response message
+// and set the value to a *Response.
+func (c Config) Response() pars.Parser {
+	return c.parser(func(r *reader) (interface{}, error) {
+		res, err := r.response()
+		if err != nil {
+			return nil, err
+		}
+		if res.Header, err = r.header(); err != nil {
+			return nil, err
+		}
+		return res, nil
+	})
+}
+
+// Header creates a Parser which will match field lines up to and including
+// an empty line and set the value to a Header. This is also the grammar of
+// the trailer section after a chunked body.
+func (c Config) Header() pars.Parser {
+	return c.parser(func(r *reader) (interface{}, error) { return r.header() })
+}
+
+var (
+	defaultRequest  = Default.Request()
+	defaultResponse = Default.Response()
+)
+
+// ReadRequest reads the head of a request message with the Default Config.
+// The state is left at the first byte of the body.
+func ReadRequest(state *pars.State) (*Request, error) {
+	result, err := defaultRequest.Parse(state)
+	if err != nil {
+		return nil, err
+	}
+	return result.Value.(*Request), nil
+}
+
+// ReadResponse reads the head of a response message with the Default Config.
+// The state is left at the first byte of the body.
+func ReadResponse(state *pars.State) (*Response, error) {
+	result, err := defaultResponse.Parse(state)
+	if err != nil {
+		return nil, err
+	}
+	return result.Value.(*Response), nil
+}
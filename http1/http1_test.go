@@ -0,0 +1,236 @@
+package http1
+
+import (
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-pars/pars"
+)
+
+var requestTests = []struct {
+	c   Config
+	in  string
+	out *Request
+}{
+	{
+		Default,
+		"GET /index.html?q=1 HTTP/1.1\r\nHost: example.com\r\nAccept:  */* \r\nX-Empty:\r\n\r\n",
+		&Request{"GET", "/index.html?q=1", 1, 1, Header{
+			{"Host", "example.com"},
+			{"Accept", "*/*"},
+			{"X-Empty", ""},
+		}},
+	},
+	{
+		Default,
+		"\r\nOPTIONS * HTTP/1.0\r\n\r\n",
+		&Request{"OPTIONS", "*", 1, 0, Header{}},
+	},
+	{
+		Default,
+		"CONNECT example.com:443 HTTP/1.1\r\nUser-Agent: caf\xc3\xa9\r\n\r\n",
+		&Request{"CONNECT", "example.com:443", 1, 1, Header{{"User-Agent", "caf\xc3\xa9"}}},
+	},
+	{
+		Lenient,
+		"  GET \t /  HTTP/1.1 \nHost: a\n X-Folded: no\nX-Long: a\n  b\n\tc\n\n",
+		&Request{"GET", "/", 1, 1, Header{{"Host", "a X-Folded: no"}, {"X-Long", "a b c"}}},
+	},
+}
+
+func TestRequest(t *testing.T) {
+	for _, tt := range requestTests {
+		result, err := tt.c.Request().Parse(pars.FromString(tt.in))
+		if err != nil {
+			t.Errorf("Request().Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(result.Value, tt.out) {
+			t.Errorf("Request().Parse(%q) = %+v, want %+v", tt.in, result.Value, tt.out)
+		}
+	}
+}
+
+var responseTests = []struct {
+	c   Config
+	in  string
+	out *Response
+}{
+	{
+		Default,
+		"HTTP/1.1 200 OK\r\nContent-Type: text/plain\r\nSet-Cookie: a=1\r\nset-cookie: b=2\r\n\r\n",
+		&Response{1, 1, 200, "OK", Header{
+			{"Content-Type", "text/plain"},
+			{"Set-Cookie", "a=1"},
+			{"set-cookie", "b=2"},
+		}},
+	},
+	{
+		Default,
+		"HTTP/1.1 404 Not  Found\r\n\r\n",
+		&Response{1, 1, 404, "Not  Found", Header{}},
+	},
+	{
+		Default,
+		"HTTP/1.0 204 \r\n\r\n",
+		&Response{1, 0, 204, "", Header{}},
+	},
+	{
+		Lenient,
+		"HTTP/1.1 204\n\n",
+		&Response{1, 1, 204, "", Header{}},
+	},
+}
+
+func TestResponse(t *testing.T) {
+	for _, tt := range responseTests {
+		result, err := tt.c.Response().Parse(pars.FromString(tt.in))
+		if err != nil {
+			t.Errorf("Response().Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(result.Value, tt.out) {
+			t.Errorf("Response().Parse(%q) = %+v, want %+v", tt.in, result.Value, tt.out)
+		}
+	}
+}
+
+var errorTests = []struct {
+	c   Config
+	p   func(Config) pars.Parser
+	in  string
+	pos pars.Position
+}{
+	{Default, Config.Request, "GET / HTTP/1.1\nHost: a\n\n", pars.Position{Line: 0, Byte: 14}},
+	{Default, Config.Request, "GET / HTTP/1.1\r\nHost: a\r\n", pars.Position{Line: 2, Byte: 0}},
+	{Default, Config.Request, "GET  / HTTP/1.1\r\n\r\n", pars.Position{Line: 0, Byte: 4}},
+	{Default, Config.Request, "GET /\r\n\r\n", pars.Position{Line: 0, Byte: 5}},
+	{Default, Config.Request, "GET\r\n\r\n", pars.Position{Line: 0, Byte: 3}},
+	{Default, Config.Request, "G@T / HTTP/1.1\r\n\r\n", pars.Position{Line: 0, Byte: 1}},
+	{Default, Config.Request, "GET / HTTP/1.1 \r\n\r\n", pars.Position{Line: 0, Byte: 6}},
+	{Default, Config.Request, "GET / http/1.1\r\n\r\n", pars.Position{Line: 0, Byte: 6}},
+	{Default, Config.Request, "GET /\x7f HTTP/1.1\r\n\r\n", pars.Position{Line: 0, Byte: 5}},
+	{Default, Config.Request, "GET / HTTP/1.1\r\nHost : a\r\n\r\n", pars.Position{Line: 1, Byte: 4}},
+	{Default, Config.Request, "GET / HTTP/1.1\r\nHo(st: a\r\n\r\n", pars.Position{Line: 1, Byte: 2}},
+	{Default, Config.Request, "GET / HTTP/1.1\r\nHost\r\n\r\n", pars.Position{Line: 1, Byte: 4}},
+	{Default, Config.Request, "GET / HTTP/1.1\r\nHost: a\x00b\r\n\r\n", pars.Position{Line: 1, Byte: 7}},
+	{Default, Config.Request, "GET / HTTP/1.1\r\nHost: a\rb\r\n\r\n", pars.Position{Line: 1, Byte: 7}},
+	{Default, Config.Request, "GET / HTTP/1.1\r\nA: b\r\n c\r\n\r\n", pars.Position{Line: 2, Byte: 0}},
+	{Lenient, Config.Request, "GET / HTTP/1.1\r\n A: b\r\n\r\n", pars.Position{Line: 1, Byte: 0}},
+	{Default, Config.Response, "HTTP/1.1 20 OK\r\n\r\n", pars.Position{Line: 0, Byte: 9}},
+	{Default, Config.Response, "HTTP/1.1 200\r\n\r\n", pars.Position{Line: 0, Byte: 12}},
+	{Default, Config.Response, "HTTP/1.1 200 O\x01K\r\n\r\n", pars.Position{Line: 0, Byte: 14}},
+	{Default, Config.Response, "HTTP/2 200 OK\r\n\r\n", pars.Position{Line: 0, Byte: 0}},
+	{Config{MaxLineBytes: 8}, Config.Request, "GET /abcdef HTTP/1.1\r\n\r\n", pars.Position{Line: 0, Byte: 0}},
+	{Config{MaxHeadBytes: 24}, Config.Request, "GET / HTTP/1.1\r\nA: b\r\nC: d\r\n\r\n", pars.Position{Line: 2, Byte: 0}},
+	{Config{MaxFields: 1}, Config.Request, "GET / HTTP/1.1\r\nA: b\r\nC: d\r\n\r\n", pars.Position{Line: 2, Byte: 0}},
+}
+
+func TestError(t *testing.T) {
+	for _, tt := range errorTests {
+		_, err := tt.p(tt.c).Parse(pars.FromString(tt.in))
+		var perr pars.Error
+		if !errors.As(err, &perr) || perr.Position() != tt.pos {
+			t.Errorf("Parse(%q) = %v, want an error at %v", tt.in, err, tt.pos)
+		}
+	}
+}
+
+func TestHeader(t *testing.T) {
+	h := Header{{"Content-Type", "text/html"}, {"Vary", "Accept"}, {"vary", "Origin"}}
+	if v := h.Get("content-type"); v != "text/html" {
+		t.Errorf("Get(content-type) = %q, want %q", v, "text/html")
+	}
+	if v := h.Get("missing"); v != "" {
+		t.Errorf("Get(missing) = %q, want %q", v, "")
+	}
+	if v := h.Values("VARY"); !reflect.DeepEqual(v, []string{"Accept", "Origin"}) {
+		t.Errorf("Values(VARY) = %q, want [Accept Origin]", v)
+	}
+
+	trailer := "Expires: never\r\n\r\nrest"
+	state := pars.FromString(trailer)
+	result, err := Default.Header().Parse(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := (Header{{"Expires", "never"}}); !reflect.DeepEqual(result.Value, e) {
+		t.Errorf("Header().Parse(%q) = %v, want %v", trailer, result.Value, e)
+	}
+}
+
+func TestReadBody(t *testing.T) {
+	in := "POST /submit HTTP/1.1\r\nContent-Length: 5\r\n\r\nhelloHTTP/1.1 200 OK\r\n\r\n"
+	state := pars.NewState(strings.NewReader(in))
+	req, err := ReadRequest(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "POST" || req.Header.Get("content-length") != "5" {
+		t.Errorf("ReadRequest() = %+v", req)
+	}
+	body := make([]byte, 5)
+	if _, err := state.Read(body); err != nil || string(body) != "hello" {
+		t.Errorf("body = %q, %v, want %q", body, err, "hello")
+	}
+	res, err := ReadResponse(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != 200 {
+		t.Errorf("ReadResponse() = %+v", res)
+	}
+	if rest, _ := ioutil.ReadAll(state); len(rest) != 0 {
+		t.Errorf("unread bytes %q", rest)
+	}
+}
+
+func TestPushState(t *testing.T) {
+	in := "GET / HTTP/1.1\r\nHost: example.com\r\n\r\nGET /next HTTP/1.1\r\n\r\n"
+	p := Default.Request()
+	state := pars.NewPushState()
+	var targets []string
+	needs := 0
+	for i := 0; i < len(in); i++ {
+		state.Feed([]byte{in[i]})
+		result, err := p.ParsePartial(state)
+		switch err {
+		case nil:
+			targets = append(targets, result.Value.(*Request).Target)
+		case pars.ErrNeedMore:
+			needs++
+		default:
+			t.Fatalf("ParsePartial() after %q: %v", in[:i+1], err)
+		}
+	}
+	if !reflect.DeepEqual(targets, []string{"/", "/next"}) {
+		t.Errorf("targets = %q, want [/ /next]", targets)
+	}
+	if needs != len(in)-2 {
+		t.Errorf("needed more input %d times, want %d", needs, len(in)-2)
+	}
+
+	// Errors are reported as soon as an invalid line is complete, without
+	// waiting for the end of the head.
+	state = pars.NewPushState()
+	state.Feed([]byte("GET / HTTP/1.1\r\nBad Name: x\r\n"))
+	var perr pars.Error
+	if _, err := p.ParsePartial(state); !errors.As(err, &perr) || perr.Position() != (pars.Position{Line: 1, Byte: 3}) {
+		t.Errorf("ParsePartial() = %v, want an error at line 2", err)
+	}
+}
+
+func BenchmarkRequest(b *testing.B) {
+	p := []byte("GET /index.html HTTP/1.1\r\nHost: example.com\r\nUser-Agent: Mozilla/5.0 (X11; Linux x86_64)\r\nAccept: text/html,application/xhtml+xml\r\nAccept-Language: en-US,en;q=0.5\r\nConnection: keep-alive\r\n\r\n")
+	parser := Default.Request()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(p)))
+	for i := 0; i < b.N; i++ {
+		if _, err := parser.Parse(pars.FromBytes(p)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package pars
+
+import "errors"
+
+// IncrementalParser parses a document which is edited over time, such as the
+// contents of an editor buffer. The memoized results of the previous parse
+// are kept between edits, so after an edit only the Parsers created with
+// Memoize which examined the edited bytes are applied again. The Parsers
+// which are not memoized are always applied again, so the rules which match
+// large parts of the document, such as the statements or lines, should be
+// memoized with Memoize for the edits to be cheap.
+type IncrementalParser struct {
+	parser Parser
+	text   []byte
+	memo   *memoTable
+}
+
+// NewIncrementalParser creates a new IncrementalParser for an empty document
+// which will parse the entire document with the given Parser.
+func NewIncrementalParser(q interface{}) *IncrementalParser {
+	return &IncrementalParser{parser: AsParser(q), text: []byte{}, memo: newMemoTable()}
+}
+
+func (ip *IncrementalParser) parse() (Result, error) {
+	state := FromBytes(ip.text)
+	state.memo = ip.memo
+	result, err := ip.parser.Parse(state)
+	if err == nil {
+		err = End(state, nil)
+	}
+	return result, err
+}
+
+// Parse replaces the document with the given bytes and parses the entire
+// document from scratch, discarding the memoized results. The given bytes
+// must not be modified afterwards as the Tokens of the results refer to them.
+func (ip *IncrementalParser) Parse(p []byte) (Result, error) {
+	ip.text = p
+	ip.memo = newMemoTable()
+	return ip.parse()
+}
+
+// Edit replaces the given number of bytes at the given offset of the document
+// with the given bytes and parses the edited document, reusing the memoized
+// results which are not affected by the edit.
+func (ip *IncrementalParser) Edit(off, removed int, inserted []byte) (Result, error) {
+	if off < 0 || removed < 0 || off+removed > len(ip.text) {
+		return Result{}, errors.New("edit is out of range of the document")
+	}
+
+	// The previous text is left as is as the memoized results refer to it.
+	text := make([]byte, 0, len(ip.text)-removed+len(inserted))
+	text = append(text, ip.text[:off]...)
+	text = append(text, inserted...)
+	text = append(text, ip.text[off+removed:]...)
+	ip.text = text
+
+	ip.memo.edit(off, removed, len(inserted))
+	return ip.parse()
+}
+
+// Text returns the current document. The bytes must not be modified.
+func (ip *IncrementalParser) Text() []byte { return ip.text }
@@ -0,0 +1,115 @@
+package pars
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-ascii/ascii"
+)
+
+func TestMemoize(t *testing.T) {
+	calls := 0
+	word := Word(ascii.IsLetter)
+	counted := Memoize(func(state *State, result *Result) error {
+		calls++
+		return word(state, result)
+	})
+
+	// Both alternatives start with the memoized word.
+	p := Any(Seq(counted, '!'), Seq(counted, '?'))
+	for _, in := range []string{"hello?", "hello!"} {
+		calls = 0
+		result, err := p.Parse(FromString(in))
+		if err != nil {
+			t.Fatalf("parser(%q): %v", in, err)
+		}
+		if string(result.Children[0].Token) != "hello" {
+			t.Errorf("parser(%q) = %v", in, result)
+		}
+		if calls != 1 {
+			t.Errorf("parser(%q) applied the memoized parser %d times", in, calls)
+		}
+	}
+
+	// Failures are memoized too.
+	calls = 0
+	if _, err := p.Parse(FromString("123")); err == nil {
+		t.Errorf("expected error")
+	}
+	if calls != 1 {
+		t.Errorf("failing parser was applied %d times", calls)
+	}
+}
+
+var incrementalLine = Memoize(Seq(Word(ascii.IsLetter), '=', Int, EOL).Map(func(result *Result) error {
+	result.SetValue(fmt.Sprintf("%s:%d", result.Children[0].Token, result.Children[2].Value))
+	return nil
+}))
+
+func incrementalValues(result Result) []string {
+	v := []string{}
+	for _, child := range result.Children {
+		v = append(v, child.Value.(string))
+	}
+	return v
+}
+
+func TestIncrementalParser(t *testing.T) {
+	lines := make([]string, 10)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("k=%d\n", i)
+	}
+	text := strings.Join(lines, "")
+
+	ip := NewIncrementalParser(Many(incrementalLine))
+	if _, err := ip.Parse([]byte(text)); err != nil {
+		t.Fatal(err)
+	}
+
+	edits := []struct {
+		off      int
+		removed  int
+		inserted string
+		hits     int
+	}{
+		{0, 0, "a=0\n", 10},  // insert a line at the beginning
+		{22, 1, "42", 10},    // replace the value of k=4
+		{45, 0, "z=9\n", 11}, // append a line
+		{4, 4, "", 11},       // remove k=0
+	}
+	for _, tt := range edits {
+		before := string(ip.Text())
+		text := before[:tt.off] + tt.inserted + before[tt.off+tt.removed:]
+		e, err := Exact(Many(incrementalLine)).Parse(FromString(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ip.memo.hits = 0
+		result, err := ip.Edit(tt.off, tt.removed, []byte(tt.inserted))
+		if err != nil {
+			t.Fatalf("Edit(%d, %d, %q): %v", tt.off, tt.removed, tt.inserted, err)
+		}
+		if string(ip.Text()) != text {
+			t.Fatalf("text = %q, want %q", ip.Text(), text)
+		}
+		if v, e := incrementalValues(result), incrementalValues(e); !same(v, e) {
+			t.Errorf("Edit(%d, %d, %q) = %v, want %v", tt.off, tt.removed, tt.inserted, v, e)
+		}
+		if ip.memo.hits != tt.hits {
+			t.Errorf("Edit(%d, %d, %q) reused %d results, want %d", tt.off, tt.removed, tt.inserted, ip.memo.hits, tt.hits)
+		}
+	}
+
+	// Break the first line so the document no longer matches.
+	if _, err := ip.Edit(2, 1, []byte("x")); err == nil {
+		t.Errorf("expected error")
+	}
+	if _, err := ip.Edit(2, 1, []byte("0")); err != nil {
+		t.Errorf("Edit(2, 1, %q): %v", "0", err)
+	}
+	if _, err := ip.Edit(len(ip.Text()), 1, nil); err == nil {
+		t.Errorf("expected error for an edit out of range")
+	}
+}
@@ -0,0 +1,107 @@
+// Package ini implements a parser for the INI files of legacy applications.
+// A file consists of lines of the following forms:
+//
+//   [section]
+//   key = value
+//   key: value
+//   ; comment
+//   # comment
+//
+// The keys before the first section belong to the unnamed section "". The
+// spaces around section names, keys, and values are removed, and a value
+// enclosed in matching double or single quotes is unquoted. A comment must be
+// on a line of its own, so a value may contain `;` and `#`.
+package ini
+
+import (
+	"io"
+	"strings"
+
+	"github.com/go-pars/pars"
+)
+
+// Section is the value of a Result for a section header.
+type Section string
+
+// Property is the value of a Result for a key/value line.
+type Property struct {
+	Key   string
+	Value string
+}
+
+func isSectionName(c byte) bool { return c != ']' && c != '\r' && c != '\n' }
+
+func isKey(c byte) bool { return c != '=' && c != ':' && c != '\r' && c != '\n' }
+
+// separator matches the `=` or `:` between a key and a value.
+func separator(state *pars.State, result *pars.Result) error {
+	if c, err := pars.Next(state); err != nil || (c != '=' && c != ':') {
+		return pars.NewError("expected `=` or `:`", state.Position())
+	}
+	state.Advance()
+	return nil
+}
+
+// unquote removes the spaces and the matching quotes around a value.
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if n := len(s); n >= 2 && (s[0] == '"' || s[0] == '\'') && s[n-1] == s[0] {
+		return s[1 : n-1]
+	}
+	return s
+}
+
+var blank = pars.Many(pars.Byte(' ', '\t'))
+
+// INI parser parts.
+var (
+	// Comment matches a comment line.
+	Comment = pars.Seq(pars.Byte(';', '#'), pars.Line)
+
+	// Header matches a section header and sets the value to a Section.
+	Header = pars.Seq('[', pars.Word(isSectionName), ']', blank, pars.EOL).Map(func(result *pars.Result) error {
+		name := strings.TrimSpace(string(result.Children[1].Token))
+		result.SetValue(Section(name))
+		return nil
+	})
+
+	// Entry matches a key/value line and sets the value to a Property.
+	Entry = pars.Seq(pars.Word(isKey), separator, pars.Line).Map(func(result *pars.Result) error {
+		key := strings.TrimSpace(string(result.Children[0].Token))
+		value := unquote(string(result.Children[2].Token))
+		result.SetValue(Property{key, value})
+		return nil
+	})
+
+	// Record matches any single line of a file. The value is nil for empty
+	// lines and comments.
+	Record = pars.Seq(blank, pars.Any(Comment, Header, pars.EOL, Entry)).Map(func(result *pars.Result) error {
+		result.SetValue(result.Children[1].Value)
+		return nil
+	})
+)
+
+// Unmarshal parses the INI file read from the given io.Reader into a map
+// from section names to the properties of each section. A section which
+// appears more than once is merged, and the last value of a duplicate key is
+// kept. Errors are pars.RecordError values giving the line of the error.
+func Unmarshal(r io.Reader) (map[string]map[string]string, error) {
+	v := map[string]map[string]string{"": {}}
+	current := v[""]
+	err := pars.Records(r, Record, func(result *pars.Result) error {
+		switch x := result.Value.(type) {
+		case Section:
+			if v[string(x)] == nil {
+				v[string(x)] = make(map[string]string)
+			}
+			current = v[string(x)]
+		case Property:
+			current[x.Key] = x.Value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
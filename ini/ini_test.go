@@ -0,0 +1,60 @@
+package ini
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-pars/pars"
+)
+
+func TestUnmarshal(t *testing.T) {
+	in := `; global settings
+name = example
+  debug: true
+
+[server]
+host = "localhost "
+port=8080
+url = http://x/#frag ; not a comment
+
+# more
+[ paths ]
+root = '/var/www'
+[server]
+port = 9090
+empty =
+`
+	e := map[string]map[string]string{
+		"":       {"name": "example", "debug": "true"},
+		"server": {"host": "localhost ", "port": "9090", "url": "http://x/#frag ; not a comment", "empty": ""},
+		"paths":  {"root": "/var/www"},
+	}
+	out, err := Unmarshal(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, e) {
+		t.Errorf("Unmarshal() = %q, want %q", out, e)
+	}
+}
+
+func TestUnmarshalError(t *testing.T) {
+	for _, tt := range []struct {
+		in     string
+		record int
+		pos    pars.Position
+	}{
+		{"a = 1\nb\nc = 2\n", 1, pars.Position{Line: 1, Byte: 1}},
+		{"[broken\n", 0, pars.Position{Line: 0, Byte: 7}},
+		{"  = 1", 0, pars.Position{Line: 0, Byte: 2}},
+	} {
+		_, err := Unmarshal(strings.NewReader(tt.in))
+		var re pars.RecordError
+		var pe pars.Error
+		if !errors.As(err, &re) || re.Record() != tt.record || !errors.As(err, &pe) || pe.Position() != tt.pos {
+			t.Errorf("Unmarshal(%q) = %v, want error in record %d at %v", tt.in, err, tt.record+1, tt.pos)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package pars
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/go-ascii/ascii"
+)
+
+// Kind represents the kind of Parser described by a Node.
+type Kind int
+
+// Kinds of Parsers.
+const (
+	// KindFunc is a Parser which was not created by this package.
+	KindFunc Kind = iota
+
+	// KindPrimitive is a built-in Parser with no children such as Epsilon or
+	// Int. The Name is the name of the Parser.
+	KindPrimitive
+
+	// KindMap is a Parser modifying the result of its only child, created with
+	// methods such as Map, Child, or Bind.
+	KindMap
+
+	// KindRef is a Parser created by AsParser for a *Parser.
+	KindRef
+
+	// KindNamed is a Parser created by Named.
+	KindNamed
+
+	// KindCapture is a Parser created by Capture.
+	KindCapture
+
+	// KindSeq is a Parser created by Seq.
+	KindSeq
+
+	// KindAny is a Parser created by Any.
+	KindAny
+
+	// KindMaybe is a Parser created by Maybe.
+	KindMaybe
+
+	// KindMany is a Parser created by Many.
+	KindMany
+
+	// KindDelim is a Parser created by Delim. The children are the item and
+	// delimiter Parsers.
+	KindDelim
+
+	// KindDry is a Parser created by Dry.
+	KindDry
+
+	// KindNot is a Parser created by Not.
+	KindNot
+
+	// KindByte is a Parser created by Byte. The Literal is the set of bytes to
+	// match or nil to match any byte.
+	KindByte
+
+	// KindByteRange is a Parser created by ByteRange. The Literal is the first
+	// and last byte of the range.
+	KindByteRange
+
+	// KindBytes is a Parser created by Bytes. The Literal is the bytes to match.
+	KindBytes
+
+	// KindRune is a Parser created by Rune. The Runes are the set of runes to
+	// match or nil to match any rune.
+	KindRune
+
+	// KindRuneRange is a Parser created by RuneRange. The Runes are the first
+	// and last rune of the range.
+	KindRuneRange
+
+	// KindRunes is a Parser created by Runes. The Runes are the runes to match.
+	KindRunes
+
+	// KindString is a Parser created by String. The Literal is the string.
+	KindString
+
+	// KindFilter is a Parser created by Filter. The Name is the name of the
+	// filter function.
+	KindFilter
+
+	// KindWord is a Parser created by Word. The Name is the name of the filter
+	// function.
+	KindWord
+
+	// KindUntil is a Parser created by Until. Either the Literal is the bytes
+	// to search for, the Name is the name of the filter function, or the only
+	// child is the Parser to search for.
+	KindUntil
+
+	// KindBetween is a Parser created by Between or Quoted. The Literal is the
+	// opening and closing bytes.
+	KindBetween
+
+	// KindWrap is a Parser matching its only child as is while bounding or
+	// caching it, created by Nested or Memoize. The Name is the name of the
+	// combinator.
+	KindWrap
+
+	// KindLengthPrefixed is a Parser created by LengthPrefixed. The children
+	// are the length Parser and the body Parser if one is given.
+	KindLengthPrefixed
+)
+
+var kindNames = [...]string{
+	"Func", "Primitive", "Map", "Ref", "Named", "Capture", "Seq", "Any",
+	"Maybe", "Many", "Delim", "Dry", "Not", "Byte", "ByteRange", "Bytes",
+	"Rune", "RuneRange", "Runes", "String", "Filter", "Word", "Until",
+	"Between", "Wrap", "LengthPrefixed",
+}
+
+// String returns the name of the kind.
+func (k Kind) String() string { return kindNames[k] }
+
+// Node is a description of a Parser for inspecting the structure of a grammar
+// built with the Parsers and combinators in this package.
+type Node struct {
+	Kind    Kind
+	Name    string
+	Literal []byte
+	Runes   []rune
+
+	parser   Parser
+	impl     Parser
+	children []Parser
+	ref      *Parser
+}
+
+// describe returns a Parser which matches the given Parser and is described
+// by the Node. Inspect retrieves the Node by calling the returned Parser with
+// a nil State, so the Node is only referenced by the Parser and is freed along
+// with it instead of being kept in a global table.
+func describe(n *Node, p Parser) Parser {
+	d := func(state *State, result *Result) error {
+		if state == nil {
+			result.Value = n
+			return nil
+		}
+		return p(state, result)
+	}
+	n.parser, n.impl = d, p
+	return d
+}
+
+// direct returns the function wrapped by describe for a described Parser, so
+// the combinators can call their children without going through describe.
+func direct(p Parser) Parser {
+	if reflect.ValueOf(p).Pointer() != describedPC {
+		return p
+	}
+	return Inspect(p).impl
+}
+
+// directs applies direct to each Parser of a copy of the slice.
+func directs(ps []Parser) []Parser {
+	qs := make([]Parser, len(ps))
+	for i, p := range ps {
+		qs[i] = direct(p)
+	}
+	return qs
+}
+
+// describedPC is the code pointer shared by every Parser returned by describe.
+var describedPC = reflect.ValueOf(describe(&Node{}, nil)).Pointer()
+
+// primitives describes the Parsers which are declared as functions, keyed by
+// their code pointers. It is only written during initialization.
+var primitives = make(map[uintptr]*Node)
+
+func declare(name string, p Parser) {
+	primitives[reflect.ValueOf(p).Pointer()] = &Node{Kind: KindPrimitive, Name: name, parser: p}
+}
+
+func primitive(name string, p Parser) Parser {
+	return describe(&Node{Kind: KindPrimitive, Name: name}, p)
+}
+
+func filterName(filter interface{}) string {
+	v := reflect.ValueOf(filter)
+	return runtime.FuncForPC(v.Pointer()).Name()
+}
+
+func init() {
+	declare("Epsilon", Epsilon)
+	declare("Head", Head)
+	declare("End", End)
+	declare("Cut", Cut)
+	declare("Spaces", Spaces)
+	declare("Int", Int)
+	declare("Number", Number)
+	declare("EOL", EOL)
+	declare("Line", Line)
+}
+
+// Inspect returns the Node describing the given Parser, converting it with
+// AsParser if necessary. Inspecting the same Parser created by this package
+// will always return the same Node so Nodes can be compared to detect cycles.
+// Parsers which were not created by this package are described by a new Node
+// of KindFunc on each call, so wrap them with Named to refer to them as rules.
+func Inspect(q interface{}) *Node {
+	p := AsParser(q)
+	pc := reflect.ValueOf(p).Pointer()
+	if pc == describedPC {
+		result := Result{}
+		p(nil, &result)
+		return result.Value.(*Node)
+	}
+	if n, ok := primitives[pc]; ok {
+		return n
+	}
+	return &Node{Kind: KindFunc, Name: filterName(p), parser: p}
+}
+
+// Parser returns the Parser described by the Node.
+func (n *Node) Parser() Parser { return n.parser }
+
+// Children returns the Nodes describing the child Parsers. The child of a
+// KindRef Node is the Parser currently referenced by the pointer.
+func (n *Node) Children() []*Node {
+	if n.ref != nil {
+		if *n.ref == nil {
+			return nil
+		}
+		return []*Node{Inspect(*n.ref)}
+	}
+	children := make([]*Node, len(n.children))
+	for i, p := range n.children {
+		children[i] = Inspect(p)
+	}
+	return children
+}
+
+// String returns a short description of the Node.
+func (n *Node) String() string {
+	switch n.Kind {
+	case KindFunc, KindPrimitive, KindWrap:
+		return n.Name
+	case KindNamed, KindCapture, KindFilter, KindWord:
+		return fmt.Sprintf("%s(%s)", n.Kind, n.Name)
+	case KindByte, KindByteRange, KindBetween:
+		return fmt.Sprintf("%s(%s)", n.Kind, strings.Join(ascii.Reps(n.Literal), ", "))
+	case KindBytes:
+		return fmt.Sprintf("%s([%s])", n.Kind, strings.Join(ascii.Reps(n.Literal), ", "))
+	case KindRune, KindRuneRange:
+		return fmt.Sprintf("%s(%s)", n.Kind, strings.Join(runeReps(n.Runes), ", "))
+	case KindRunes:
+		return fmt.Sprintf("%s([%s])", n.Kind, strings.Join(runeReps(n.Runes), ", "))
+	case KindString:
+		return fmt.Sprintf("%s(%q)", n.Kind, n.Literal)
+	case KindUntil:
+		switch {
+		case n.Name != "":
+			return fmt.Sprintf("%s(%s)", n.Kind, n.Name)
+		case n.Literal != nil:
+			return fmt.Sprintf("%s(%s)", n.Kind, strings.Join(ascii.Reps(n.Literal), ", "))
+		}
+		return n.Kind.String()
+	case KindSeq, KindAny:
+		return fmt.Sprintf("%s(%d)", n.Kind, len(n.children))
+	default:
+		return n.Kind.String()
+	}
+}
+
+// Walk calls the function for the given Node and all Nodes reachable from it
+// in depth-first order, visiting each Node only once. The children of a Node
+// are not visited if the function returns false.
+func Walk(n *Node, f func(*Node) bool) {
+	seen := make(map[*Node]bool)
+	var walk func(*Node)
+	walk = func(n *Node) {
+		if seen[n] {
+			return
+		}
+		seen[n] = true
+		if f(n) {
+			for _, child := range n.Children() {
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+}
+
+// Named creates a Parser which will match the given Parser and describe it
+// with the given name. Naming a Parser has no effect on the result but allows
+// tools inspecting the grammar to refer to it as a rule.
+func Named(name string, q interface{}) Parser {
+	p := AsParser(q)
+	return describe(&Node{Kind: KindNamed, Name: name, children: []Parser{p}}, direct(p))
+}
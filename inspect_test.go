@@ -0,0 +1,136 @@
+package pars
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/go-ascii/ascii"
+)
+
+var inspectTests = []struct {
+	q        interface{}
+	kind     Kind
+	s        string
+	children int
+}{
+	{Epsilon, KindPrimitive, "Epsilon", 0},
+	{Int, KindPrimitive, "Int", 0},
+	{Int64, KindPrimitive, "Integer(64)", 0},
+	{byte('a'), KindByte, "Byte(a)", 0},
+	{Byte(), KindByte, "Byte()", 0},
+	{Digit, KindByteRange, "ByteRange(0, 9)", 0},
+	{[]byte("ab"), KindBytes, "Bytes([a, b])", 0},
+	{'ä', KindRune, "Rune(ä)", 0},
+	{"hello", KindString, `String("hello")`, 0},
+	{Word(ascii.IsDigit), KindWord, "Word(github.com/go-ascii/ascii.IsDigit)", 0},
+	{Until(';'), KindUntil, "Until(;)", 0},
+	{Until(Digit), KindUntil, "Until", 1},
+	{Quoted('"'), KindBetween, `Between(", ")`, 0},
+	{Seq('a', 'b', 'c'), KindSeq, "Seq(3)", 3},
+	{Any('a', 'b'), KindAny, "Any(2)", 2},
+	{Maybe('a'), KindMaybe, "Maybe", 1},
+	{Many('a'), KindMany, "Many", 1},
+	{Delim('a', ','), KindDelim, "Delim", 2},
+	{Dry('a'), KindDry, "Dry", 1},
+	{Not('a'), KindNot, "Not", 1},
+	{Capture("x", 'a'), KindCapture, "Capture(x)", 1},
+	{Seq('a', 'b').Child(0), KindMap, "Map", 1},
+	{Named("rule", 'a'), KindNamed, "Named(rule)", 1},
+	{Nested('a', 3), KindWrap, "Nested(3)", 1},
+	{Memoize('a'), KindWrap, "Memoize", 1},
+	{LengthPrefixed(Uint8, nil, 255), KindLengthPrefixed, "LengthPrefixed", 1},
+	{LengthPrefixed(Uint8, 'a', 255), KindLengthPrefixed, "LengthPrefixed", 2},
+}
+
+func TestInspect(t *testing.T) {
+	for _, tt := range inspectTests {
+		n := Inspect(tt.q)
+		if n.Kind != tt.kind {
+			t.Errorf("Inspect(%v).Kind = %v, want %v", tt.q, n.Kind, tt.kind)
+		}
+		if s := n.String(); s != tt.s {
+			t.Errorf("Inspect(%v).String() = %q, want %q", tt.q, s, tt.s)
+		}
+		if c := len(n.Children()); c != tt.children {
+			t.Errorf("len(Inspect(%v).Children()) = %d, want %d", tt.q, c, tt.children)
+		}
+		if Inspect(n.Parser()) != n {
+			t.Errorf("Inspect(%v): expected the same node for the same parser", tt.q)
+		}
+	}
+}
+
+func TestInspectChildren(t *testing.T) {
+	a, b := Byte('a'), String("b")
+	children := Inspect(Any(a, Seq(b, a))).Children()
+	if children[0] != Inspect(a) {
+		t.Errorf("expected first child to be %v", Inspect(a))
+	}
+	if grandchildren := children[1].Children(); grandchildren[0] != Inspect(b) || grandchildren[1] != Inspect(a) {
+		t.Errorf("unexpected children of %v", children[1])
+	}
+}
+
+func TestInspectFunc(t *testing.T) {
+	var p Parser = func(state *State, result *Result) error { return nil }
+	n := Inspect(p)
+	if n.Kind != KindFunc || n.Name == "" {
+		t.Errorf("Inspect(func) = %v (%v), want named KindFunc", n, n.Kind)
+	}
+	named := Named("rule", p)
+	if Inspect(named) != Inspect(named) {
+		t.Errorf("expected the same node for the same named parser")
+	}
+}
+
+func TestInspectRelease(t *testing.T) {
+	// The literal is only referenced by the parser and its node, so it must be
+	// freed once the parser is no longer used.
+	freed := make(chan struct{})
+	func() {
+		literal := make([]byte, 64)
+		runtime.SetFinalizer(&literal[0], func(*byte) { close(freed) })
+		Inspect(Seq(Bytes(literal), 'a'))
+	}()
+	for i := 0; i < 100; i++ {
+		runtime.GC()
+		select {
+		case <-freed:
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Errorf("expected the parser to be freed")
+}
+
+func TestWalk(t *testing.T) {
+	var value Parser
+	list := Named("list", Seq('(', Delim(&value, ','), ')'))
+	value = Named("value", Any(Int, list))
+
+	n := Inspect(value)
+	if ref := Inspect(list).Children()[0].Children()[1].Children()[0]; ref.Kind != KindRef || ref.Children()[0] != n {
+		t.Errorf("expected reference to resolve to %v", n)
+	}
+
+	names := []string{}
+	Walk(n, func(n *Node) bool {
+		if n.Kind == KindNamed {
+			names = append(names, n.Name)
+		}
+		return true
+	})
+	if len(names) != 2 || names[0] != "value" || names[1] != "list" {
+		t.Errorf("Walk() visited %v, want [value list]", names)
+	}
+
+	count := 0
+	Walk(n, func(n *Node) bool {
+		count++
+		return false
+	})
+	if count != 1 {
+		t.Errorf("Walk() visited %d nodes, want 1", count)
+	}
+}
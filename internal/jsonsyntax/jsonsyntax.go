@@ -0,0 +1,228 @@
+// Package jsonsyntax implements the parts of the JSON grammar shared by the
+// json package and the dialects of the json5 package: the helpers used by
+// the scanners of numbers and strings, and the arrays and objects built from
+// the pars combinators.
+package jsonsyntax
+
+import (
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/go-pars/pars"
+)
+
+// UnexpectedEOF is the error message for input ending within a value.
+const UnexpectedEOF = "unexpected end of input"
+
+// IsDigit tests if the byte is a decimal digit.
+func IsDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+// HexValue returns the value of a hexadecimal digit, or -1 if the byte is not
+// a hexadecimal digit.
+func HexValue(c byte) rune {
+	switch {
+	case '0' <= c && c <= '9':
+		return rune(c - '0')
+	case 'a' <= c && c <= 'f':
+		return rune(c - 'a' + 10)
+	case 'A' <= c && c <= 'F':
+		return rune(c - 'A' + 10)
+	default:
+		return -1
+	}
+}
+
+// Expect matches the given byte or returns an error describing what was
+// expected instead.
+func Expect(state *pars.State, e byte, what string) error {
+	c, err := pars.Next(state)
+	if err != nil {
+		return pars.NewError(UnexpectedEOF, state.Position())
+	}
+	if c != e {
+		return pars.NewError(fmt.Sprintf("expected %s", what), state.Position())
+	}
+	state.Advance()
+	return nil
+}
+
+// ScanHex matches the n hexadecimal digits of an escape and returns their
+// value.
+func ScanHex(state *pars.State, n int) (rune, error) {
+	if err := state.Request(n); err != nil {
+		return 0, pars.NewError(UnexpectedEOF, state.Position())
+	}
+	r := rune(0)
+	for _, c := range state.Buffer() {
+		v := HexValue(c)
+		if v < 0 {
+			return 0, pars.NewError(fmt.Sprintf("expected %d hexadecimal digits", n), state.Position())
+		}
+		r = r<<4 | v
+	}
+	state.Advance()
+	return r, nil
+}
+
+// ScanUnicodeEscape matches the digits of a `\u` escape, combining a
+// surrogate pair given as two escapes into a single rune. A lone surrogate is
+// decoded as the replacement character as with encoding/json.
+func ScanUnicodeEscape(state *pars.State) (rune, error) {
+	r, err := ScanHex(state, 4)
+	if err != nil || !utf16.IsSurrogate(r) {
+		return r, err
+	}
+	state.Push()
+	if state.Request(2) == nil && string(state.Buffer()) == `\u` {
+		state.Advance()
+		if r2, err := ScanHex(state, 4); err == nil {
+			if d := utf16.DecodeRune(r, r2); d != utf8.RuneError {
+				state.Drop()
+				return d, nil
+			}
+		}
+	}
+	state.Pop()
+	return utf8.RuneError, nil
+}
+
+// Duplicates determines how an object with duplicate keys is decoded.
+type Duplicates int
+
+// Policies for duplicate keys.
+const (
+	KeepLast Duplicates = iota
+	KeepFirst
+	Reject
+)
+
+// Containers configures the parsers of arrays and objects.
+type Containers struct {
+	// Value matches the elements of arrays and the values of objects.
+	Value *pars.Parser
+
+	// Key matches the keys of objects and sets the Value to a string.
+	Key pars.Parser
+
+	// Space matches the whitespace between the tokens.
+	Space pars.Parser
+
+	// TrailingComma allows a comma after the last element.
+	TrailingComma bool
+
+	// Duplicates is the policy for objects with duplicate keys.
+	Duplicates Duplicates
+
+	// MaxDepth limits the nesting of arrays and objects if positive.
+	MaxDepth int
+}
+
+// key is the value of an object key when its position is needed to report a
+// duplicate key.
+type key struct {
+	name string
+	pos  pars.Position
+}
+
+func positioned(p pars.Parser) pars.Parser {
+	return func(state *pars.State, result *pars.Result) error {
+		pos := state.Position()
+		if err := p(state, result); err != nil {
+			return err
+		}
+		result.SetValue(key{result.Value.(string), pos})
+		return nil
+	}
+}
+
+// container creates a Parser which will match the elements between the given
+// brackets. An error in the first element is reported where it occurs, but
+// the elements stop before a comma which is not followed by a valid element,
+// where the closing bracket is then expected.
+func (c Containers) container(open, close byte, element pars.Parser) pars.Parser {
+	comma := pars.Seq(',', c.Space)
+	elements := pars.Seq(pars.Delim(element, comma), close)
+	if c.TrailingComma {
+		elements = pars.Seq(pars.Delim(element, comma), pars.Maybe(comma), close)
+	}
+	p := pars.Seq(open, c.Space, pars.Any(close, elements))
+	if c.MaxDepth > 0 {
+		p = pars.Nested(p, c.MaxDepth)
+	}
+	return p
+}
+
+// elements returns the elements matched by a container.
+func elements(result *pars.Result) []pars.Result {
+	if body := result.Children[2]; body.Children != nil {
+		return body.Children[0].Children
+	}
+	return []pars.Result{}
+}
+
+// Array creates a Parser which will match an array and set the Value to a
+// []interface{}. The results of the elements are kept as the Children.
+func (c Containers) Array() pars.Parser {
+	item := pars.Seq(c.Value, c.Space)
+	return c.container('[', ']', item).Map(func(result *pars.Result) error {
+		items := elements(result)
+		v := make([]interface{}, len(items))
+		for i := range items {
+			items[i] = items[i].Children[0]
+			v[i] = items[i].Value
+		}
+		result.SetValue(v)
+		result.Children = items
+		return nil
+	})
+}
+
+// Object creates a Parser which will match an object and set the Value to a
+// map[string]interface{}. The results of the values are kept as the Children
+// named by their keys.
+func (c Containers) Object() pars.Parser {
+	k := c.Key
+	if c.Duplicates == Reject {
+		k = positioned(k)
+	}
+	member := pars.Seq(k, c.Space, ':', c.Space, c.Value, c.Space)
+	return c.container('{', '}', member).Map(func(result *pars.Result) error {
+		members := elements(result)
+		v := make(map[string]interface{}, len(members))
+		n := 0
+		for i := range members {
+			var name string
+			switch k := members[i].Children[0].Value.(type) {
+			case string:
+				name = k
+			case key:
+				name = k.name
+				if _, ok := v[name]; ok {
+					return pars.NewError(fmt.Sprintf("duplicate key %q", name), k.pos)
+				}
+			}
+			member := members[i].Children[4]
+			member.Name = name
+
+			if _, ok := v[name]; ok {
+				if c.Duplicates == KeepFirst {
+					continue
+				}
+				for j := range members[:n] {
+					if members[j].Name == name {
+						members[j] = member
+						break
+					}
+				}
+			} else {
+				members[n] = member
+				n++
+			}
+			v[name] = member.Value
+		}
+		result.SetValue(v)
+		result.Children = members[:n]
+		return nil
+	})
+}
@@ -0,0 +1,57 @@
+package json
+
+import (
+	"io"
+
+	"github.com/go-pars/pars"
+)
+
+// Decoder reads and decodes a stream of JSON values from an io.Reader. The
+// values may be separated by whitespace as with encoding/json.
+type Decoder struct {
+	state  *pars.State
+	config Config
+}
+
+// NewDecoder creates a new Decoder reading from the given io.Reader with the
+// Default configuration.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{state: pars.NewState(r), config: Default}
+}
+
+// UseNumber causes the Decoder to decode numbers into an interface{} as an
+// encoding/json.Number instead of a float64.
+func (d *Decoder) UseNumber() { d.config.UseNumber = true }
+
+// DuplicateKeys sets the policy for objects with duplicate keys.
+func (d *Decoder) DuplicateKeys(policy DuplicatePolicy) { d.config.Duplicates = policy }
+
+// Decode reads the next JSON value and stores it in the value pointed to by
+// v. It returns io.EOF if there are no more values. The value is stored with
+// pars.Decode from the result of the Value parser of the configuration: an
+// interface{} receives the same value as with encoding/json, arrays are
+// decoded into slices and arrays by their elements, objects are decoded into
+// maps by their keys and into structs by the keys given in `pars` tags, and
+// numbers are converted from their bytes to the numeric type of the
+// destination. A null sets a pointer, interface, map, or slice to nil.
+func (d *Decoder) Decode(v interface{}) error {
+	Whitespace(d.state, nil)
+	if pars.End(d.state, nil) == nil {
+		return io.EOF
+	}
+
+	// The results are only needed until they are decoded.
+	arena := arenas.Get().(*pars.Arena)
+	defer func() {
+		d.state.SetArena(nil)
+		arena.Reset()
+		arenas.Put(arena)
+	}()
+	d.state.SetArena(arena)
+
+	result, err := d.config.Value().Parse(d.state)
+	if err != nil {
+		return err
+	}
+	return pars.Decode(&result, v)
+}
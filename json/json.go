@@ -1,52 +1,394 @@
-package examples
+// Package json implements a JSON parser conforming to RFC 8259. The parsers
+// produce the same values as encoding/json when decoding into an interface{}:
+// nil, bool, float64, string, []interface{}, and map[string]interface{}.
+//
+// The package replaces the JSON example which was previously declared as
+// package examples in this directory, so it is imported as pars/json. The
+// arrays and objects are built from the pars combinators, so the parsers
+// benefit from a pars.Arena set on the state and the resulting trees can be
+// decoded into Go values with pars.Decode.
+package json
 
 import (
+	stdjson "encoding/json"
+	"fmt"
 	"io"
+	"strconv"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/go-pars/pars"
+	"github.com/go-pars/pars/internal/jsonsyntax"
 )
 
-func arrayMap(result *pars.Result) error {
-	children := result.Children[1].Children
-	v := make([]interface{}, len(children))
-	for i, child := range children {
-		v[i] = child.Value
+// DuplicatePolicy determines how an object with duplicate keys is decoded.
+type DuplicatePolicy int
+
+const (
+	// DuplicateLast keeps the value of the last duplicate key, which is the
+	// behavior of encoding/json.
+	DuplicateLast DuplicatePolicy = iota
+
+	// DuplicateFirst keeps the value of the first duplicate key.
+	DuplicateFirst
+
+	// DuplicateError rejects objects with duplicate keys.
+	DuplicateError
+)
+
+// DefaultMaxDepth is the nesting limit of arrays and objects used when the
+// MaxDepth of a Config is zero, which is the same as that of encoding/json.
+const DefaultMaxDepth = 10000
+
+// Config configures how the parsers decode values.
+type Config struct {
+	// UseNumber decodes numbers as an encoding/json.Number instead of a
+	// float64.
+	UseNumber bool
+
+	// Duplicates is the policy for objects with duplicate keys.
+	Duplicates DuplicatePolicy
+
+	// MaxDepth limits the nesting of arrays and objects. Zero means
+	// DefaultMaxDepth and a negative value means there is no limit, in which
+	// case deeply nested input may exhaust the stack.
+	MaxDepth int
+}
+
+// Default is the configuration of the package level parsers and functions,
+// which decode values as encoding/json does.
+var Default = Config{}
+
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func skipWhitespace(state *pars.State) {
+	for {
+		c, err := pars.Next(state)
+		if err != nil || !isWhitespace(c) {
+			return
+		}
+		state.Advance()
+	}
+}
+
+// literal creates a Parser which will match the given keyword and set the
+// given value.
+func literal(word string, value interface{}) pars.Parser {
+	what := fmt.Sprintf("expected `%s`", word)
+	return func(state *pars.State, result *pars.Result) error {
+		if err := state.Request(len(word)); err != nil || string(state.Buffer()) != word {
+			return pars.NewError(what, state.Position())
+		}
+		state.Advance()
+		result.SetValue(value)
+		return nil
+	}
+}
+
+// scanDigits matches one or more digits.
+func scanDigits(state *pars.State) error {
+	c, err := pars.Next(state)
+	if err != nil {
+		return pars.NewError(jsonsyntax.UnexpectedEOF, state.Position())
+	}
+	if !jsonsyntax.IsDigit(c) {
+		return pars.NewError("expected a digit", state.Position())
+	}
+	for err == nil && jsonsyntax.IsDigit(c) {
+		state.Advance()
+		c, err = pars.Next(state)
+	}
+	return nil
+}
+
+// scanNumber matches a number and returns its bytes. A number is defined to
+// be as follows in EBNF:
+//
+//   digit    = `0` | `1` | `2` | `3` | `4` | `5` | `6` | `7` | `8` | `9`
+//   integer  = `0` | ( digit - `0` ), { digit }
+//   fraction = `.`, digit, { digit }
+//   exponent = ( `e` | `E` ), [ `+` | `-` ], digit, { digit }
+//   number   = [ `-` ], integer, [ fraction ], [ exponent ]
+func scanNumber(state *pars.State) ([]byte, error) {
+	state.Push()
+	fail := func(err error) ([]byte, error) {
+		state.Pop()
+		return nil, err
+	}
+
+	if c, err := pars.Next(state); err == nil && c == '-' {
+		state.Advance()
+	}
+	c, err := pars.Next(state)
+	switch {
+	case err != nil:
+		return fail(pars.NewError(jsonsyntax.UnexpectedEOF, state.Position()))
+	case c == '0':
+		state.Advance()
+	case jsonsyntax.IsDigit(c):
+		scanDigits(state)
+	default:
+		return fail(pars.NewError("expected a digit", state.Position()))
+	}
+
+	if c, err := pars.Next(state); err == nil && c == '.' {
+		state.Advance()
+		if err := scanDigits(state); err != nil {
+			return fail(err)
+		}
+	}
+
+	if c, err := pars.Next(state); err == nil && (c == 'e' || c == 'E') {
+		state.Advance()
+		if c, err := pars.Next(state); err == nil && (c == '+' || c == '-') {
+			state.Advance()
+		}
+		if err := scanDigits(state); err != nil {
+			return fail(err)
+		}
+	}
+
+	return pars.Trail(state)
+}
+
+// scanEscape matches an escape sequence following a backslash and appends
+// the decoded rune to the given bytes. A lone surrogate is decoded as the
+// replacement character as with encoding/json.
+func scanEscape(state *pars.State, p []byte) ([]byte, error) {
+	c, err := pars.Next(state)
+	if err != nil {
+		return nil, pars.NewError(jsonsyntax.UnexpectedEOF, state.Position())
+	}
+	switch c {
+	case '"', '\\', '/':
+		p = append(p, c)
+	case 'b':
+		p = append(p, '\b')
+	case 'f':
+		p = append(p, '\f')
+	case 'n':
+		p = append(p, '\n')
+	case 'r':
+		p = append(p, '\r')
+	case 't':
+		p = append(p, '\t')
+	case 'u':
+		state.Advance()
+		r, err := jsonsyntax.ScanUnicodeEscape(state)
+		if err != nil {
+			return nil, err
+		}
+		return append(p, string(r)...), nil
+	default:
+		return nil, pars.NewError("invalid escape sequence", state.Position())
+	}
+	state.Advance()
+	return p, nil
+}
+
+// scanString matches a string and returns its decoded contents. Invalid UTF-8
+// sequences are replaced by the replacement character as with encoding/json.
+func scanString(state *pars.State) (string, error) {
+	state.Push()
+	fail := func(err error) (string, error) {
+		state.Pop()
+		return "", err
+	}
+
+	if err := jsonsyntax.Expect(state, '"', "a string"); err != nil {
+		return fail(err)
+	}
+
+	p := []byte{}
+	for {
+		c, err := pars.Next(state)
+		switch {
+		case err != nil:
+			return fail(pars.NewError(jsonsyntax.UnexpectedEOF, state.Position()))
+		case c == '"':
+			state.Advance()
+			state.Drop()
+			return string(p), nil
+		case c == '\\':
+			state.Advance()
+			if p, err = scanEscape(state, p); err != nil {
+				return fail(err)
+			}
+		case c < 0x20:
+			return fail(pars.NewError("invalid control character in string", state.Position()))
+		case c < utf8.RuneSelf:
+			p = append(p, c)
+			state.Advance()
+		default:
+			state.Request(utf8.UTFMax)
+			r, size := utf8.DecodeRune(state.Buffer())
+			state.Request(size)
+			state.Advance()
+			p = append(p, string(r)...)
+		}
+	}
+}
+
+// number matches a number and sets the Token to its bytes and the Value to
+// its float64 or encoding/json.Number value.
+func (g *grammar) number(state *pars.State, result *pars.Result) error {
+	pos := state.Position()
+	p, err := scanNumber(state)
+	if err != nil {
+		return err
+	}
+	if g.UseNumber {
+		result.SetToken(p)
+		result.Value = stdjson.Number(p)
+		return nil
 	}
-	result.SetValue(v)
+	f, err := strconv.ParseFloat(string(p), 64)
+	if err != nil {
+		return pars.NewError(fmt.Sprintf("number %s is out of range", p), pos)
+	}
+	result.SetToken(p)
+	result.Value = f
 	return nil
 }
 
-func objectMap(result *pars.Result) error {
-	children := result.Children[1].Children
-	v := make(map[string]interface{})
-	for _, child := range children {
-		name, value := child.Children[0].Value.(string), child.Children[2].Value
-		v[name] = value
+func str(state *pars.State, result *pars.Result) error {
+	s, err := scanString(state)
+	if err != nil {
+		return err
 	}
-	result.SetValue(v)
+	result.SetValue(s)
 	return nil
 }
 
-// JSON parser parts.
-var (
-	Value  pars.Parser
-	Null   = pars.String("null").Bind(nil)
-	True   = pars.String("true").Bind(true)
-	False  = pars.String("false").Bind(false)
-	Number = pars.Number
-	String = pars.Quoted('"').Map(pars.ToString)
-	Array  = pars.Seq('[', pars.Delim(&Value, ','), ']').Map(arrayMap)
-	prop   = pars.Seq(String, ':', &Value)
-	Object = pars.Seq('{', pars.Delim(prop, ','), '}').Map(objectMap)
-)
+// grammar is a set of JSON parsers configured with decoding options.
+type grammar struct {
+	Config
+
+	value    pars.Parser
+	document pars.Parser
+	exact    pars.Parser
+	array    pars.Parser
+	object   pars.Parser
+}
+
+// dispatch matches a value by the parser for its first byte, so an error is
+// reported where the value is malformed rather than at its start.
+func (g *grammar) dispatch(state *pars.State, result *pars.Result) error {
+	c, err := pars.Next(state)
+	if err != nil {
+		return pars.NewError(jsonsyntax.UnexpectedEOF, state.Position())
+	}
+	switch {
+	case c == '{':
+		return g.object(state, result)
+	case c == '[':
+		return g.array(state, result)
+	case c == '"':
+		return str(state, result)
+	case c == '-' || jsonsyntax.IsDigit(c):
+		return g.number(state, result)
+	case c == 't':
+		return True(state, result)
+	case c == 'f':
+		return False(state, result)
+	case c == 'n':
+		return Null(state, result)
+	default:
+		return pars.NewError("expected a JSON value", state.Position())
+	}
+}
 
-func init() {
-	Value = pars.Any(Null, True, False, String, Number, Array, Object)
+func newGrammar(c Config) *grammar {
+	g := &grammar{Config: c}
+	g.value = g.dispatch
+
+	ws := pars.Parser(Whitespace)
+	containers := jsonsyntax.Containers{
+		Value:      &g.value,
+		Key:        str,
+		Space:      ws,
+		Duplicates: jsonsyntax.Duplicates(c.Duplicates), // declared in the same order
+		MaxDepth:   c.MaxDepth,
+	}
+	if c.MaxDepth == 0 {
+		containers.MaxDepth = DefaultMaxDepth
+	}
+	g.array = containers.Array()
+	g.object = containers.Object()
+
+	g.document = pars.Seq(ws, &g.value, ws).Child(1)
+	g.exact = pars.Seq(g.document, pars.End).Child(0)
+	return g
+}
+
+var grammars sync.Map
+
+// grammar returns the parsers for the configuration, which are created once
+// for each configuration in use.
+func (c Config) grammar() *grammar {
+	if g, ok := grammars.Load(c); ok {
+		return g.(*grammar)
+	}
+	g, _ := grammars.LoadOrStore(c, newGrammar(c))
+	return g.(*grammar)
 }
 
-// Unmarshal json string into an interface{}.
-func Unmarshal(r io.Reader) (interface{}, error) {
-	state := pars.NewState(pars.NewReader(r))
-	result, err := pars.Exact(Value).Parse(state)
+// Value returns a Parser which will match any value without the surrounding
+// whitespace.
+func (c Config) Value() pars.Parser { return c.grammar().value }
+
+// Document returns a Parser which will match a value surrounded by optional
+// whitespace.
+func (c Config) Document() pars.Parser { return c.grammar().document }
+
+// The intermediate results are discarded once mapped to values, so their
+// memory is reused across calls to Unmarshal.
+var arenas = sync.Pool{New: func() interface{} { return pars.NewArena() }}
+
+// Unmarshal parses the JSON document read from the given io.Reader into an
+// interface{}. The document must consist of exactly one value.
+func (c Config) Unmarshal(r io.Reader) (interface{}, error) {
+	arena := arenas.Get().(*pars.Arena)
+	defer func() {
+		arena.Reset()
+		arenas.Put(arena)
+	}()
+	state := pars.NewState(r)
+	state.SetArena(arena)
+	result, err := c.grammar().exact.Parse(state)
 	return result.Value, err
 }
+
+// Whitespace will match any number of JSON whitespace characters, which are
+// the space, horizontal tab, line feed, and carriage return.
+func Whitespace(state *pars.State, result *pars.Result) error {
+	skipWhitespace(state)
+	return nil
+}
+
+var std = Default.grammar()
+
+// JSON parser parts with the Default configuration. The values are the same
+// as encoding/json produces when decoding into an interface{}. Arrays and
+// objects also keep the results of their elements as the Children, with the
+// members of an object named by their keys. Numbers also keep their bytes as
+// the Token. Value matches any value without the surrounding whitespace,
+// which Document allows.
+var (
+	Value    = std.value
+	Document = std.document
+	Null     = literal("null", nil)
+	True     = literal("true", true)
+	False    = literal("false", false)
+	Number   = pars.Parser(std.number)
+	String   = pars.Parser(str)
+	Array    = std.array
+	Object   = std.object
+)
+
+// Unmarshal parses the JSON document read from the given io.Reader into an
+// interface{} with the Default configuration. The document must consist of
+// exactly one value.
+func Unmarshal(r io.Reader) (interface{}, error) { return Default.Unmarshal(r) }
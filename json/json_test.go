@@ -1,6 +1,12 @@
-package examples
+package json
 
 import (
+	"bytes"
+	stdjson "encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -20,7 +26,14 @@ var unmarshalTestCases = []struct {
 	{`true`, true},
 	{`false`, false},
 	{`42`, 42.0},
+	{`-0.5e-1`, -0.05},
 	{`"Hello, world!"`, "Hello, world!"},
+	{`"\"\\\/\b\f\n\r\t"`, "\"\\/\b\f\n\r\t"},
+	{`"é😀"`, "é😀"},
+	{`"\ud800"`, "�"},
+	{"\"\xff\"", "�"},
+	{` [] `, []interface{}{}},
+	{"\t{}\r\n", map[string]interface{}{}},
 	{
 		`[true, null, false, -1.23e+4]`,
 		[]interface{}{true, nil, false, -1.23e+4},
@@ -29,6 +42,7 @@ var unmarshalTestCases = []struct {
 		`{"true":true, "false":false, "null": null, "number": 404}`,
 		map[string]interface{}{"true": true, "false": false, "null": nil, "number": float64(404)},
 	},
+	{`{"a":1,"a":2}`, map[string]interface{}{"a": 2.0}},
 }
 
 func TestUnmarshal(t *testing.T) {
@@ -36,7 +50,7 @@ func TestUnmarshal(t *testing.T) {
 		out, err := Unmarshal(strings.NewReader(tt.in))
 		if err != nil {
 			t.Errorf("Unmarshal(%q): %v", tt.in, err)
-			return
+			continue
 		}
 		if !same(out, tt.out) {
 			t.Errorf("Unmarshal(%q)= %v, wanted %v", tt.in, out, tt.out)
@@ -44,16 +58,284 @@ func TestUnmarshal(t *testing.T) {
 	}
 }
 
+var unmarshalErrorTestCases = []struct {
+	in  string
+	pos pars.Position
+}{
+	{`+1`, pars.Position{Line: 0, Byte: 0}},
+	{`01`, pars.Position{Line: 0, Byte: 1}},
+	{`1.`, pars.Position{Line: 0, Byte: 2}},
+	{`.1`, pars.Position{Line: 0, Byte: 0}},
+	{`1e`, pars.Position{Line: 0, Byte: 2}},
+	{`-`, pars.Position{Line: 0, Byte: 1}},
+	{`1e400`, pars.Position{Line: 0, Byte: 0}},
+	{`[1,]`, pars.Position{Line: 0, Byte: 2}},
+	{"[\n  1\n  2]", pars.Position{Line: 2, Byte: 2}},
+	{`{"a" 1}`, pars.Position{Line: 0, Byte: 5}},
+	{`"\x"`, pars.Position{Line: 0, Byte: 2}},
+	{"\"\t\"", pars.Position{Line: 0, Byte: 1}},
+	{`"abc`, pars.Position{Line: 0, Byte: 4}},
+	{`nul`, pars.Position{Line: 0, Byte: 0}},
+	{"\f1", pars.Position{Line: 0, Byte: 0}},
+}
+
+func TestUnmarshalError(t *testing.T) {
+	for _, tt := range unmarshalErrorTestCases {
+		_, err := Unmarshal(strings.NewReader(tt.in))
+		var pe pars.Error
+		if !errors.As(err, &pe) {
+			t.Errorf("Unmarshal(%q) = %v, want error at %v", tt.in, err, tt.pos)
+			continue
+		}
+		if pe.Position() != tt.pos {
+			t.Errorf("Unmarshal(%q) = %v, want error at %v", tt.in, err, tt.pos)
+		}
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	in := `{"a": 1, "b": 2, "a": 3}`
+	for _, tt := range []struct {
+		policy DuplicatePolicy
+		out    interface{}
+	}{
+		{DuplicateLast, map[string]interface{}{"a": 3.0, "b": 2.0}},
+		{DuplicateFirst, map[string]interface{}{"a": 1.0, "b": 2.0}},
+	} {
+		d := NewDecoder(strings.NewReader(in + in))
+		d.DuplicateKeys(tt.policy)
+		var out interface{}
+		if err := d.Decode(&out); err != nil || !same(out, tt.out) {
+			t.Errorf("policy %d: Decode = %v, %v, want %v", tt.policy, out, err, tt.out)
+		}
+
+		// The members of the object are decoded into maps by their keys.
+		var m map[string]float64
+		if err := d.Decode(&m); err != nil || !same(m["a"], tt.out.(map[string]interface{})["a"]) || len(m) != 2 {
+			t.Errorf("policy %d: Decode = %v, %v, want %v", tt.policy, m, err, tt.out)
+		}
+	}
+
+	d := NewDecoder(strings.NewReader(in))
+	d.DuplicateKeys(DuplicateError)
+	var out interface{}
+	err := d.Decode(&out)
+	var pe pars.Error
+	if !errors.As(err, &pe) || pe.Position() != (pars.Position{Line: 0, Byte: 17}) {
+		t.Errorf("DuplicateError: Decode = %v, want error at byte 17", err)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	d := NewDecoder(strings.NewReader(` 1 "two" [3]
+{"four": 4} `))
+	d.UseNumber()
+	e := []interface{}{
+		stdjson.Number("1"), "two",
+		[]interface{}{stdjson.Number("3")},
+		map[string]interface{}{"four": stdjson.Number("4")},
+	}
+	for _, want := range e {
+		var out interface{}
+		if err := d.Decode(&out); err != nil || !same(out, want) {
+			t.Errorf("Decode = %#v, %v, want %#v", out, err, want)
+		}
+	}
+	var out interface{}
+	if err := d.Decode(&out); err != io.EOF {
+		t.Errorf("Decode = %v, want %v", err, io.EOF)
+	}
+}
+
+type decodeTarget struct {
+	Name     string            `pars:"name,optional"`
+	ID       int               `pars:"id"`
+	Ratio    float32           `pars:"ratio,optional"`
+	Flags    []bool            `pars:"flags,optional"`
+	Pair     [2]uint8          `pars:"pair,optional"`
+	Counts   map[int]int       `pars:"counts,optional"`
+	Next     *decodeTarget     `pars:"next,optional"`
+	Any      interface{}       `pars:"any,optional"`
+	Labels   map[string]string `pars:"labels,optional"`
+	Inner    string            `pars:"inner.name,optional"`
+	Skipped  string            `pars:"-"`
+	Untagged string
+}
+
+func TestDecodeStruct(t *testing.T) {
+	in := `{
+  "name": "root", "id": 1, "ratio": 0.5,
+  "flags": [true, false], "pair": [7, 8], "counts": {"1": 2},
+  "next": {"id": 2, "next": null}, "any": [1, {"x": null}],
+  "labels": {"k": "v"}, "inner": {"name": "nested"},
+  "Skipped": "no", "Untagged": "no", "unknown": 3
+}`
+	var out decodeTarget
+	if err := NewDecoder(strings.NewReader(in)).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	e := decodeTarget{
+		Name:   "root",
+		ID:     1,
+		Ratio:  0.5,
+		Flags:  []bool{true, false},
+		Pair:   [2]uint8{7, 8},
+		Counts: map[int]int{1: 2},
+		Next:   &decodeTarget{ID: 2},
+		Any:    []interface{}{1.0, map[string]interface{}{"x": nil}},
+		Labels: map[string]string{"k": "v"},
+		Inner:  "nested",
+	}
+	if !same(out, e) {
+		t.Errorf("Decode = %+v, want %+v", out, e)
+	}
+
+	for _, tt := range []struct {
+		in   string
+		path string
+	}{
+		{`{}`, "json.decodeTarget.ID"},
+		{`{"id": 1.5}`, "json.decodeTarget.ID"},
+		{`{"id": 1, "pair": [7, 256]}`, "json.decodeTarget.Pair[1]"},
+		{`{"id": 1, "flags": ["no"]}`, "json.decodeTarget.Flags[0]"},
+		{`{"id": 1, "next": {"id": 2, "counts": {"x": 1}}}`, `json.decodeTarget.Next.Counts["x"]`},
+	} {
+		var out decodeTarget
+		err := NewDecoder(strings.NewReader(tt.in)).Decode(&out)
+		var de pars.DecodeError
+		if !errors.As(err, &de) || de.Path() != tt.path {
+			t.Errorf("Decode(%q) = %v, want error at %s", tt.in, err, tt.path)
+		}
+	}
+}
+
+func TestConfig(t *testing.T) {
+	in := `[1.5, {"a": 2, "a": 3}]`
+	out, err := Config{UseNumber: true, Duplicates: DuplicateFirst}.Unmarshal(strings.NewReader(in))
+	e := []interface{}{stdjson.Number("1.5"), map[string]interface{}{"a": stdjson.Number("2")}}
+	if err != nil || !same(out, e) {
+		t.Errorf("Unmarshal(%q) = %#v, %v, want %#v", in, out, err, e)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	for _, tt := range []struct {
+		c   Config
+		in  string
+		pos pars.Position
+	}{
+		{Default, strings.Repeat("[", 2000000), pars.Position{Line: 0, Byte: DefaultMaxDepth}},
+		{Default, strings.Repeat(`{"a":`, DefaultMaxDepth+1), pars.Position{Line: 0, Byte: 5 * DefaultMaxDepth}},
+		{Config{MaxDepth: 2}, `[{"a": [1]}]`, pars.Position{Line: 0, Byte: 7}},
+	} {
+		_, err := tt.c.Unmarshal(strings.NewReader(tt.in))
+		var pe pars.Error
+		if !errors.As(err, &pe) || pe.Position() != tt.pos {
+			t.Errorf("Unmarshal(%.16q...) = %.80v, want error at %v", tt.in, err, tt.pos)
+		}
+	}
+
+	for _, tt := range []struct {
+		c Config
+		n int
+	}{
+		{Default, DefaultMaxDepth},
+		{Config{MaxDepth: -1}, 2 * DefaultMaxDepth},
+	} {
+		in := strings.Repeat("[", tt.n) + strings.Repeat("]", tt.n)
+		if _, err := tt.c.Unmarshal(strings.NewReader(in)); err != nil {
+			t.Errorf("Unmarshal(%d nested arrays): %.80v", tt.n, err)
+		}
+	}
+}
+
+// TestJSONTestSuite runs the cases in testdata/JSONTestSuite. The cases are
+// named after those of https://github.com/nst/JSONTestSuite: y_ cases must be
+// accepted with the same value as encoding/json, n_ cases must be rejected,
+// and i_ cases may be either as long as the parser does not panic.
+func TestJSONTestSuite(t *testing.T) {
+	names, err := filepath.Glob(filepath.Join("testdata", "JSONTestSuite", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) == 0 {
+		t.Fatal("no test cases found")
+	}
+	for _, name := range names {
+		p, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		base := filepath.Base(name)
+		out, err := Unmarshal(bytes.NewReader(p))
+		switch base[0] {
+		case 'y':
+			if err != nil {
+				t.Errorf("%s: %v", base, err)
+				continue
+			}
+			var e interface{}
+			if err := stdjson.Unmarshal(p, &e); err != nil {
+				t.Fatalf("%s: encoding/json: %v", base, err)
+			}
+			if !same(out, e) {
+				t.Errorf("%s: Unmarshal = %#v, want %#v", base, out, e)
+			}
+		case 'n':
+			if err == nil {
+				t.Errorf("%s: Unmarshal = %#v, want error", base, out)
+			}
+		}
+	}
+}
+
 func BenchmarkJSON(b *testing.B) {
 	b.Run("complex", func(b *testing.B) {
 		s := pars.NewState(strings.NewReader(benchmarkString))
 		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
 			s.Push()
-			Value(s, pars.Void)
+			Value(s, &pars.Result{})
 			s.Pop()
 		}
 	})
+
+	// Parse the example from memory and from a reader.
+	p := []byte(benchmarkString)
+
+	b.Run("bytes", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(p)))
+		for i := 0; i < b.N; i++ {
+			if _, err := Value.Parse(pars.FromBytes(p)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("arena", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(p)))
+		arena := pars.NewArena()
+		for i := 0; i < b.N; i++ {
+			state := pars.FromBytes(p)
+			state.SetArena(arena)
+			if _, err := Value.Parse(state); err != nil {
+				b.Fatal(err)
+			}
+			arena.Reset()
+		}
+	})
+
+	b.Run("reader", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(p)))
+		for i := 0; i < b.N; i++ {
+			if _, err := Value.Parse(pars.NewState(bytes.NewReader(p))); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
 }
 
 // This string was taken from http://json.org/example.html
@@ -0,0 +1,519 @@
+// Package json5 implements parsers for the JSON5 and JSONC extensions of
+// JSON. JSONC is JSON with comments and trailing commas, as used by many
+// configuration files. JSON5 additionally allows unquoted identifiers as
+// object keys, single-quoted and multi-line strings, hexadecimal numbers,
+// leading and trailing decimal points, explicit plus signs, Infinity, and
+// NaN. The parsers produce the same values as encoding/json produces when
+// decoding into an interface{}: nil, bool, float64, string, []interface{},
+// and map[string]interface{}. Errors are pars.Error values which report the
+// line and column of the offending input.
+package json5
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-pars/pars"
+	"github.com/go-pars/pars/internal/jsonsyntax"
+	"github.com/go-pars/pars/json"
+)
+
+// grammar is a set of parsers for one of the dialects.
+type grammar struct {
+	// json5 enables the JSON5 syntax in addition to the comments and trailing
+	// commas accepted by both dialects.
+	json5 bool
+
+	value    pars.Parser
+	document pars.Parser
+	exact    pars.Parser
+	array    pars.Parser
+	object   pars.Parser
+}
+
+// peekRune returns the next rune and its size in bytes, requesting the bytes
+// of the rune so that a call to Advance will consume it.
+func peekRune(state *pars.State) (rune, int, error) {
+	if err := state.Request(1); err != nil {
+		return 0, 0, err
+	}
+	if c := state.Buffer()[0]; c < utf8.RuneSelf {
+		return rune(c), 1, nil
+	}
+	state.Request(utf8.UTFMax)
+	r, size := utf8.DecodeRune(state.Buffer())
+	state.Request(size)
+	return r, size, nil
+}
+
+func isLineTerminator(r rune) bool {
+	return r == '\n' || r == '\r' || r == '\u2028' || r == '\u2029'
+}
+
+// isSpace tests if the rune is whitespace in the dialect.
+func (g *grammar) isSpace(r rune) bool {
+	switch r {
+	case ' ', '\t', '\n', '\r':
+		return true
+	case '\v', '\f', '\u00a0', '\u2028', '\u2029', '\ufeff':
+		return g.json5
+	default:
+		return g.json5 && unicode.Is(unicode.Zs, r)
+	}
+}
+
+// skipComment skips a comment if the state is at one. A line comment ends
+// before the line terminator, which is then skipped as whitespace.
+func skipComment(state *pars.State) (bool, error) {
+	if state.Request(2) != nil {
+		return false, nil
+	}
+	switch string(state.Buffer()) {
+	case "//":
+		state.Advance()
+		for {
+			r, _, err := peekRune(state)
+			if err != nil || isLineTerminator(r) {
+				return true, nil
+			}
+			state.Advance()
+		}
+	case "/*":
+		pos := state.Position()
+		state.Advance()
+		for {
+			if err := state.Request(2); err != nil {
+				return false, pars.NewError("unterminated comment", pos)
+			}
+			if string(state.Buffer()) == "*/" {
+				state.Advance()
+				return true, nil
+			}
+			pars.Skip(state, 1)
+		}
+	default:
+		return false, nil
+	}
+}
+
+// skipSpace skips any whitespace and comments.
+func (g *grammar) skipSpace(state *pars.State) error {
+	for {
+		r, _, err := peekRune(state)
+		if err != nil {
+			return nil
+		}
+		if g.isSpace(r) {
+			state.Advance()
+			continue
+		}
+		if r != '/' {
+			return nil
+		}
+		ok, err := skipComment(state)
+		if !ok {
+			return err
+		}
+	}
+}
+
+// keyword matches the given word if it is not followed by an identifier
+// character.
+func keyword(state *pars.State, word string) bool {
+	if state.Request(len(word)) != nil || string(state.Buffer()) != word {
+		return false
+	}
+	state.Push()
+	state.Advance()
+	if r, _, err := peekRune(state); err == nil && isIdentifierPart(r) {
+		state.Pop()
+		return false
+	}
+	state.Drop()
+	return true
+}
+
+func isHexDigit(c byte) bool { return jsonsyntax.HexValue(c) >= 0 }
+
+// skipDigits matches zero or more bytes satisfying the given function and
+// reports the number of bytes matched.
+func skipDigits(state *pars.State, digit func(byte) bool) int {
+	n := 0
+	for {
+		c, err := pars.Next(state)
+		if err != nil || !digit(c) {
+			return n
+		}
+		state.Advance()
+		n++
+	}
+}
+
+// scanNumber matches a JSON5 number and returns its value. A number is
+// defined to be as follows in EBNF:
+//
+//   integer  = `0` | ( digit - `0` ), { digit }
+//   decimal  = integer, [ `.`, { digit } ] | `.`, digit, { digit }
+//   exponent = ( `e` | `E` ), [ `+` | `-` ], digit, { digit }
+//   hex      = `0`, ( `x` | `X` ), hexdigit, { hexdigit }
+//   number   = [ `+` | `-` ], ( `Infinity` | `NaN` | hex | decimal, [ exponent ] )
+func scanNumber(state *pars.State) (float64, error) {
+	start := state.Position()
+	state.Push()
+	fail := func(err error) (float64, error) {
+		state.Pop()
+		return 0, err
+	}
+
+	sign := 1.0
+	if c, err := pars.Next(state); err == nil && (c == '+' || c == '-') {
+		if c == '-' {
+			sign = -1
+		}
+		state.Advance()
+	}
+
+	switch {
+	case keyword(state, "Infinity"):
+		state.Drop()
+		return math.Inf(int(sign)), nil
+	case keyword(state, "NaN"):
+		state.Drop()
+		return math.NaN(), nil
+	}
+
+	if state.Request(2) == nil {
+		if p := state.Buffer(); p[0] == '0' && (p[1] == 'x' || p[1] == 'X') {
+			state.Advance()
+			if skipDigits(state, isHexDigit) == 0 {
+				return fail(pars.NewError("expected a hexadecimal digit", state.Position()))
+			}
+			p, _ := pars.Trail(state)
+			f, err := strconv.ParseFloat(string(p)+"p0", 64)
+			if err != nil {
+				return 0, pars.NewError(fmt.Sprintf("number %s is out of range", p), start)
+			}
+			return f, nil
+		}
+	}
+
+	n := 0
+	if c, err := pars.Next(state); err == nil && c == '0' {
+		state.Advance()
+		n = 1
+		if c, err := pars.Next(state); err == nil && jsonsyntax.IsDigit(c) {
+			return fail(pars.NewError("leading zeros are not allowed", state.Position()))
+		}
+	} else {
+		n = skipDigits(state, jsonsyntax.IsDigit)
+	}
+	if c, err := pars.Next(state); err == nil && c == '.' {
+		state.Advance()
+		n += skipDigits(state, jsonsyntax.IsDigit)
+	}
+	if n == 0 {
+		if _, err := pars.Next(state); err != nil {
+			return fail(pars.NewError(jsonsyntax.UnexpectedEOF, state.Position()))
+		}
+		return fail(pars.NewError("expected a digit", state.Position()))
+	}
+
+	if c, err := pars.Next(state); err == nil && (c == 'e' || c == 'E') {
+		state.Advance()
+		if c, err := pars.Next(state); err == nil && (c == '+' || c == '-') {
+			state.Advance()
+		}
+		if skipDigits(state, jsonsyntax.IsDigit) == 0 {
+			return fail(pars.NewError("expected a digit", state.Position()))
+		}
+	}
+
+	p, _ := pars.Trail(state)
+	f, err := strconv.ParseFloat(string(p), 64)
+	if err != nil {
+		return 0, pars.NewError(fmt.Sprintf("number %s is out of range", p), start)
+	}
+	return f, nil
+}
+
+// scanEscape matches an escape sequence following a backslash and appends
+// the decoded rune to the given bytes. An escaped line terminator continues
+// the string on the next line and produces nothing.
+func scanEscape(state *pars.State, p []byte) ([]byte, error) {
+	r, _, err := peekRune(state)
+	if err != nil {
+		return nil, pars.NewError(jsonsyntax.UnexpectedEOF, state.Position())
+	}
+	switch r {
+	case 'b':
+		p = append(p, '\b')
+	case 'f':
+		p = append(p, '\f')
+	case 'n':
+		p = append(p, '\n')
+	case 'r':
+		p = append(p, '\r')
+	case 't':
+		p = append(p, '\t')
+	case 'v':
+		p = append(p, '\v')
+	case '0':
+		state.Advance()
+		if c, err := pars.Next(state); err == nil && jsonsyntax.IsDigit(c) {
+			return nil, pars.NewError("invalid escape sequence", state.Position())
+		}
+		return append(p, 0), nil
+	case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return nil, pars.NewError("invalid escape sequence", state.Position())
+	case 'x':
+		state.Advance()
+		r, err := jsonsyntax.ScanHex(state, 2)
+		if err != nil {
+			return nil, err
+		}
+		return append(p, string(r)...), nil
+	case 'u':
+		state.Advance()
+		r, err := jsonsyntax.ScanUnicodeEscape(state)
+		if err != nil {
+			return nil, err
+		}
+		return append(p, string(r)...), nil
+	case '\r':
+		state.Advance()
+		if c, err := pars.Next(state); err == nil && c == '\n' {
+			state.Advance()
+		}
+		return p, nil
+	case '\n', '\u2028', '\u2029':
+	default:
+		p = append(p, string(r)...)
+	}
+	state.Advance()
+	return p, nil
+}
+
+// scanString matches a single- or double-quoted string and returns its
+// decoded contents. Invalid UTF-8 sequences are replaced by the replacement
+// character as with encoding/json.
+func scanString(state *pars.State) (string, error) {
+	state.Push()
+	fail := func(err error) (string, error) {
+		state.Pop()
+		return "", err
+	}
+
+	quote, err := pars.Next(state)
+	switch {
+	case err != nil:
+		return fail(pars.NewError(jsonsyntax.UnexpectedEOF, state.Position()))
+	case quote != '"' && quote != '\'':
+		return fail(pars.NewError("expected a string", state.Position()))
+	}
+	state.Advance()
+
+	p := []byte{}
+	for {
+		r, _, err := peekRune(state)
+		switch {
+		case err != nil:
+			return fail(pars.NewError(jsonsyntax.UnexpectedEOF, state.Position()))
+		case r == rune(quote):
+			state.Advance()
+			state.Drop()
+			return string(p), nil
+		case r == '\\':
+			state.Advance()
+			if p, err = scanEscape(state, p); err != nil {
+				return fail(err)
+			}
+		case r == '\n' || r == '\r':
+			return fail(pars.NewError("unescaped line terminator in string", state.Position()))
+		default:
+			state.Advance()
+			p = append(p, string(r)...)
+		}
+	}
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '$' || r == '_' || unicode.IsLetter(r) || unicode.Is(unicode.Nl, r)
+}
+
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || r == '\u200c' || r == '\u200d' ||
+		unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc)
+}
+
+// scanIdentifier matches an ECMAScript identifier name used as an object key.
+// The characters of the identifier may be given as `\u` escapes.
+func scanIdentifier(state *pars.State) (string, error) {
+	state.Push()
+	fail := func(err error) (string, error) {
+		state.Pop()
+		return "", err
+	}
+
+	p := []byte{}
+	for {
+		pos := state.Position()
+		r, _, err := peekRune(state)
+		if err != nil {
+			break
+		}
+		if r == '\\' {
+			state.Advance()
+			if err := jsonsyntax.Expect(state, 'u', "`u`"); err != nil {
+				return fail(err)
+			}
+			if r, err = jsonsyntax.ScanUnicodeEscape(state); err != nil {
+				return fail(err)
+			}
+		} else {
+			if !isIdentifierPart(r) {
+				break
+			}
+			state.Advance()
+		}
+		if len(p) == 0 && !isIdentifierStart(r) || len(p) > 0 && !isIdentifierPart(r) {
+			return fail(pars.NewError("invalid identifier character", pos))
+		}
+		p = append(p, string(r)...)
+	}
+
+	if len(p) == 0 {
+		if _, err := pars.Next(state); err != nil {
+			return fail(pars.NewError(jsonsyntax.UnexpectedEOF, state.Position()))
+		}
+		return fail(pars.NewError("expected a key", state.Position()))
+	}
+	state.Drop()
+	return string(p), nil
+}
+
+// space matches any whitespace and comments.
+func (g *grammar) space(state *pars.State, result *pars.Result) error {
+	return g.skipSpace(state)
+}
+
+// key matches an object key and sets the Value to its name.
+func (g *grammar) key(state *pars.State, result *pars.Result) error {
+	if !g.json5 {
+		return json.String(state, result)
+	}
+	var s string
+	var err error
+	if c, e := pars.Next(state); e == nil && (c == '"' || c == '\'') {
+		s, err = scanString(state)
+	} else {
+		s, err = scanIdentifier(state)
+	}
+	if err != nil {
+		return err
+	}
+	result.SetValue(s)
+	return nil
+}
+
+// dispatch matches a value by the parser for its first byte.
+func (g *grammar) dispatch(state *pars.State, result *pars.Result) error {
+	c, err := pars.Next(state)
+	if err != nil {
+		return pars.NewError(jsonsyntax.UnexpectedEOF, state.Position())
+	}
+	switch {
+	case c == '{':
+		return g.object(state, result)
+	case c == '[':
+		return g.array(state, result)
+	case c == 't':
+		return json.True(state, result)
+	case c == 'f':
+		return json.False(state, result)
+	case c == 'n':
+		return json.Null(state, result)
+	case !g.json5:
+		switch {
+		case c == '"':
+			return json.String(state, result)
+		case c == '-' || jsonsyntax.IsDigit(c):
+			return json.Number(state, result)
+		}
+	case c == '"' || c == '\'':
+		s, err := scanString(state)
+		if err != nil {
+			return err
+		}
+		result.SetValue(s)
+		return nil
+	case c == '+' || c == '-' || c == '.' || c == 'I' || c == 'N' || jsonsyntax.IsDigit(c):
+		f, err := scanNumber(state)
+		if err != nil {
+			return err
+		}
+		result.SetValue(f)
+		return nil
+	}
+	return pars.NewError("expected a value", state.Position())
+}
+
+// newGrammar creates the parsers of a dialect. Both dialects allow a trailing
+// comma after the last element of an array or object, and bound the nesting
+// of arrays and objects as the json package does by default.
+func newGrammar(json5 bool) *grammar {
+	g := &grammar{json5: json5}
+	g.value = g.dispatch
+
+	space := pars.Parser(g.space)
+	containers := jsonsyntax.Containers{
+		Value:         &g.value,
+		Key:           g.key,
+		Space:         space,
+		TrailingComma: true,
+		MaxDepth:      json.DefaultMaxDepth,
+	}
+	g.array = containers.Array()
+	g.object = containers.Object()
+
+	g.document = pars.Seq(space, &g.value, space).Child(1)
+	g.exact = pars.Seq(g.document, pars.End).Child(0)
+	return g
+}
+
+var (
+	json5 = newGrammar(true)
+	jsonc = newGrammar(false)
+)
+
+// JSON5 parsers. Value matches any value without the surrounding whitespace
+// and comments, which Document allows. Arrays and objects keep the results of
+// their elements as the Children as with the json package.
+var (
+	Value    = json5.value
+	Document = json5.document
+)
+
+// JSONC parsers. JSONCValue matches any value without the surrounding
+// whitespace and comments, which JSONCDocument allows.
+var (
+	JSONCValue    = jsonc.value
+	JSONCDocument = jsonc.document
+)
+
+// Unmarshal parses the JSON5 document read from the given io.Reader into an
+// interface{}. The document must consist of exactly one value.
+func Unmarshal(r io.Reader) (interface{}, error) {
+	result, err := json5.exact.Parse(pars.NewState(r))
+	return result.Value, err
+}
+
+// UnmarshalJSONC parses the JSONC document read from the given io.Reader
+// into an interface{}. The document must consist of exactly one value.
+func UnmarshalJSONC(r io.Reader) (interface{}, error) {
+	result, err := jsonc.exact.Parse(pars.NewState(r))
+	return result.Value, err
+}
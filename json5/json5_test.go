@@ -0,0 +1,137 @@
+package json5
+
+import (
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-pars/pars"
+	"github.com/go-pars/pars/json"
+)
+
+func same(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+var unmarshalTestCases = []struct {
+	in  string
+	out interface{}
+}{
+	{`null`, nil},
+	{`// comment
+	true /* trailing */`, true},
+	{`[1, 2, 3,]`, []interface{}{1.0, 2.0, 3.0}},
+	{`{a: 1, $b_2: 2, 'c': 3, "d": 4,}`, map[string]interface{}{"a": 1.0, "$b_2": 2.0, "c": 3.0, "d": 4.0}},
+	{`{ab: 1, café: 2}`, map[string]interface{}{"ab": 1.0, "café": 2.0}},
+	{`'it\'s "quoted"'`, `it's "quoted"`},
+	{"'line \\\n continued\\\r\n twice'", "line  continued twice"},
+	{`"\x41é\0\v\q"`, "Aé\x00\vq"},
+	{`"😀"`, "😀"},
+	{"'\u2028'", "\u2028"},
+	{`0x1F`, 31.0},
+	{`-0XfF`, -255.0},
+	{`+1`, 1.0},
+	{`.5`, 0.5},
+	{`5.`, 5.0},
+	{`1e3`, 1000.0},
+	{`-.5e-1`, -0.05},
+	{`Infinity`, math.Inf(1)},
+	{`-Infinity`, math.Inf(-1)},
+	{"\ufeff\u00a0[\v\f]\u2029\u3000", []interface{}{}},
+	{`{nested: {list: [{}, [], 'x',],},}`, map[string]interface{}{
+		"nested": map[string]interface{}{"list": []interface{}{map[string]interface{}{}, []interface{}{}, "x"}},
+	}},
+}
+
+func TestUnmarshal(t *testing.T) {
+	for _, tt := range unmarshalTestCases {
+		out, err := Unmarshal(strings.NewReader(tt.in))
+		if err != nil {
+			t.Errorf("Unmarshal(%q): %v", tt.in, err)
+			continue
+		}
+		if !same(out, tt.out) {
+			t.Errorf("Unmarshal(%q) = %#v, want %#v", tt.in, out, tt.out)
+		}
+	}
+
+	out, err := Unmarshal(strings.NewReader(`[NaN, -NaN]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range out.([]interface{}) {
+		if f, ok := v.(float64); !ok || !math.IsNaN(f) {
+			t.Errorf("Unmarshal(NaN) = %#v, want NaN", v)
+		}
+	}
+}
+
+var unmarshalErrorTestCases = []struct {
+	in  string
+	pos pars.Position
+}{
+	{`[1,,]`, pars.Position{Line: 0, Byte: 3}},
+	{`{a b: 1}`, pars.Position{Line: 0, Byte: 3}},
+	{`{1a: 1}`, pars.Position{Line: 0, Byte: 1}},
+	{"{\n  a: /* unterminated\n}", pars.Position{Line: 1, Byte: 5}},
+	{"'line\nbreak'", pars.Position{Line: 0, Byte: 5}},
+	{`"\1"`, pars.Position{Line: 0, Byte: 2}},
+	{`"\01"`, pars.Position{Line: 0, Byte: 3}},
+	{`"\xg0"`, pars.Position{Line: 0, Byte: 3}},
+	{`012`, pars.Position{Line: 0, Byte: 1}},
+	{`0x`, pars.Position{Line: 0, Byte: 2}},
+	{`[0x` + strings.Repeat("f", 300) + `]`, pars.Position{Line: 0, Byte: 1}},
+	{`.`, pars.Position{Line: 0, Byte: 1}},
+	{`1e`, pars.Position{Line: 0, Byte: 2}},
+	{`Infinityx`, pars.Position{Line: 0, Byte: 0}},
+	{`undefined`, pars.Position{Line: 0, Byte: 0}},
+	{"[1]\n// comment\n2", pars.Position{Line: 2, Byte: 0}},
+}
+
+func TestUnmarshalError(t *testing.T) {
+	for _, tt := range unmarshalErrorTestCases {
+		_, err := Unmarshal(strings.NewReader(tt.in))
+		var pe pars.Error
+		if !errors.As(err, &pe) || pe.Position() != tt.pos {
+			t.Errorf("Unmarshal(%q) = %v, want error at %v", tt.in, err, tt.pos)
+		}
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	in := strings.Repeat("[", 2000000)
+	for _, unmarshal := range []func(io.Reader) (interface{}, error){Unmarshal, UnmarshalJSONC} {
+		_, err := unmarshal(strings.NewReader(in))
+		var pe pars.Error
+		if !errors.As(err, &pe) || pe.Position() != (pars.Position{Line: 0, Byte: json.DefaultMaxDepth}) {
+			t.Errorf("unmarshal(%d brackets) = %v, want error at byte %d", len(in), err, json.DefaultMaxDepth)
+		}
+	}
+}
+
+func TestUnmarshalJSONC(t *testing.T) {
+	in := `// settings
+{
+  "editor.tabSize": 4, /* spaces */
+  "files.exclude": ["*.o", "*.a",],
+}`
+	e := map[string]interface{}{
+		"editor.tabSize": 4.0,
+		"files.exclude":  []interface{}{"*.o", "*.a"},
+	}
+	out, err := UnmarshalJSONC(strings.NewReader(in))
+	if err != nil || !same(out, e) {
+		t.Errorf("UnmarshalJSONC = %#v, %v, want %#v", out, err, e)
+	}
+
+	// The JSON5 extensions other than comments and trailing commas are
+	// rejected in JSONC.
+	for _, in := range []string{`{a: 1}`, `'x'`, `0x1`, `+1`, `.5`, `Infinity`, "[\v]"} {
+		if out, err := UnmarshalJSONC(strings.NewReader(in)); err == nil {
+			t.Errorf("UnmarshalJSONC(%q) = %#v, want error", in, out)
+		}
+	}
+}
@@ -0,0 +1,495 @@
+// Package lint analyses grammars built with pars for common mistakes which
+// are not detected by the parsers themselves. The analysis is based on the
+// descriptions returned by pars.Inspect so Parsers which were not created by
+// the pars package are treated as opaque.
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-pars/pars"
+)
+
+// Kind represents the kind of an Issue.
+type Kind int
+
+// Kinds of Issues.
+const (
+	// LeftRecursion is reported for a Parser which may call itself without
+	// consuming any input, which will never terminate.
+	LeftRecursion Kind = iota
+
+	// EmptyLoop is reported for a Many or Delim whose body may match without
+	// consuming any input, which will silently stop the loop.
+	EmptyLoop
+
+	// Shadowed is reported for an alternative of an Any which can never match
+	// because an earlier alternative matches a prefix of the same input.
+	Shadowed
+
+	// Unused is reported for a rule which is not reachable from the root.
+	Unused
+)
+
+var kindNames = [...]string{"LeftRecursion", "EmptyLoop", "Shadowed", "Unused"}
+
+// String returns the name of the kind.
+func (k Kind) String() string { return kindNames[k] }
+
+// Issue is a problem found in a grammar.
+type Issue struct {
+	Kind Kind
+
+	// Rule is the name of the innermost Named Parser containing the Node, or
+	// empty if the Node is not in a named rule.
+	Rule string
+
+	// Node is the description of the Parser with the problem.
+	Node *pars.Node
+
+	// Message is a human readable description of the problem.
+	Message string
+}
+
+// String returns the Issue as a human readable string.
+func (i Issue) String() string {
+	if i.Rule == "" {
+		return i.Message
+	}
+	return fmt.Sprintf("%s: %s", i.Rule, i.Message)
+}
+
+// nullablePrimitives are the primitives which may match without consuming any
+// input.
+var nullablePrimitives = map[string]bool{
+	"Epsilon": true,
+	"Head":    true,
+	"End":     true,
+	"Cut":     true,
+	"Spaces":  true,
+	"EOL":     true,
+	"Line":    true,
+	"Align":   true,
+}
+
+// succeedingPrimitives are the primitives which always match.
+var succeedingPrimitives = map[string]bool{
+	"Epsilon": true,
+	"Cut":     true,
+	"Spaces":  true,
+	"Line":    true,
+	"Align":   true,
+}
+
+type analyzer struct {
+	nodes    []*pars.Node
+	rules    map[*pars.Node]string
+	nullable map[*pars.Node]bool
+	issues   []Issue
+}
+
+func (a *analyzer) report(kind Kind, n *pars.Node, format string, args ...interface{}) {
+	a.issues = append(a.issues, Issue{kind, a.rules[n], n, fmt.Sprintf(format, args...)})
+}
+
+// collect records every Node reachable from the given Node along with the
+// innermost rule it was first reached in.
+func (a *analyzer) collect(n *pars.Node, rule string) {
+	if _, ok := a.rules[n]; ok {
+		return
+	}
+	if n.Kind == pars.KindNamed {
+		rule = n.Name
+	}
+	a.rules[n] = rule
+	a.nodes = append(a.nodes, n)
+	for _, child := range n.Children() {
+		a.collect(child, rule)
+	}
+}
+
+func (a *analyzer) isNullable(n *pars.Node) bool {
+	children := n.Children()
+	switch n.Kind {
+	case pars.KindPrimitive:
+		return nullablePrimitives[n.Name]
+	case pars.KindBytes, pars.KindString:
+		return len(n.Literal) == 0
+	case pars.KindRunes:
+		return len(n.Runes) == 0
+	case pars.KindMaybe, pars.KindMany, pars.KindDry, pars.KindNot, pars.KindUntil:
+		return true
+	case pars.KindSeq, pars.KindLengthPrefixed:
+		for _, child := range children {
+			if !a.nullable[child] {
+				return false
+			}
+		}
+		return true
+	case pars.KindAny:
+		for _, child := range children {
+			if a.nullable[child] {
+				return true
+			}
+		}
+		return false
+	case pars.KindMap, pars.KindRef, pars.KindNamed, pars.KindCapture, pars.KindWrap, pars.KindDelim:
+		return len(children) > 0 && a.nullable[children[0]]
+	default:
+		return false
+	}
+}
+
+// computeNullable finds the Nodes which may match without consuming input.
+// Recursive grammars are handled by iterating until nothing changes.
+func (a *analyzer) computeNullable() {
+	for changed := true; changed; {
+		changed = false
+		for _, n := range a.nodes {
+			if !a.nullable[n] && a.isNullable(n) {
+				a.nullable[n] = true
+				changed = true
+			}
+		}
+	}
+}
+
+// leftmost returns the children which may be called by the Node before any
+// input is consumed.
+func (a *analyzer) leftmost(n *pars.Node) []*pars.Node {
+	children := n.Children()
+	switch n.Kind {
+	case pars.KindSeq, pars.KindLengthPrefixed:
+		for i, child := range children {
+			if !a.nullable[child] {
+				return children[:i+1]
+			}
+		}
+		return children
+	case pars.KindDelim:
+		if a.nullable[children[0]] {
+			return children
+		}
+		return children[:1]
+	default:
+		return children
+	}
+}
+
+func cycleString(cycle []*pars.Node) string {
+	names := []string{}
+	for _, n := range cycle {
+		if n.Kind == pars.KindNamed {
+			names = append(names, n.Name)
+		}
+	}
+	if len(names) == 0 {
+		for _, n := range cycle {
+			names = append(names, n.String())
+		}
+	}
+	return strings.Join(append(names, names[0]), " -> ")
+}
+
+func (a *analyzer) checkLeftRecursion(root *pars.Node) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[*pars.Node]int)
+	stack := []*pars.Node{}
+
+	var visit func(n *pars.Node)
+	visit = func(n *pars.Node) {
+		state[n] = visiting
+		stack = append(stack, n)
+		for _, child := range a.leftmost(n) {
+			switch state[child] {
+			case unvisited:
+				visit(child)
+			case visiting:
+				i := len(stack) - 1
+				for stack[i] != child {
+					i--
+				}
+				a.report(LeftRecursion, child, "left recursion: %s", cycleString(stack[i:]))
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[n] = visited
+	}
+
+	visit(root)
+	for _, n := range a.nodes {
+		if state[n] == unvisited {
+			visit(n)
+		}
+	}
+}
+
+func (a *analyzer) checkEmptyLoops() {
+	for _, n := range a.nodes {
+		children := n.Children()
+		switch n.Kind {
+		case pars.KindMany:
+			if a.nullable[children[0]] {
+				a.report(EmptyLoop, n, "body of Many may match empty input: %s", children[0])
+			}
+		case pars.KindDelim:
+			if a.nullable[children[0]] && a.nullable[children[1]] {
+				a.report(EmptyLoop, n, "item and delimiter of Delim may both match empty input")
+			}
+		}
+	}
+}
+
+// byteSet is a set of bytes matched at a single position.
+type byteSet [256]bool
+
+func (s *byteSet) subsetOf(t *byteSet) bool {
+	for c := range s {
+		if s[c] && !t[c] {
+			return false
+		}
+	}
+	return true
+}
+
+func literalPattern(p []byte) []byteSet {
+	sets := make([]byteSet, len(p))
+	for i, c := range p {
+		sets[i][c] = true
+	}
+	return sets
+}
+
+// pattern returns the byte sets matched at each position by a Node which
+// always matches a fixed number of bytes, or false if the Node is not of
+// such a form.
+func pattern(n *pars.Node, seen map[*pars.Node]bool) ([]byteSet, bool) {
+	if seen[n] {
+		return nil, false
+	}
+	seen[n] = true
+	defer delete(seen, n)
+
+	children := n.Children()
+	switch n.Kind {
+	case pars.KindByte:
+		set := byteSet{}
+		for c := range set {
+			set[c] = n.Literal == nil
+		}
+		for _, c := range n.Literal {
+			set[c] = true
+		}
+		return []byteSet{set}, true
+	case pars.KindByteRange:
+		set := byteSet{}
+		for c := int(n.Literal[0]); c <= int(n.Literal[1]); c++ {
+			set[c] = true
+		}
+		return []byteSet{set}, true
+	case pars.KindBytes, pars.KindString:
+		return literalPattern(n.Literal), true
+	case pars.KindRunes:
+		return literalPattern([]byte(string(n.Runes))), true
+	case pars.KindRune:
+		if len(n.Runes) == 1 {
+			return literalPattern([]byte(string(n.Runes))), true
+		}
+		return nil, false
+	case pars.KindFilter:
+		set := byteSet{}
+		p := n.Parser()
+		for c := range set {
+			_, err := p.Parse(pars.FromBytes([]byte{byte(c)}))
+			set[c] = err == nil
+		}
+		return []byteSet{set}, true
+	case pars.KindSeq:
+		sets := []byteSet{}
+		for _, child := range children {
+			s, ok := pattern(child, seen)
+			if !ok {
+				return nil, false
+			}
+			sets = append(sets, s...)
+		}
+		return sets, true
+	case pars.KindAny:
+		// Only a union of single bytes can be represented exactly.
+		set := byteSet{}
+		for _, child := range children {
+			s, ok := pattern(child, seen)
+			if !ok || len(s) != 1 {
+				return nil, false
+			}
+			for c := range set {
+				set[c] = set[c] || s[0][c]
+			}
+		}
+		return []byteSet{set}, true
+	case pars.KindMap, pars.KindRef, pars.KindNamed, pars.KindCapture, pars.KindWrap:
+		if len(children) == 0 {
+			return nil, false
+		}
+		return pattern(children[0], seen)
+	default:
+		return nil, false
+	}
+}
+
+// succeeds tests if the Node will always match.
+func succeeds(n *pars.Node, seen map[*pars.Node]bool) bool {
+	if seen[n] {
+		return false
+	}
+	seen[n] = true
+	defer delete(seen, n)
+
+	children := n.Children()
+	switch n.Kind {
+	case pars.KindPrimitive:
+		return succeedingPrimitives[n.Name]
+	case pars.KindMaybe, pars.KindMany:
+		return true
+	case pars.KindSeq:
+		for _, child := range children {
+			if !succeeds(child, seen) {
+				return false
+			}
+		}
+		return true
+	case pars.KindAny:
+		for _, child := range children {
+			if succeeds(child, seen) {
+				return true
+			}
+		}
+		return false
+	case pars.KindMap, pars.KindRef, pars.KindNamed, pars.KindCapture, pars.KindWrap, pars.KindDry:
+		return len(children) > 0 && succeeds(children[0], seen)
+	default:
+		return false
+	}
+}
+
+// shadows tests if every input matched by the second Node starts with an
+// input matched by the first Node.
+func shadows(n, m *pars.Node) bool {
+	if succeeds(n, make(map[*pars.Node]bool)) {
+		return true
+	}
+	p, ok := pattern(n, make(map[*pars.Node]bool))
+	if !ok {
+		return false
+	}
+	q, ok := pattern(m, make(map[*pars.Node]bool))
+	if !ok || len(q) < len(p) {
+		return false
+	}
+	for i := range p {
+		if !q[i].subsetOf(&p[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *analyzer) checkShadowed() {
+	for _, n := range a.nodes {
+		if n.Kind != pars.KindAny {
+			continue
+		}
+		children := n.Children()
+		for j := 1; j < len(children); j++ {
+			for i := 0; i < j; i++ {
+				if shadows(children[i], children[j]) {
+					a.report(
+						Shadowed, n,
+						"alternative %d (%s) is shadowed by alternative %d (%s)",
+						j+1, children[j], i+1, children[i],
+					)
+					break
+				}
+			}
+		}
+	}
+}
+
+func (a *analyzer) checkUnused(rules []interface{}) {
+	for _, rule := range rules {
+		n := pars.Inspect(rule)
+		if _, ok := a.rules[n]; ok {
+			continue
+		}
+		name := n.String()
+		if n.Kind == pars.KindNamed {
+			name = n.Name
+		}
+		a.issues = append(a.issues, Issue{Unused, name, n, fmt.Sprintf("rule %s is not reachable", name)})
+	}
+}
+
+// Analyze reports the Issues found in the grammar reachable from the given
+// root Parser. The other given Parsers are the rules of the grammar, which
+// are reported if they are not reachable from the root. Name the rules with
+// pars.Named so they can be referred to in the Issues. Parsers are converted
+// with pars.AsParser if necessary.
+func Analyze(root interface{}, rules ...interface{}) []Issue {
+	a := &analyzer{
+		rules:    make(map[*pars.Node]string),
+		nullable: make(map[*pars.Node]bool),
+	}
+	n := pars.Inspect(root)
+	a.collect(n, "")
+	a.computeNullable()
+	a.checkLeftRecursion(n)
+	a.checkEmptyLoops()
+	a.checkShadowed()
+	a.checkUnused(rules)
+	return a.issues
+}
+
+// Issues are the Issues found in a grammar, which can be returned as an error.
+type Issues []Issue
+
+// Error returns the Issues as a human readable string, one Issue per line.
+func (is Issues) Error() string {
+	s := make([]string, len(is))
+	for i, issue := range is {
+		s[i] = issue.String()
+	}
+	return strings.Join(s, "\n")
+}
+
+// Check analyzes the grammar like Analyze and returns the Issues found as an
+// error of type Issues, or nil if there are none.
+func Check(root interface{}, rules ...interface{}) error {
+	if issues := Analyze(root, rules...); len(issues) > 0 {
+		return Issues(issues)
+	}
+	return nil
+}
+
+// TB is the subset of testing.TB used by Report, which keeps this package
+// from depending on the testing package.
+type TB interface {
+	Helper()
+	Error(args ...interface{})
+}
+
+// Report analyzes the grammar like Analyze and reports each Issue as an error
+// of the test:
+//   func TestGrammar(t *testing.T) {
+//       lint.Report(t, Expr, Expr, Term, Factor)
+//   }
+func Report(t TB, root interface{}, rules ...interface{}) {
+	t.Helper()
+	for _, issue := range Analyze(root, rules...) {
+		t.Error(issue)
+	}
+}
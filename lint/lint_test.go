@@ -0,0 +1,154 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-ascii/ascii"
+	"github.com/go-pars/pars"
+	"github.com/go-pars/pars/peg"
+)
+
+func kinds(issues []Issue) []Kind {
+	v := make([]Kind, len(issues))
+	for i, issue := range issues {
+		v[i] = issue.Kind
+	}
+	return v
+}
+
+func sameKinds(a, b []Kind) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+var analyzeTests = []struct {
+	name string
+	root interface{}
+	out  []Kind
+}{
+	{"clean", pars.Seq(pars.Many(pars.Digit), pars.Delim(pars.Int, ','), pars.End), nil},
+	{"many of maybe", pars.Many(pars.Maybe('a')), []Kind{EmptyLoop}},
+	{"many of many", pars.Many(pars.Many('a')), []Kind{EmptyLoop}},
+	{"many of seq", pars.Many(pars.Seq(pars.Maybe('a'), pars.Spaces)), []Kind{EmptyLoop}},
+	{"delim of maybe", pars.Delim(pars.Maybe('a'), pars.Maybe(',')), []Kind{EmptyLoop}},
+	{"delim of item", pars.Delim(pars.Maybe('a'), ','), nil},
+	{"prefix string", pars.Any("in", "int"), []Kind{Shadowed}},
+	{"same string", pars.Any("a", pars.Byte('a')), []Kind{Shadowed}},
+	{"byte set", pars.Any(pars.Letter, "if"), []Kind{Shadowed}},
+	{"filter", pars.Any(pars.Filter(ascii.IsDigit), pars.Seq('1', '2')), []Kind{Shadowed}},
+	{"maybe first", pars.Any(pars.Maybe('a'), 'b'), []Kind{Shadowed}},
+	{"longest first", pars.Any("int", "in"), nil},
+	{"distinct", pars.Any(pars.Digit, pars.Letter), nil},
+	{"opaque", pars.Any(pars.Int, "1"), nil},
+	{"many of nested", pars.Many(pars.Nested(pars.Maybe('a'), 3)), []Kind{EmptyLoop}},
+	{"memoized prefix", pars.Any(pars.Memoize("in"), "int"), []Kind{Shadowed}},
+	{"many of length prefixed", pars.Many(pars.LengthPrefixed(pars.Uint8, pars.Maybe('a'), 255)), nil},
+}
+
+func TestAnalyze(t *testing.T) {
+	for _, tt := range analyzeTests {
+		issues := Analyze(tt.root)
+		if out := kinds(issues); !sameKinds(out, tt.out) {
+			t.Errorf("%s: Analyze() = %v, want %v", tt.name, issues, tt.out)
+		}
+	}
+}
+
+func TestShadowedMessage(t *testing.T) {
+	issues := Analyze(pars.Named("keyword", pars.Any("in", "int")))
+	if len(issues) != 1 {
+		t.Fatalf("Analyze() = %v, want a single issue", issues)
+	}
+	e := `keyword: alternative 2 (String("int")) is shadowed by alternative 1 (String("in"))`
+	if s := issues[0].String(); s != e {
+		t.Errorf("issue = %q, want %q", s, e)
+	}
+}
+
+func TestLeftRecursion(t *testing.T) {
+	var expr pars.Parser
+	sum := pars.Named("sum", pars.Seq(&expr, '+', pars.Int))
+	expr = pars.Named("expr", pars.Any(sum, pars.Int))
+
+	issues := Analyze(expr)
+	if len(issues) != 1 || issues[0].Kind != LeftRecursion {
+		t.Fatalf("Analyze() = %v, want left recursion", issues)
+	}
+	if e := "expr: left recursion: expr -> sum -> expr"; issues[0].String() != e {
+		t.Errorf("issue = %q, want %q", issues[0], e)
+	}
+
+	// Recursion after consuming input is fine.
+	var list pars.Parser
+	list = pars.Named("list", pars.Seq('(', pars.Maybe(&list), ')'))
+	Report(t, list)
+
+	// Recursion after a nullable prefix is not.
+	var nested pars.Parser
+	nested = pars.Seq(pars.Spaces, pars.Maybe(pars.Seq(&nested, 'a')))
+	if out := kinds(Analyze(nested)); !sameKinds(out, []Kind{LeftRecursion}) {
+		t.Errorf("Analyze() = %v, want left recursion", out)
+	}
+}
+
+func TestUnused(t *testing.T) {
+	a := pars.Named("a", "a")
+	b := pars.Named("b", "b")
+	root := pars.Named("root", pars.Seq(a, pars.End))
+
+	issues := Analyze(root, root, a, b)
+	if len(issues) != 1 || issues[0].Kind != Unused || issues[0].Rule != "b" {
+		t.Fatalf("Analyze() = %v, want unused rule b", issues)
+	}
+}
+
+func TestCheckGrammar(t *testing.T) {
+	rules := peg.MustCompile(`
+		Expr    <- Sum !.
+		Sum     <- Product (('+' / '-') Product)*
+		Product <- Value (('*' / '/') Value)*
+		Value   <- [0-9]+ / '(' Sum ')'
+	`, nil)
+	Report(t, rules["Expr"], rules["Sum"], rules["Product"], rules["Value"])
+
+	rules = peg.MustCompile(`
+		Expr   <- Expr '+' Term / Term
+		Term   <- ('a' / 'ab') ' '*
+		Unused <- 'x'
+	`, nil)
+	issues := Analyze(rules["Expr"], rules["Expr"], rules["Term"], rules["Unused"])
+	if out := kinds(issues); !sameKinds(out, []Kind{LeftRecursion, Shadowed, Unused}) {
+		t.Errorf("Analyze() = %v", issues)
+	}
+
+	err := Check(rules["Expr"], rules["Expr"], rules["Term"], rules["Unused"])
+	if is, ok := err.(Issues); !ok || len(is) != 3 || strings.Count(err.Error(), "\n") != 2 {
+		t.Errorf("Check() = %v, want 3 issues", err)
+	}
+	if err := Check(rules["Term"]); err == nil {
+		t.Errorf("Check() expected an error")
+	}
+	if err := Check(rules["Unused"]); err != nil {
+		t.Errorf("Check() = %v, want nil", err)
+	}
+
+	tb := &recorder{}
+	Report(tb, rules["Term"])
+	if len(tb.errors) != 1 {
+		t.Errorf("Report() reported %v, want 1 issue", tb.errors)
+	}
+}
+
+type recorder struct{ errors []interface{} }
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Error(args ...interface{}) { r.errors = append(r.errors, args...) }
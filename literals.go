@@ -23,11 +23,14 @@ func convertNumber(state *State) (float64, error) {
 //   zero     = `0`
 //   non-zero = `1` | `2` | `3` | `4` | `5` | `6` | `7` | `8` | `9`
 //   digit    = zero | non-zero
-//   integer  = [ ( `-` | `+` ) ], zero | digit, { digit }
+//   integer  = [ ( `-` | `+` ) ], ( zero | non-zero, { digit } )
 //
 // This implementation is optimized so the parser will first scan as far as
 // possible to match a valid integer and then retrieve a block of bytes and
-// convert it to an `int` via strconv.Atoi.
+// convert it to an `int` via strconv.Atoi. A leading `0` is matched as an
+// integer on its own, so `012` is matched as `0` followed by `12`. Existing
+// grammars rely on this behavior so it is kept as is: use Integer to match
+// leading zeros, other bases, separators, or sizes.
 func Int(state *State, result *Result) error {
 	// Scan forwards from the current position.
 	state.Push()
@@ -241,7 +244,7 @@ func Between(l, r byte) Parser {
 	whatL := fmt.Sprintf("expected opening `%c`", l)
 	whatR := fmt.Sprintf("expected closing `%c`", r)
 
-	return func(state *State, result *Result) error {
+	return describe(&Node{Kind: KindBetween, Literal: []byte{l, r}}, func(state *State, result *Result) error {
 		state.Push()
 
 		c, err := Next(state)
@@ -279,7 +282,7 @@ func Between(l, r byte) Parser {
 		Skip(state, 1)
 		result.SetToken(p[1:])
 		return nil
-	}
+	})
 }
 
 // Quoted creates a Parser which will attempt to match a sequence of bytes
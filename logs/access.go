@@ -0,0 +1,94 @@
+package logs
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-pars/pars"
+)
+
+// Access is an entry of a web server access log. The fields given as `-` are
+// empty, and the Method, Target, and Proto are only set if the Request is a
+// well-formed request line.
+type Access struct {
+	Host      string
+	Ident     string
+	User      string
+	Time      time.Time
+	Request   string
+	Method    string
+	Target    string
+	Proto     string
+	Status    int
+	Size      int
+	Referer   string
+	UserAgent string
+}
+
+const accessLayout = "02/Jan/2006:15:04:05 -0700"
+
+// accessFields returns the parsers for the fields of the Common Log Format
+// followed by the given parsers.
+func accessFields(qs ...interface{}) []interface{} {
+	return append([]interface{}{
+		pars.Capture("host", pars.Word(isField)), ' ',
+		pars.Capture("ident", pars.Word(isField)), ' ',
+		pars.Capture("user", pars.Word(isField)), ' ',
+		pars.Capture("time", timestamp(pars.Between('[', ']'), accessLayout)), ' ',
+		pars.Capture("request", pars.Quoted('"')), ' ',
+		pars.Capture("status", integer(3, 3, "a status code")), ' ',
+		pars.Capture("size", pars.Any('-', integer(1, 18, "a size"))),
+	}, qs...)
+}
+
+// access maps the fields of an access log line to an Access.
+func access(result *pars.Result) error {
+	m := result.Named()
+	str := func(name string, quoted bool) string {
+		r := m[name]
+		if r == nil {
+			return ""
+		}
+		s := string(r.Token)
+		if quoted {
+			s = unescape(r.Token)
+		}
+		if s == "-" {
+			return ""
+		}
+		return s
+	}
+	a := Access{
+		Host:      str("host", false),
+		Ident:     str("ident", false),
+		User:      str("user", false),
+		Time:      m["time"].Value.(time.Time),
+		Request:   str("request", true),
+		Status:    m["status"].Value.(int),
+		Referer:   str("referer", true),
+		UserAgent: str("agent", true),
+	}
+	a.Size, _ = m["size"].Value.(int)
+	if parts := strings.Split(a.Request, " "); len(parts) == 3 {
+		a.Method, a.Target, a.Proto = parts[0], parts[1], parts[2]
+	}
+	result.SetValue(a)
+	return nil
+}
+
+// Access log parsers.
+var (
+	// Common matches a line of the Common Log Format and sets the value to an
+	// Access:
+	//   127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET / HTTP/1.0" 200 2326
+	Common = pars.Seq(accessFields(pars.EOL)...).Map(access)
+
+	// Combined matches a line of the Combined Log Format, which is the Common
+	// Log Format followed by the quoted referer and user agent, and sets the
+	// value to an Access.
+	Combined = pars.Seq(accessFields(
+		' ', pars.Capture("referer", pars.Quoted('"')),
+		' ', pars.Capture("agent", pars.Quoted('"')),
+		pars.EOL,
+	)...).Map(access)
+)
@@ -0,0 +1,137 @@
+package logs
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-pars/pars"
+)
+
+// Field is a key/value pair of a logfmt line.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Fields are the key/value pairs of a logfmt line in order of appearance.
+type Fields []Field
+
+// Get returns the value of the first field with the given key.
+func (f Fields) Get(key string) (string, bool) {
+	for _, field := range f {
+		if field.Key == key {
+			return field.Value, true
+		}
+	}
+	return "", false
+}
+
+// Time converts the value of the first field with the given key with the
+// Time mapping for the given layout.
+func (f Fields) Time(key, layout string) (time.Time, error) {
+	v, ok := f.Get(key)
+	if !ok {
+		return time.Time{}, fmt.Errorf("missing key %q", key)
+	}
+	result := &pars.Result{Token: []byte(v)}
+	if err := pars.Time(layout)(result); err != nil {
+		return time.Time{}, err
+	}
+	return result.Value.(time.Time), nil
+}
+
+func isKey(c byte) bool { return c > ' ' && c != '=' && c != '"' && c != 0x7f }
+
+func isBlank(c byte) bool { return c == ' ' || c == '\t' }
+
+// word matches the bytes satisfying the filter, which may be none.
+func word(state *pars.State, filter func(byte) bool) string {
+	state.Push()
+	for {
+		c, err := pars.Next(state)
+		if err != nil || !filter(c) {
+			break
+		}
+		state.Advance()
+	}
+	p, _ := pars.Trail(state)
+	return string(p)
+}
+
+// quotedValue matches a double quoted value with the escapes of a Go string.
+func quotedValue(state *pars.State) (string, error) {
+	pos := state.Position()
+	state.Push()
+	state.Advance()
+	for {
+		c, err := pars.Next(state)
+		if err != nil || c == '\n' || c == '\r' {
+			state.Pop()
+			return "", pars.NewError("unterminated quoted value", pos)
+		}
+		state.Advance()
+		if c == '"' {
+			break
+		}
+		if c == '\\' {
+			pars.Skip(state, 1)
+		}
+	}
+	p, _ := pars.Trail(state)
+	s, err := strconv.Unquote(string(p))
+	if err != nil {
+		return "", pars.NewError("invalid quoted value", pos)
+	}
+	return s, nil
+}
+
+// logfmt matches a logfmt line and sets the value to the Fields. A key
+// without a value has an empty value.
+func logfmt(state *pars.State, result *pars.Result) error {
+	state.Push()
+	fail := func(err error) error {
+		state.Pop()
+		return err
+	}
+	unexpected := func(c byte) error {
+		return fail(pars.NewError(fmt.Sprintf("unexpected %q", c), state.Position()))
+	}
+
+	fields := Fields{}
+	for {
+		word(state, isBlank)
+		c, err := pars.Next(state)
+		if err != nil || c == '\n' || c == '\r' {
+			break
+		}
+		key := word(state, isKey)
+		if key == "" {
+			return unexpected(c)
+		}
+		value := ""
+		if c, err := pars.Next(state); err == nil && c == '=' {
+			state.Advance()
+			if c, err := pars.Next(state); err == nil && c == '"' {
+				if value, err = quotedValue(state); err != nil {
+					return fail(err)
+				}
+			} else {
+				value = word(state, isKey)
+			}
+		}
+		if c, err := pars.Next(state); err == nil && !isBlank(c) && c != '\n' && c != '\r' {
+			return unexpected(c)
+		}
+		fields = append(fields, Field{key, value})
+	}
+	pars.EOL(state, result)
+	state.Drop()
+	result.SetValue(fields)
+	return nil
+}
+
+// Logfmt matches a line of key=value pairs separated by spaces and sets the
+// value to the Fields. A value containing spaces or `=` is double quoted:
+//   level=info msg="request done" path=/ status=200 cached
+var Logfmt pars.Parser = logfmt
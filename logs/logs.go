@@ -0,0 +1,116 @@
+// Package logs implements parsers for common log formats:
+//
+//   RFC5424   syslog messages as defined by RFC 5424
+//   RFC3164   BSD syslog messages as described by RFC 3164
+//   Common    the Common Log Format of Apache and Nginx
+//   Combined  the Combined Log Format, which adds the referer and user agent
+//   Logfmt    lines of key=value pairs
+//
+// Each parser matches a single line including the line ending and sets the
+// value to a typed struct, so the parsers can be used with pars.Records or a
+// pars.Scanner to process arbitrarily large files line by line:
+//
+//   err := pars.Records(file, logs.Combined, func(result *pars.Result) error {
+//       entry := result.Value.(logs.Access)
+//       ...
+//       return nil
+//   })
+//
+// A pars.Scanner with pars.Line as the Recover parser will skip malformed
+// lines instead of stopping at the first one.
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-pars/pars"
+)
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func isPrintUSASCII(c byte) bool { return 33 <= c && c <= 126 }
+
+func isField(c byte) bool { return c != ' ' && c != '\t' && c != '\r' && c != '\n' }
+
+// timestamp creates a Parser which will attempt to match the given Parser
+// and convert its token with the Time mapping for the given layout. The state
+// is restored if the token is not a valid time.
+func timestamp(q interface{}, layout string) pars.Parser {
+	p := pars.AsParser(q).Map(pars.Time(layout))
+	return func(state *pars.State, result *pars.Result) error {
+		pos := state.Position()
+		state.Push()
+		if err := p(state, result); err != nil {
+			state.Pop()
+			var perr pars.Error
+			if !errors.As(err, &perr) {
+				err = pars.NewError(fmt.Sprintf("invalid timestamp: %v", err), pos)
+			}
+			return err
+		}
+		state.Drop()
+		return nil
+	}
+}
+
+// integer creates a Parser which will match a decimal number of at least min
+// and at most max digits and set the value to an int.
+func integer(min, max int, what string) pars.Parser {
+	what = "expected " + what
+	return func(state *pars.State, result *pars.Result) error {
+		pos := state.Position()
+		state.Push()
+		n := 0
+		for n < max {
+			c, err := pars.Next(state)
+			if err != nil || !isDigit(c) {
+				break
+			}
+			state.Advance()
+			n++
+		}
+		if n < min {
+			state.Pop()
+			return pars.NewError(what, pos)
+		}
+		p, _ := pars.Trail(state)
+		v, _ := strconv.Atoi(string(p))
+		result.SetValue(v)
+		return nil
+	}
+}
+
+// unescape replaces the escape sequences `\"`, `\\`, and `\xHH` written by
+// web servers in quoted fields.
+func unescape(p []byte) string {
+	if !strings.ContainsRune(string(p), '\\') {
+		return string(p)
+	}
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		if p[i] != '\\' || i+1 == len(p) {
+			b.WriteByte(p[i])
+			continue
+		}
+		switch c := p[i+1]; c {
+		case '"', '\\':
+			b.WriteByte(c)
+			i++
+		case 'x':
+			if i+3 < len(p) {
+				if v, err := strconv.ParseUint(string(p[i+2:i+4]), 16, 8); err == nil {
+					b.WriteByte(byte(v))
+					i += 3
+					continue
+				}
+			}
+			b.WriteByte('\\')
+		default:
+			b.WriteByte('\\')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,260 @@
+package logs
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-pars/pars"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+var parseTests = []struct {
+	name string
+	p    pars.Parser
+	in   string
+	out  interface{}
+}{
+	{
+		"rfc5424", RFC5424,
+		"<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 - \xEF\xBB\xBF'su root' failed for lonvick on /dev/pts/8\n",
+		Syslog{
+			Priority:  34,
+			Version:   1,
+			Timestamp: date("2003-10-11T22:14:15.003Z"),
+			Hostname:  "mymachine.example.com",
+			AppName:   "su",
+			MsgID:     "ID47",
+			Message:   "'su root' failed for lonvick on /dev/pts/8",
+		},
+	},
+	{
+		"rfc5424 structured data", RFC5424,
+		`<165>1 2003-10-11T22:14:15.003-07:00 host evntslog 8710 ID47 [exampleSDID@32473 iut="3" eventID="1011"][examplePriority@32473 class="high" note="a \"b\" \] \x"]`,
+		Syslog{
+			Priority:  165,
+			Version:   1,
+			Timestamp: date("2003-10-11T22:14:15.003-07:00"),
+			Hostname:  "host",
+			AppName:   "evntslog",
+			ProcID:    "8710",
+			MsgID:     "ID47",
+			StructuredData: []SDElement{
+				{"exampleSDID@32473", []SDParam{{"iut", "3"}, {"eventID", "1011"}}},
+				{"examplePriority@32473", []SDParam{{"class", "high"}, {"note", `a "b" ] \x`}}},
+			},
+		},
+	},
+	{
+		"rfc5424 nil values", RFC5424,
+		"<0>1 - - - - - - \r\n",
+		Syslog{Version: 1},
+	},
+	{
+		"rfc3164", RFC3164,
+		"<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick\n",
+		Syslog{
+			Priority:  34,
+			Timestamp: time.Date(0, time.October, 11, 22, 14, 15, 0, time.UTC),
+			Hostname:  "mymachine",
+			AppName:   "su",
+			ProcID:    "123",
+			Message:   "'su root' failed for lonvick",
+		},
+	},
+	{
+		"rfc3164 without tag", RFC3164,
+		"<13>Feb  5 17:32:18 10.0.0.99 Use the BFG!",
+		Syslog{
+			Priority:  13,
+			Timestamp: time.Date(0, time.February, 5, 17, 32, 18, 0, time.UTC),
+			Hostname:  "10.0.0.99",
+			Message:   "Use the BFG!",
+		},
+	},
+	{
+		"common", Common,
+		"127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] \"GET /apache_pb.gif HTTP/1.0\" 200 2326\n",
+		Access{
+			Host:    "127.0.0.1",
+			User:    "frank",
+			Time:    date("2000-10-10T13:55:36-07:00"),
+			Request: "GET /apache_pb.gif HTTP/1.0",
+			Method:  "GET",
+			Target:  "/apache_pb.gif",
+			Proto:   "HTTP/1.0",
+			Status:  200,
+			Size:    2326,
+		},
+	},
+	{
+		"common without size", Common,
+		`::1 - - [01/Jan/2021:00:00:00 +0100] "\x16\x03\x01" 400 -`,
+		Access{
+			Host:    "::1",
+			Time:    date("2021-01-01T00:00:00+01:00"),
+			Request: "\x16\x03\x01",
+			Status:  400,
+		},
+	},
+	{
+		"combined", Combined,
+		`203.0.113.9 - - [10/Oct/2000:13:55:36 -0700] "GET /a?q=\"x\" HTTP/1.1" 304 0 "http://example.com/" "Mozilla/5.0 (X11)"` + "\n",
+		Access{
+			Host:      "203.0.113.9",
+			Time:      date("2000-10-10T13:55:36-07:00"),
+			Request:   `GET /a?q="x" HTTP/1.1`,
+			Method:    "GET",
+			Target:    `/a?q="x"`,
+			Proto:     "HTTP/1.1",
+			Status:    304,
+			Referer:   "http://example.com/",
+			UserAgent: "Mozilla/5.0 (X11)",
+		},
+	},
+	{
+		"logfmt", Logfmt,
+		"level=info msg=\"request done\" path=/ status=200 cached empty= quote=\"a\\\"b\"\n",
+		Fields{
+			{"level", "info"},
+			{"msg", "request done"},
+			{"path", "/"},
+			{"status", "200"},
+			{"cached", ""},
+			{"empty", ""},
+			{"quote", `a"b`},
+		},
+	},
+	{
+		"logfmt blank", Logfmt,
+		"   \n",
+		Fields{},
+	},
+}
+
+func TestParse(t *testing.T) {
+	for _, tt := range parseTests {
+		result, err := tt.p.Parse(pars.FromString(tt.in))
+		if err != nil {
+			t.Errorf("%s: Parse(%q): %v", tt.name, tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(result.Value, tt.out) {
+			t.Errorf("%s: Parse(%q) = %#v, want %#v", tt.name, tt.in, result.Value, tt.out)
+		}
+	}
+}
+
+var parseErrorTests = []struct {
+	name string
+	p    pars.Parser
+	in   string
+	pos  pars.Position
+}{
+	{"rfc5424 priority", RFC5424, "<192>1 - - - - - -", pars.Position{Byte: 0}},
+	{"rfc5424 timestamp", RFC5424, "<1>1 2003-13-11T22:14:15Z - - - - -", pars.Position{Byte: 5}},
+	{"rfc5424 app name", RFC5424, "<1>1 - h " + strings.Repeat("a", 49) + " - - -", pars.Position{Byte: 9}},
+	{"rfc5424 sd", RFC5424, `<1>1 - - - - - [id a="b]`, pars.Position{Byte: 21}},
+	{"rfc5424 sd name", RFC5424, `<1>1 - - - - - [id ="b"]`, pars.Position{Byte: 19}},
+	{"rfc3164 timestamp", RFC3164, "<1>Foo 11 22:14:15 host msg", pars.Position{Byte: 3}},
+	{"common timestamp", Common, `h - - [10/Oct/2000:25:55:36 -0700] "GET /" 200 1`, pars.Position{Byte: 6}},
+	{"common status", Common, `h - - [10/Oct/2000:13:55:36 -0700] "GET /" 20 1`, pars.Position{Byte: 43}},
+	{"combined agent", Combined, `h - - [10/Oct/2000:13:55:36 -0700] "GET /" 200 1 "-" x`, pars.Position{Byte: 53}},
+	{"logfmt value", Logfmt, `a=b"c`, pars.Position{Byte: 3}},
+	{"logfmt quote", Logfmt, `a="b`, pars.Position{Byte: 2}},
+	{"logfmt key", Logfmt, `a=b =c`, pars.Position{Byte: 4}},
+}
+
+func TestParseError(t *testing.T) {
+	for _, tt := range parseErrorTests {
+		_, err := tt.p.Parse(pars.FromString(tt.in))
+		var perr pars.Error
+		if !errors.As(err, &perr) || perr.Position() != tt.pos {
+			t.Errorf("%s: Parse(%q) = %v, want an error at %v", tt.name, tt.in, err, tt.pos)
+		}
+	}
+}
+
+func TestSyslogPriority(t *testing.T) {
+	m := Syslog{Priority: 165}
+	if m.Facility() != 20 || m.Severity() != 5 {
+		t.Errorf("Facility(), Severity() = %d, %d, want 20, 5", m.Facility(), m.Severity())
+	}
+}
+
+func TestFieldsTime(t *testing.T) {
+	result, err := Logfmt.Parse(pars.FromString("ts=2020-01-02T03:04:05Z bad=x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := result.Value.(Fields)
+	if ts, err := fields.Time("ts", time.RFC3339); err != nil || !ts.Equal(date("2020-01-02T03:04:05Z")) {
+		t.Errorf("Time(ts) = %v, %v", ts, err)
+	}
+	if _, err := fields.Time("bad", time.RFC3339); err == nil {
+		t.Error("Time(bad) expected an error")
+	}
+	if _, err := fields.Time("missing", time.RFC3339); err == nil {
+		t.Error("Time(missing) expected an error")
+	}
+}
+
+func TestRecords(t *testing.T) {
+	in := strings.Repeat(`10.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET / HTTP/1.1" 200 5 "-" "curl/7.68.0"`+"\n", 100)
+	n, size := 0, 0
+	err := pars.Records(strings.NewReader(in), Combined, func(result *pars.Result) error {
+		a := result.Value.(Access)
+		n++
+		size += a.Size
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 100 || size != 500 {
+		t.Errorf("read %d entries of total size %d, want 100 and 500", n, size)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	in := "<1>1 - - - - - - a\ngarbage\n<2>1 - - - - - - b\n"
+	s := pars.NewScanner(strings.NewReader(in), RFC5424)
+	var bad []int
+	s.Recover(pars.Line, func(err error) {
+		var rerr pars.RecordError
+		if errors.As(err, &rerr) {
+			bad = append(bad, rerr.Record())
+		}
+	})
+	var msgs []string
+	for s.Scan() {
+		msgs = append(msgs, s.Result().Value.(Syslog).Message)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(msgs, []string{"a", "b"}) || !reflect.DeepEqual(bad, []int{1}) {
+		t.Errorf("read %q with bad records %v, want [a b] and [1]", msgs, bad)
+	}
+}
+
+func BenchmarkCombined(b *testing.B) {
+	p := []byte(strings.Repeat(`203.0.113.9 - - [10/Oct/2000:13:55:36 -0700] "GET /index.html HTTP/1.1" 200 2326 "http://example.com/" "Mozilla/5.0 (X11; Linux x86_64)"`+"\n", 64))
+	b.ReportAllocs()
+	b.SetBytes(int64(len(p)))
+	for i := 0; i < b.N; i++ {
+		err := pars.Records(pars.FromBytes(p), Combined, func(result *pars.Result) error { return nil })
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
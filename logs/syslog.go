@@ -0,0 +1,302 @@
+package logs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-pars/pars"
+)
+
+// SDParam is a parameter of a structured data element.
+type SDParam struct {
+	Name  string
+	Value string
+}
+
+// SDElement is a structured data element of an RFC 5424 message.
+type SDElement struct {
+	ID     string
+	Params []SDParam
+}
+
+// Syslog is a syslog message. The fields which are absent from the format or
+// given as the nil value `-` are empty.
+type Syslog struct {
+	Priority       int
+	Version        int
+	Timestamp      time.Time
+	Hostname       string
+	AppName        string
+	ProcID         string
+	MsgID          string
+	StructuredData []SDElement
+	Message        string
+}
+
+// Facility returns the facility encoded in the priority.
+func (m Syslog) Facility() int { return m.Priority / 8 }
+
+// Severity returns the severity encoded in the priority.
+func (m Syslog) Severity() int { return m.Priority % 8 }
+
+// priority matches the priority of a message and sets the value to an int.
+func priority(state *pars.State, result *pars.Result) error {
+	pos := state.Position()
+	state.Push()
+	fail := func(what string, pos pars.Position) error {
+		state.Pop()
+		return pars.NewError(what, pos)
+	}
+	if c, err := pars.Next(state); err != nil || c != '<' {
+		return fail("expected `<`", pos)
+	}
+	state.Advance()
+	if err := integer(1, 3, "a priority")(state, result); err != nil {
+		state.Pop()
+		return err
+	}
+	if result.Value.(int) > 191 {
+		return fail("priority out of range", pos)
+	}
+	if c, err := pars.Next(state); err != nil || c != '>' {
+		return fail("expected `>`", state.Position())
+	}
+	state.Advance()
+	state.Drop()
+	return nil
+}
+
+// field creates a Parser which will match a header field of printable
+// US-ASCII bytes up to the given length and set the value to the string, or
+// to "" for the nil value `-`.
+func field(max int, what string) pars.Parser {
+	expected := "expected " + what
+	tooLong := fmt.Sprintf("%s is longer than %d bytes", what, max)
+	return func(state *pars.State, result *pars.Result) error {
+		pos := state.Position()
+		state.Push()
+		n := 0
+		for {
+			c, err := pars.Next(state)
+			if err != nil || !isPrintUSASCII(c) {
+				break
+			}
+			state.Advance()
+			n++
+		}
+		switch {
+		case n == 0:
+			state.Pop()
+			return pars.NewError(expected, pos)
+		case n > max:
+			state.Pop()
+			return pars.NewError(tooLong, pos)
+		}
+		p, _ := pars.Trail(state)
+		s := string(p)
+		if s == "-" {
+			s = ""
+		}
+		result.SetValue(s)
+		return nil
+	}
+}
+
+func isSDName(c byte) bool {
+	return isPrintUSASCII(c) && c != '=' && c != ']' && c != '"'
+}
+
+// sdName matches the name of a structured data element or parameter.
+func sdName(state *pars.State, what string) (string, error) {
+	pos := state.Position()
+	state.Push()
+	n := 0
+	for {
+		c, err := pars.Next(state)
+		if err != nil || !isSDName(c) {
+			break
+		}
+		state.Advance()
+		n++
+	}
+	if n == 0 || n > 32 {
+		state.Pop()
+		return "", pars.NewError("expected "+what, pos)
+	}
+	p, _ := pars.Trail(state)
+	return string(p), nil
+}
+
+// sdValue matches a quoted parameter value, in which `"`, `\`, and `]` are
+// escaped by a backslash.
+func sdValue(state *pars.State) (string, error) {
+	pos := state.Position()
+	if c, err := pars.Next(state); err != nil || c != '"' {
+		return "", pars.NewError("expected `\"`", pos)
+	}
+	state.Advance()
+	var b strings.Builder
+	for {
+		c, err := pars.Next(state)
+		if err != nil || c == '\n' {
+			return "", pars.NewError("unterminated parameter value", pos)
+		}
+		state.Advance()
+		switch c {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			if c, err := pars.Next(state); err == nil && (c == '"' || c == '\\' || c == ']') {
+				state.Advance()
+				b.WriteByte(c)
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+}
+
+// sdElement matches a structured data element.
+func sdElement(state *pars.State) (SDElement, error) {
+	var e SDElement
+	state.Advance()
+	id, err := sdName(state, "an element ID")
+	if err != nil {
+		return e, err
+	}
+	e.ID = id
+	for {
+		c, err := pars.Next(state)
+		switch {
+		case err == nil && c == ']':
+			state.Advance()
+			return e, nil
+		case err == nil && c == ' ':
+			state.Advance()
+		default:
+			return e, pars.NewError("expected ` ` or `]`", state.Position())
+		}
+		name, err := sdName(state, "a parameter name")
+		if err != nil {
+			return e, err
+		}
+		if c, err := pars.Next(state); err != nil || c != '=' {
+			return e, pars.NewError("expected `=`", state.Position())
+		}
+		state.Advance()
+		value, err := sdValue(state)
+		if err != nil {
+			return e, err
+		}
+		e.Params = append(e.Params, SDParam{name, value})
+	}
+}
+
+// structuredData matches the structured data of an RFC 5424 message and sets
+// the value to a []SDElement, which is nil for the nil value `-`.
+func structuredData(state *pars.State, result *pars.Result) error {
+	pos := state.Position()
+	if c, err := pars.Next(state); err == nil && c == '-' {
+		state.Advance()
+		result.SetValue([]SDElement(nil))
+		return nil
+	}
+	state.Push()
+	var elements []SDElement
+	for {
+		if c, err := pars.Next(state); err != nil || c != '[' {
+			break
+		}
+		e, err := sdElement(state)
+		if err != nil {
+			state.Pop()
+			return err
+		}
+		elements = append(elements, e)
+	}
+	if elements == nil {
+		state.Pop()
+		return pars.NewError("expected structured data", pos)
+	}
+	state.Drop()
+	result.SetValue(elements)
+	return nil
+}
+
+// message matches the message after a space up to the end of the line, or
+// the end of the line if there is no message.
+func message(state *pars.State, result *pars.Result) error {
+	if c, err := pars.Next(state); err == nil && c == ' ' {
+		state.Advance()
+		return pars.Line(state, result)
+	}
+	if err := pars.EOL(state, result); err != nil {
+		return err
+	}
+	result.SetToken(nil)
+	return nil
+}
+
+// nilValue matches the nil value `-` of a field.
+var nilValue = pars.Seq('-', pars.Dry(' '))
+
+func isTag(c byte) bool { return isPrintUSASCII(c) && c != '[' && c != ':' }
+
+// Syslog parsers.
+var (
+	// RFC5424 matches a line of an RFC 5424 syslog message and sets the value
+	// to a Syslog. A byte order mark at the start of the message is removed.
+	RFC5424 = pars.Seq(
+		pars.Capture("priority", priority),
+		pars.Capture("version", integer(1, 3, "a version")), ' ',
+		pars.Capture("timestamp", pars.Any(nilValue, timestamp(pars.Word(isPrintUSASCII), time.RFC3339Nano))), ' ',
+		pars.Capture("hostname", field(255, "a hostname")), ' ',
+		pars.Capture("app", field(48, "an app name")), ' ',
+		pars.Capture("procid", field(128, "a process ID")), ' ',
+		pars.Capture("msgid", field(32, "a message ID")), ' ',
+		pars.Capture("sd", structuredData),
+		pars.Capture("msg", message),
+	).Map(func(result *pars.Result) error {
+		m := result.Named()
+		msg := Syslog{
+			Priority:       m["priority"].Value.(int),
+			Version:        m["version"].Value.(int),
+			Hostname:       m["hostname"].Value.(string),
+			AppName:        m["app"].Value.(string),
+			ProcID:         m["procid"].Value.(string),
+			MsgID:          m["msgid"].Value.(string),
+			StructuredData: m["sd"].Value.([]SDElement),
+			Message:        strings.TrimPrefix(string(m["msg"].Token), "\ufeff"),
+		}
+		msg.Timestamp, _ = m["timestamp"].Value.(time.Time)
+		result.SetValue(msg)
+		return nil
+	})
+
+	// RFC3164 matches a line of a BSD syslog message and sets the value to a
+	// Syslog. The tag and the process ID in brackets that commonly start the
+	// message are set as the AppName and ProcID if present. The year is not
+	// a part of the format, so the year of the Timestamp is 0 and should be
+	// set by the caller.
+	RFC3164 = pars.Seq(
+		pars.Capture("priority", priority),
+		pars.Capture("timestamp", timestamp(pars.Take(len(time.Stamp)), time.Stamp)), ' ',
+		pars.Capture("hostname", pars.Word(isField)), ' ',
+		pars.Capture("tag", pars.Maybe(pars.Seq(pars.Word(isTag), pars.Maybe(pars.Between('[', ']')), ':', pars.Maybe(' ')))),
+		pars.Capture("msg", pars.Line),
+	).Map(func(result *pars.Result) error {
+		m := result.Named()
+		msg := Syslog{
+			Priority:  m["priority"].Value.(int),
+			Timestamp: m["timestamp"].Value.(time.Time),
+			Hostname:  string(m["hostname"].Token),
+			Message:   string(m["msg"].Token),
+		}
+		if tag := m["tag"].Children; tag != nil {
+			msg.AppName, msg.ProcID = string(tag[0].Token), string(tag[1].Token)
+		}
+		result.SetValue(msg)
+		return nil
+	})
+)
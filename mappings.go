@@ -2,6 +2,7 @@ package pars
 
 import (
 	"bytes"
+	"fmt"
 	"time"
 )
 
@@ -16,6 +17,21 @@ func Child(i int) Map {
 	}
 }
 
+// Field will map to the child captured with the given name.
+func Field(name string) Map {
+	return func(result *Result) error {
+		if result.Children == nil {
+			return errNoChildren
+		}
+		child := result.Get(name)
+		if child == nil {
+			return fmt.Errorf("result does not have a child named %q", name)
+		}
+		*result = *child
+		return nil
+	}
+}
+
 // Children will keep the children associated to the given indices.
 func Children(indices ...int) Map {
 	return func(result *Result) error {
@@ -0,0 +1,134 @@
+package pars
+
+import "sync/atomic"
+
+var memoCount uint32
+
+type memoKey struct {
+	id  uint32
+	off int
+	bit int
+}
+
+// memoEntry is the outcome of a memoized Parser at an offset. The offsets are
+// absolute and far is the offset after the last byte examined by the Parser.
+type memoEntry struct {
+	end    int
+	bit    int
+	far    int
+	result Result
+	err    error
+}
+
+// memoTable holds the memoized results of a state.
+type memoTable struct {
+	entries map[memoKey]memoEntry
+	hits    int
+}
+
+func newMemoTable() *memoTable {
+	return &memoTable{entries: make(map[memoKey]memoEntry)}
+}
+
+// release discards the entries before the given offset, which can no longer
+// be reached once the state has committed past it.
+func (t *memoTable) release(off int) {
+	for key := range t.entries {
+		if key.off < off {
+			delete(t.entries, key)
+		}
+	}
+}
+
+// edit updates the table for an edit replacing the given number of bytes at
+// the given offset with the given number of bytes. Entries which examined
+// any of the replaced bytes are discarded and entries after the replaced
+// bytes are moved by the difference in length. The errors of moved entries
+// are discarded as their positions are no longer valid.
+func (t *memoTable) edit(off, removed, inserted int) {
+	delta := inserted - removed
+	moved := make(map[memoKey]memoEntry, len(t.entries))
+	for key, e := range t.entries {
+		switch {
+		case e.far <= off:
+			moved[key] = e
+		case key.off >= off+removed && e.err == nil:
+			key.off += delta
+			e.end += delta
+			e.far += delta
+			moved[key] = e
+		}
+	}
+	t.entries = moved
+}
+
+// Memoize creates a Parser which will remember the outcome of the given
+// Parser at each offset of the state, so the given Parser is applied at most
+// once for each offset even if the surrounding Parsers backtrack. Memoizing
+// the rules of a grammar with a lot of backtracking avoids exponential
+// parsing times, and allows an IncrementalParser to reuse the results of the
+// rules which are not affected by an edit.
+//
+// The result of the given Parser is shared by every match at the same offset,
+// so a Map must not modify the Children of the result in place. The results
+// are allocated on the heap even if the state has an Arena. The given Parser
+// must not depend on the position of the state, as it may be reused at a
+// different position by an IncrementalParser.
+func Memoize(q interface{}) Parser {
+	p := AsParser(q)
+	id := atomic.AddUint32(&memoCount, 1)
+
+	n := &Node{Kind: KindWrap, Name: "Memoize", children: []Parser{p}}
+	p = direct(p)
+	return describe(n, func(state *State, result *Result) error {
+		if state.memo == nil {
+			state.memo = newMemoTable()
+		}
+		t := state.memo
+		key := memoKey{id, state.InputOffset(), state.bit}
+
+		if e, ok := t.entries[key]; ok {
+			t.hits++
+			if e.far > state.far {
+				state.far = e.far
+			}
+			if e.err != nil {
+				return e.err
+			}
+			if err := state.Request(e.end - key.off); err != nil {
+				return err
+			}
+			state.Advance()
+			state.bit = e.bit
+			*result = e.result
+			return nil
+		}
+
+		// Track the bytes examined by this Parser alone. The results are
+		// kept beyond the lifetime of an Arena so the Arena is not used.
+		far, arena, starved := state.far, state.arena, state.starved
+		state.far, state.arena, state.starved = key.off, nil, false
+		state.Push()
+		err := p(state, result)
+		state.arena = arena
+		if err != nil {
+			state.Pop()
+		} else {
+			state.Drop()
+		}
+
+		// The outcome may change once more input is fed to a push state.
+		if !state.starved {
+			e := memoEntry{end: state.InputOffset(), bit: state.bit, far: state.far, err: err}
+			if err == nil {
+				e.result = *result
+			}
+			t.entries[key] = e
+		}
+		state.starved = state.starved || starved
+		if far > state.far {
+			state.far = far
+		}
+		return err
+	})
+}
@@ -0,0 +1,402 @@
+package pars
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/go-ascii/ascii"
+)
+
+// NumberFormat is a set of flags configuring the syntax accepted by the
+// numeric literal parsers.
+type NumberFormat int
+
+// Flags for configuring a NumberFormat.
+const (
+	// HexPrefix allows integers prefixed with `0x` or `0X`.
+	HexPrefix NumberFormat = 1 << iota
+
+	// OctalPrefix allows integers prefixed with `0o` or `0O`.
+	OctalPrefix
+
+	// BinaryPrefix allows integers prefixed with `0b` or `0B`.
+	BinaryPrefix
+
+	// Underscores allows a single `_` to separate any two digits.
+	Underscores
+)
+
+// Common combinations of NumberFormat flags.
+const (
+	// Decimal only allows plain decimal digits.
+	Decimal NumberFormat = 0
+
+	// BasePrefixes allows all of the base prefixes.
+	BasePrefixes = HexPrefix | OctalPrefix | BinaryPrefix
+
+	// FullSyntax allows all of the base prefixes and digit separators.
+	FullSyntax = BasePrefixes | Underscores
+)
+
+// Parsers for matching integers of a fixed bit size in FullSyntax.
+var (
+	Int64  = Integer(64, FullSyntax)
+	Uint64 = Unsigned(64, FullSyntax)
+)
+
+func prefixBase(c byte, format NumberFormat) int {
+	switch {
+	case (c == 'x' || c == 'X') && format&HexPrefix != 0:
+		return 16
+	case (c == 'o' || c == 'O') && format&OctalPrefix != 0:
+		return 8
+	case (c == 'b' || c == 'B') && format&BinaryPrefix != 0:
+		return 2
+	default:
+		return 10
+	}
+}
+
+func baseFilter(base int) (ascii.Filter, string) {
+	switch base {
+	case 16:
+		return ascii.IsHex, "expected a hexadecimal digit"
+	case 8:
+		return ascii.IsOctal, "expected an octal digit"
+	case 2:
+		return ascii.IsBinary, "expected a binary digit"
+	default:
+		return ascii.IsDigit, "expected a digit"
+	}
+}
+
+func peekFilter(state *State, filter ascii.Filter) bool {
+	c, err := Next(state)
+	return err == nil && filter(c)
+}
+
+// scanDigits will append at least one digit matching the filter to the given
+// byte slice. Underscores are skipped if the format allows them, but each one
+// must be followed by another digit.
+func scanDigits(state *State, p []byte, filter ascii.Filter, what string, format NumberFormat) ([]byte, error) {
+	c, err := Next(state)
+	if err != nil || !filter(c) {
+		return p, NewError(what, state.Position())
+	}
+	for err == nil {
+		switch {
+		case filter(c):
+			p = append(p, c)
+		case c == '_' && format&Underscores != 0:
+			state.Advance()
+			if c, err = Next(state); err != nil || !filter(c) {
+				return p, NewError(what+" after `_`", state.Position())
+			}
+			continue
+		default:
+			return p, nil
+		}
+		state.Advance()
+		c, err = Next(state)
+	}
+	return p, nil
+}
+
+func scanSign(state *State, p []byte) []byte {
+	if c, err := Next(state); err == nil && (c == '-' || c == '+') {
+		state.Advance()
+		p = append(p, c)
+	}
+	return p
+}
+
+// scanInteger will scan an integer and return its digits without any prefix
+// or separators along with the base of the digits.
+func scanInteger(state *State, format NumberFormat, signed bool) ([]byte, int, error) {
+	p := []byte{}
+	if signed {
+		p = scanSign(state, p)
+	}
+
+	base := 10
+	if format&BasePrefixes != 0 && state.Request(2) == nil {
+		if q := state.Buffer(); q[0] == '0' {
+			if base = prefixBase(q[1], format); base != 10 {
+				state.Advance()
+			}
+		}
+	}
+
+	filter, what := baseFilter(base)
+	p, err := scanDigits(state, p, filter, what, format)
+	return p, base, err
+}
+
+// scanFloat will scan a decimal floating point number and return its bytes
+// without any separators.
+func scanFloat(state *State, format NumberFormat) ([]byte, error) {
+	p := scanSign(state, []byte{})
+
+	p, err := scanDigits(state, p, ascii.IsDigit, "expected a digit", format)
+	if err != nil {
+		return p, err
+	}
+
+	// Process the fraction part only if a digit follows the point.
+	if c, err := Next(state); err == nil && c == '.' {
+		state.Push()
+		state.Advance()
+		if !peekFilter(state, ascii.IsDigit) {
+			state.Pop()
+			return p, nil
+		}
+		state.Drop()
+		p, err = scanDigits(state, append(p, '.'), ascii.IsDigit, "expected a digit", format)
+		if err != nil {
+			return p, err
+		}
+	}
+
+	// Process the exponent part only if a digit follows the optional sign.
+	if c, err := Next(state); err == nil && (c == 'e' || c == 'E') {
+		state.Push()
+		state.Advance()
+		q := scanSign(state, append(p, c))
+		if !peekFilter(state, ascii.IsDigit) {
+			state.Pop()
+			return p, nil
+		}
+		state.Drop()
+		p, err = scanDigits(state, q, ascii.IsDigit, "expected a digit", format)
+		if err != nil {
+			return p, err
+		}
+	}
+
+	return p, nil
+}
+
+func checkBitSize(bitSize int) {
+	switch bitSize {
+	case 0, 8, 16, 32, 64:
+	default:
+		panic(fmt.Errorf("invalid bit size %d", bitSize))
+	}
+}
+
+func describeError(err error, what string) string {
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Sprintf("value out of range for %s", what)
+	}
+	return fmt.Sprintf("invalid syntax for %s", what)
+}
+
+func sizedInt(n int64, bitSize int) interface{} {
+	switch bitSize {
+	case 8:
+		return int8(n)
+	case 16:
+		return int16(n)
+	case 32:
+		return int32(n)
+	case 64:
+		return n
+	default:
+		return int(n)
+	}
+}
+
+func sizedUint(n uint64, bitSize int) interface{} {
+	switch bitSize {
+	case 8:
+		return uint8(n)
+	case 16:
+		return uint16(n)
+	case 32:
+		return uint32(n)
+	case 64:
+		return n
+	default:
+		return uint(n)
+	}
+}
+
+func typeName(prefix string, bitSize int) string {
+	if bitSize == 0 {
+		return prefix
+	}
+	return fmt.Sprintf("%s%d", prefix, bitSize)
+}
+
+// numberParser creates a Parser which scans a number using the scan function
+// and then converts it to a value of the named type using the convert
+// function. The state is restored to the position prior to matching if either
+// of the functions fail.
+func numberParser(name, what string, scan func(*State) ([]byte, int, error), convert func([]byte, int) (interface{}, error)) Parser {
+	return describe(&Node{Kind: KindPrimitive, Name: name}, func(state *State, result *Result) error {
+		if _, err := Next(state); err != nil {
+			return NewNestedError(name, err)
+		}
+
+		state.Push()
+		p, base, err := scan(state)
+		if err != nil {
+			state.Pop()
+			return NewNestedError(name, err)
+		}
+
+		v, err := convert(p, base)
+		if err != nil {
+			state.Pop()
+			return NewError(describeError(err, what), state.Position())
+		}
+
+		state.Drop()
+		result.SetValue(v)
+		return nil
+	})
+}
+
+func integerScanner(format NumberFormat, signed bool) func(*State) ([]byte, int, error) {
+	return func(state *State) ([]byte, int, error) {
+		return scanInteger(state, format, signed)
+	}
+}
+
+func floatScanner(format NumberFormat) func(*State) ([]byte, int, error) {
+	return func(state *State) ([]byte, int, error) {
+		p, err := scanFloat(state, format)
+		return p, 10, err
+	}
+}
+
+// Integer creates a Parser which will match a signed integer in the given
+// format and convert it to a Go integer of the given bit size. A bit size of
+// 8, 16, 32, or 64 will yield an int8, int16, int32, or int64 respectively
+// and a bit size of 0 will yield an int. An integer is defined to be as
+// follows in EBNF, where the prefixes and separators are subject to the
+// format:
+//
+//   sign    = `+` | `-`
+//   prefix  = `0x` | `0X` | `0o` | `0O` | `0b` | `0B`
+//   digits  = digit, { [ `_` ], digit }
+//   integer = [ sign ], [ prefix ], digits
+//
+// Unlike Int, leading zeros are matched as a part of a decimal integer. If the
+// matched integer cannot be represented in the given bit size, the Parser will
+// return an error and backtrack to where it started matching.
+func Integer(bitSize int, format NumberFormat) Parser {
+	checkBitSize(bitSize)
+	name := fmt.Sprintf("Integer(%d)", bitSize)
+	what := typeName("int", bitSize)
+
+	return numberParser(name, what, integerScanner(format, true), func(p []byte, base int) (interface{}, error) {
+		n, err := strconv.ParseInt(string(p), base, bitSize)
+		return sizedInt(n, bitSize), err
+	})
+}
+
+// Unsigned creates a Parser which will match an unsigned integer in the given
+// format and convert it to a Go unsigned integer of the given bit size. The
+// syntax is identical to that of Integer except that a sign is not allowed.
+func Unsigned(bitSize int, format NumberFormat) Parser {
+	checkBitSize(bitSize)
+	name := fmt.Sprintf("Unsigned(%d)", bitSize)
+	what := typeName("uint", bitSize)
+
+	return numberParser(name, what, integerScanner(format, false), func(p []byte, base int) (interface{}, error) {
+		n, err := strconv.ParseUint(string(p), base, bitSize)
+		return sizedUint(n, bitSize), err
+	})
+}
+
+// Float creates a Parser which will match a decimal floating point number and
+// convert it to a float32 or float64 for a bit size of 32 or 64 respectively.
+// A floating point number is defined to be as follows in EBNF, where the
+// separators are subject to the format and the base prefixes are ignored:
+//
+//   sign     = `+` | `-`
+//   digits   = digit, { [ `_` ], digit }
+//   fraction = `.`, digits
+//   exponent = ( `e` | `E` ), [ sign ], digits
+//   float    = [ sign ], digits, [ fraction ], [ exponent ]
+//
+// Similar to Number, a trailing `.` or exponent without digits is not a part
+// of the match.
+func Float(bitSize int, format NumberFormat) Parser {
+	if bitSize != 32 && bitSize != 64 {
+		panic(fmt.Errorf("invalid bit size %d", bitSize))
+	}
+	name := fmt.Sprintf("Float(%d)", bitSize)
+	what := typeName("float", bitSize)
+
+	return numberParser(name, what, floatScanner(format), func(p []byte, base int) (interface{}, error) {
+		f, err := strconv.ParseFloat(string(p), bitSize)
+		if bitSize == 32 {
+			return float32(f), err
+		}
+		return f, err
+	})
+}
+
+// BigInt creates a Parser which will match an integer with the same syntax as
+// Integer and convert it to a *big.Int of arbitrary size.
+func BigInt(format NumberFormat) Parser {
+	return numberParser("BigInt", "big.Int", integerScanner(format, true), func(p []byte, base int) (interface{}, error) {
+		n, ok := new(big.Int).SetString(string(p), base)
+		if !ok {
+			return nil, strconv.ErrSyntax
+		}
+		return n, nil
+	})
+}
+
+// BigFloat creates a Parser which will match a floating point number with the
+// same syntax as Float and convert it to a *big.Float with the given mantissa
+// precision. A precision of 0 will default to 64 bits.
+func BigFloat(prec uint, format NumberFormat) Parser {
+	return numberParser("BigFloat", "big.Float", floatScanner(format), func(p []byte, base int) (interface{}, error) {
+		f, _, err := big.ParseFloat(string(p), base, prec, big.ToNearestEven)
+		return f, err
+	})
+}
+
+// MaxRatExponent is the largest magnitude of a decimal exponent accepted by
+// BigRat. An exact value is computed from the exponent, so the size of the
+// value and the time taken to compute it grow with the exponent rather than
+// the length of the input.
+const MaxRatExponent = 10000
+
+// ratExponent checks that the exponent of a scanned floating point number
+// does not exceed MaxRatExponent.
+func ratExponent(p []byte) error {
+	i := bytes.IndexAny(p, "eE")
+	if i < 0 {
+		return nil
+	}
+	exp := strings.TrimPrefix(string(p[i+1:]), "+")
+	if n, err := strconv.Atoi(exp); err != nil || n < -MaxRatExponent || MaxRatExponent < n {
+		return strconv.ErrRange
+	}
+	return nil
+}
+
+// BigRat creates a Parser which will match a floating point number with the
+// same syntax as Float and convert it to an exact *big.Rat value. A number
+// with an exponent beyond MaxRatExponent is reported as out of range.
+func BigRat(format NumberFormat) Parser {
+	return numberParser("BigRat", "big.Rat", floatScanner(format), func(p []byte, base int) (interface{}, error) {
+		if err := ratExponent(p); err != nil {
+			return nil, err
+		}
+		r, ok := new(big.Rat).SetString(string(p))
+		if !ok {
+			return nil, strconv.ErrSyntax
+		}
+		return r, nil
+	})
+}
@@ -0,0 +1,93 @@
+package pars
+
+import (
+	"runtime"
+	"sync"
+)
+
+// chunk is a range of the input to be parsed by ParseChunks.
+type chunk struct {
+	start int
+	end   int
+	pos   Position
+}
+
+// splitChunks splits the given bytes at each non-empty match of the given
+// delimiter Parser. Empty chunks are omitted.
+func splitChunks(p []byte, d Parser) []chunk {
+	chunks := []chunk{}
+	state := FromBytes(p)
+	current := chunk{}
+	void := Result{}
+	for state.Request(1) == nil {
+		off := state.Offset()
+		state.Push()
+		if d(state, &void) == nil && state.Offset() > off {
+			state.Drop()
+			if current.end = off; current.start < current.end {
+				chunks = append(chunks, current)
+			}
+			current = chunk{start: state.Offset(), pos: state.Position()}
+			continue
+		}
+		state.Pop()
+		Skip(state, 1)
+	}
+	if current.start < len(p) {
+		current.end = len(p)
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// ParseChunks splits the given bytes at each match of the delimiter Parser
+// and parses each chunk in between with the given Parser, which must match
+// the entire chunk. Empty chunks, such as blank lines between line delimited
+// records, are skipped and do not yield a result. The chunks are parsed in
+// parallel by the given number of goroutines, or by runtime.GOMAXPROCS(0)
+// goroutines if the number is not positive. The results are returned in the
+// order of the chunks. The positions of the chunks, and thus the positions of
+// any errors, are relative to the beginning of the given bytes. If any of the
+// chunks fail to parse, the error of the first such chunk is returned.
+//
+// The delimiter must not appear within a chunk, so it is best suited for
+// inputs such as line delimited records. The Tokens of the results refer to
+// the given bytes.
+func ParseChunks(p []byte, delim, q interface{}, workers int) ([]Result, error) {
+	d, parser := AsParser(delim), AsParser(q)
+	chunks := splitChunks(p, d)
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	results := make([]Result, len(chunks))
+	errs := make([]error, len(chunks))
+	indices := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				c := chunks[i]
+				state := FromBytes(p[c.start:c.end])
+				state.pos = c.pos
+				if errs[i] = parser(state, &results[i]); errs[i] == nil {
+					errs[i] = End(state, nil)
+				}
+			}
+		}()
+	}
+	for i := range chunks {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
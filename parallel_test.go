@@ -0,0 +1,90 @@
+package pars
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Run with -race to detect shared mutable state in the parsers.
+func TestConcurrent(t *testing.T) {
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, tt := range parserTests {
+				parser := AsParser(tt.val)
+				for _, tp := range tt.pass {
+					result, err := parser.Parse(FromString(tp.in))
+					if err != nil {
+						t.Errorf("%s: parser(%q): %v", tt.name, tp.in, err)
+						continue
+					}
+					compareResults(t, result, *tp.out)
+				}
+				for _, tf := range tt.fail {
+					if parser(FromString(tf), &Result{}) == nil {
+						t.Errorf("%s: parser(%q): expected error", tt.name, tf)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestParseChunks(t *testing.T) {
+	in := []byte("a=1\nb=2\r\nc=3\n")
+	results, err := ParseChunks(in, '\n', Seq(record, End), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := []string{}
+	for _, result := range results {
+		v = append(v, result.Children[0].Value.(string))
+	}
+	if e := []string{"a:1", "b:2", "c:3"}; !same(v, e) {
+		t.Errorf("records = %v, want %v", v, e)
+	}
+
+	for _, workers := range []int{0, 1, 3} {
+		lines := make([]string, 100)
+		for i := range lines {
+			lines[i] = fmt.Sprintf("k=%d", i)
+		}
+		results, err := ParseChunks([]byte(strings.Join(lines, "\n")), EOL, record, workers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, result := range results {
+			if v, e := result.Value, fmt.Sprintf("k:%d", i); v != e {
+				t.Errorf("workers %d: result %d = %v, want %v", workers, i, v, e)
+			}
+		}
+	}
+
+	_, err = ParseChunks([]byte("a=1;b=x;c=y"), ';', Seq(Byte(), '=', Int), 2)
+	var pe Error
+	if !errors.As(err, &pe) || pe.Position() != (Position{0, 6}) {
+		t.Errorf("ParseChunks() = %v, want error at %v", err, Position{0, 6})
+	}
+
+	_, err = ParseChunks([]byte("a\nbb\n"), '\n', 'a', 1)
+	if !errors.As(err, &pe) || pe.Position() != (Position{1, 0}) {
+		t.Errorf("ParseChunks() = %v, want error at %v", err, Position{1, 0})
+	}
+
+	// Empty chunks anywhere in the input are skipped.
+	results, err = ParseChunks([]byte("\na=1\n\n\nb=2\n\n"), '\n', record, 2)
+	if err != nil || len(results) != 2 || results[1].Value != "b:2" {
+		t.Errorf("ParseChunks() = %v, %v, want 2 results", results, err)
+	}
+
+	results, err = ParseChunks(nil, '\n', record, 1)
+	if err != nil || len(results) != 0 {
+		t.Errorf("ParseChunks(nil) = %v, %v", results, err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"reflect"
 	"strings"
 	"testing"
@@ -260,6 +261,146 @@ func TestState(t *testing.T) {
 
 		s.Clear()
 	})
+
+	t.Run("Read Size", func(t *testing.T) {
+		s := NewStateSize(bytes.NewBuffer(e), 16)
+		if err := s.Request(5); err != nil {
+			t.Errorf("s.Request(5): %v", err)
+			return
+		}
+		if !compareBytes(t, s.Dump(), e[:16]) {
+			return
+		}
+		if err := s.Request(40); err != nil {
+			t.Errorf("s.Request(40): %v", err)
+			return
+		}
+		compareBytes(t, s.Buffer(), e[:40])
+	})
+
+	t.Run("Request Beyond Input", func(t *testing.T) {
+		s := NewStateSize(bytes.NewBuffer(e[:40]), 16)
+		if err := s.Request(1 << 30); err != io.EOF {
+			t.Errorf("s.Request(1 << 30) = %v, want io.EOF", err)
+			return
+		}
+		if !compareBytes(t, s.Buffer(), e[:40]) {
+			return
+		}
+		if c := cap(s.buf); c > 2*40+16 {
+			t.Errorf("cap(s.buf) = %d, want at most %d", c, 2*40+16)
+		}
+	})
+
+	t.Run("Value Methods", func(t *testing.T) {
+		s := FromString(hello)
+		s.Request(5)
+		v := *s
+		if string(v.Buffer()) != hello[:5] || string(v.Dump()) != hello || v.Offset() != 0 || v.Position() != (Position{0, 0}) || v.Pushed() {
+			t.Errorf("methods of a State value do not match the state")
+		}
+	})
+
+	t.Run("Commit", func(t *testing.T) {
+		s := NewStateSize(bytes.NewBuffer(e), 16)
+		s.Push()
+		Skip(s, 3)
+		s.Push()
+		Skip(s, 5)
+		s.Commit()
+		Skip(s, 2)
+
+		// Backtracking stops at the commit point.
+		s.Pop()
+		if s.Position() != (Position{0, 8}) {
+			t.Errorf("s.Position() = %v, want %v", s.Position(), Position{0, 8})
+		}
+		s.Pop()
+		if s.Position() != (Position{0, 8}) {
+			t.Errorf("s.Position() = %v, want %v", s.Position(), Position{0, 8})
+		}
+		if !compareBytes(t, s.Dump(), e[8:16]) {
+			return
+		}
+
+		// The bytes before the commit point are not kept when reading more.
+		s.Push()
+		if err := s.Request(40); err != nil {
+			t.Errorf("s.Request(40): %v", err)
+			return
+		}
+		if !compareBytes(t, s.Buffer(), e[8:48]) {
+			return
+		}
+		if n := len(s.buf); n > 40+16 {
+			t.Errorf("len(s.buf) = %d, want at most %d", n, 40+16)
+		}
+		s.Pop()
+		if s.Position() != (Position{0, 8}) {
+			t.Errorf("s.Position() = %v, want %v", s.Position(), Position{0, 8})
+		}
+	})
+
+	t.Run("Bounded", func(t *testing.T) {
+		s := NewStateSize(bytes.NewBuffer(e), 64)
+		p, q := make([]byte, 1), make([]byte, 64)
+		for i := 0; i < len(e); i++ {
+			s.Push()
+			s.Read(p)
+			s.Drop()
+			if c := cap(s.buf); c > 2*64 {
+				t.Errorf("cap(s.buf) = %d after %d bytes", c, i)
+				return
+			}
+		}
+		if n, err := s.Read(q); n != 0 || err != io.EOF {
+			t.Errorf("s.Read(q) = %d, %v, want 0, io.EOF", n, err)
+		}
+	})
+
+	t.Run("Compact", func(t *testing.T) {
+		s := NewStateSize(bytes.NewBuffer(e), 16)
+		Skip(s, 16)
+		s.Clear()
+		c := cap(s.buf)
+		s.Compact()
+		if err := s.Request(16); err != nil {
+			t.Errorf("s.Request(16): %v", err)
+			return
+		}
+		if !compareBytes(t, s.Buffer(), e[16:32]) {
+			return
+		}
+		if cap(s.buf) != c {
+			t.Errorf("cap(s.buf) = %d, want %d", cap(s.buf), c)
+		}
+
+		// A state created from bytes never modifies them.
+		p := []byte(hello)
+		s = FromBytes(p)
+		Skip(s, 6)
+		s.Clear()
+		s.Compact()
+		if string(p) != hello || string(s.Dump()) != hello[6:] {
+			t.Errorf("FromBytes state modified by Compact")
+		}
+	})
+
+	t.Run("InputOffset", func(t *testing.T) {
+		s := NewStateSize(bytes.NewBuffer(e), 16)
+		Skip(s, 20)
+		s.Clear()
+		s.Compact()
+		s.Push()
+		Skip(s, 5)
+		if s.Offset() != 5 || s.InputOffset() != 25 {
+			t.Errorf("s.Offset(), s.InputOffset() = %d, %d, want 5, 25", s.Offset(), s.InputOffset())
+		}
+		s.Pop()
+		if s.InputOffset() != 20 {
+			t.Errorf("s.InputOffset() = %d, want 20", s.InputOffset())
+		}
+	})
 }
 
 func TestBasic(t *testing.T) {
@@ -269,8 +410,8 @@ func TestBasic(t *testing.T) {
 			if err := s.Request(1); err != nil {
 				t.Errorf("s.Request(1): %v", err)
 			}
-			if err := Epsilon(s, Void); err != nil {
-				t.Errorf("Epsilon(s, Void): %v", err)
+			if err := Epsilon(s, &Result{}); err != nil {
+				t.Errorf("Epsilon(s, &Result{}): %v", err)
 			}
 			s.Advance()
 		}
@@ -284,13 +425,13 @@ func TestBasic(t *testing.T) {
 			}
 			switch i {
 			case 0:
-				if err := Head(s, Void); err != nil {
-					t.Errorf("Head(s, Void): %v", err)
+				if err := Head(s, &Result{}); err != nil {
+					t.Errorf("Head(s, &Result{}): %v", err)
 				}
 			default:
 				e := NewError("state is not at head", s.Position())
-				if err := Head(s, Void); !same(err, e) {
-					t.Errorf("Head(s, Void) = `%v`, wanted `%v`", err, e)
+				if err := Head(s, &Result{}); !same(err, e) {
+					t.Errorf("Head(s, &Result{}) = `%v`, wanted `%v`", err, e)
 				}
 			}
 			s.Advance()
@@ -304,13 +445,13 @@ func TestBasic(t *testing.T) {
 				t.Errorf("s.Request(1): %v", err)
 			}
 			e := NewError("state is not at end", s.Position())
-			if err := End(s, Void); !same(err, e) {
-				t.Errorf("End(s, Void) = `%v`, wanted `%v`", err, e)
+			if err := End(s, &Result{}); !same(err, e) {
+				t.Errorf("End(s, &Result{}) = `%v`, wanted `%v`", err, e)
 			}
 			s.Advance()
 		}
-		if err := End(s, Void); err != nil {
-			t.Errorf("End(s, Void): %v", err)
+		if err := End(s, &Result{}); err != nil {
+			t.Errorf("End(s, &Result{}): %v", err)
 		}
 	})
 
@@ -320,8 +461,8 @@ func TestBasic(t *testing.T) {
 			if err := s.Request(1); err != nil {
 				t.Errorf("s.Request(1): %v", err)
 			}
-			if err := Cut(s, Void); err != nil {
-				t.Errorf("Cut(s, Void): %v", err)
+			if err := Cut(s, &Result{}); err != nil {
+				t.Errorf("Cut(s, &Result{}): %v", err)
 			}
 			s.Advance()
 		}
@@ -476,6 +617,11 @@ var parserTests = []struct {
 	}, {
 		"Any(Seq(Cut, End))", Any(Seq(Cut, End).Bind(nil)),
 		[]testPair{{"", &Result{}}}, []string{hello, small, large},
+	}, {
+		"Seq(Not(`Small`), Byte())", Seq(Not(`Small`), Byte()).Child(1), []testPair{
+			{hello, AsResult(hello[0])},
+			{large, AsResult(large[0])},
+		}, []string{"", small},
 	}, {
 		"Maybe(`Hello`)", Maybe(`Hello`), []testPair{
 			{hello, AsResult(hello[:5])},
@@ -544,6 +690,56 @@ var parserTests = []struct {
 			"1" + strings.Repeat("0", 309) + "e-a",
 			"1.7976931348623159e308",
 		},
+	}, {
+		"Integer(0, Decimal)", Integer(0, Decimal), []testPair{
+			{"0", AsResult(0)},
+			{"012", AsResult(12)},
+			{"-42", AsResult(-42)},
+			{"+42", AsResult(42)},
+			{"0x42", AsResult(0)},
+			{"1_000", AsResult(1)},
+		}, []string{"", hello, "-", "+x", "9223372036854775808"},
+	}, {
+		"Integer(8, FullSyntax)", Integer(8, FullSyntax), []testPair{
+			{"127", AsResult(int8(127))},
+			{"-128", AsResult(int8(-128))},
+			{"0x7f", AsResult(int8(127))},
+			{"-0X80", AsResult(int8(-128))},
+			{"0o17", AsResult(int8(15))},
+			{"0b1010", AsResult(int8(10))},
+			{"1_0", AsResult(int8(10))},
+		}, []string{"", "128", "-129", "0x80", "0xg", "0x_1", "1_", "1__0"},
+	}, {
+		"Int64", Int64, []testPair{
+			{"0xFFFF_FFFF", AsResult(int64(0xffffffff))},
+			{"-9223372036854775808", AsResult(int64(-9223372036854775808))},
+		}, []string{"9223372036854775808"},
+	}, {
+		"Uint64", Uint64, []testPair{
+			{"18446744073709551615", AsResult(uint64(18446744073709551615))},
+			{"0xdead_beef", AsResult(uint64(0xdeadbeef))},
+		}, []string{"-1", "+1", "18446744073709551616"},
+	}, {
+		"Unsigned(16, HexPrefix)", Unsigned(16, HexPrefix), []testPair{
+			{"0xffff", AsResult(uint16(0xffff))},
+			{"0o17", AsResult(uint16(0))},
+		}, []string{"0x10000", "0x_1"},
+	}, {
+		"Float(64, Underscores)", Float(64, Underscores), []testPair{
+			{"0", AsResult(0.0)},
+			{"-1.5", AsResult(-1.5)},
+			{"1_000.000_1", AsResult(1000.0001)},
+			{"1e1_0", AsResult(1e10)},
+			{"1.", AsResult(1.0)},
+			{"1.e", AsResult(1.0)},
+			{"1e+", AsResult(1.0)},
+			{"1._0", AsResult(1.0)},
+		}, []string{"", hello, ".5", "1_", "1.0_", "1e309"},
+	}, {
+		"Float(32, Decimal)", Float(32, Decimal), []testPair{
+			{"0.5", AsResult(float32(0.5))},
+			{"1_0", AsResult(float32(1))},
+		}, []string{"1e39"},
 	}, {
 		"Between('(', ')')", Between('(', ')'), []testPair{
 			{"(" + hello + ")", AsResult([]byte(hello))},
@@ -702,7 +898,7 @@ func TestParsers(t *testing.T) {
 
 			for _, tf := range tt.fail {
 				state := FromString(tf)
-				if parser(state, Void) == nil {
+				if parser(state, &Result{}) == nil {
 					t.Errorf("parser(%q): expected error", tf)
 					return
 				}
@@ -724,6 +920,25 @@ func TestParserLazy(t *testing.T) {
 	compareResults(t, result, *AsResult(hello))
 }
 
+func TestNested(t *testing.T) {
+	var parens Parser
+	parens = Nested(Any('x', Seq('(', &parens, ')')), 3)
+	parser := Exact(parens)
+
+	for _, in := range []string{"x", "(x)", "((x))"} {
+		if _, err := parser.Parse(FromString(in)); err != nil {
+			t.Errorf("parser(%q): %v", in, err)
+		}
+	}
+
+	in := "(((x)))"
+	_, err := parser.Parse(FromString(in))
+	var pe Error
+	if !errors.As(err, &pe) || pe.Position() != (Position{0, 3}) {
+		t.Errorf("parser(%q) = %v, want error at %v", in, err, Position{0, 3})
+	}
+}
+
 var panicTests = []struct {
 	name string
 	fn   func()
@@ -735,6 +950,9 @@ var panicTests = []struct {
 	{"RuneRange('A', 'A')", func() { RuneRange('A', 'A') }},
 	{"RuneRange('Z', 'A')", func() { RuneRange('Z', 'A') }},
 	{"Until([]byte{})", func() { Until([]byte{}) }},
+	{"Integer(7, Decimal)", func() { Integer(7, Decimal) }},
+	{"Unsigned(128, Decimal)", func() { Unsigned(128, Decimal) }},
+	{"Float(16, Decimal)", func() { Float(16, Decimal) }},
 }
 
 func TestPanic(t *testing.T) {
@@ -794,6 +1012,10 @@ func TestParserError(t *testing.T) {
 			t.Errorf("err.Error() = %q, want %q", v, e)
 			return
 		}
+		if v := err.(Error).Position(); v != (Position{0, 0}) {
+			t.Errorf("err.Position() = %v, want %v", v, Position{0, 0})
+			return
+		}
 	})
 
 	t.Run("NestedError", func(t *testing.T) {
@@ -810,6 +1032,128 @@ func TestParserError(t *testing.T) {
 	})
 }
 
+func TestCapture(t *testing.T) {
+	parser := Seq(
+		Capture("key", Word(ascii.IsLetter)),
+		Spaces, '=', Spaces,
+		Capture("value", Int),
+	)
+
+	in := "answer = 42"
+	result, err := parser.Parse(FromString(in))
+	if err != nil {
+		t.Errorf("parser(%q): %v", in, err)
+		return
+	}
+
+	if v := result.Get("key"); v == nil || !compareResults(t, *v, *AsResult([]byte("answer"))) {
+		t.Errorf("result.Get(%q) = %v", "key", v)
+	}
+	if v := result.Get("missing"); v != nil {
+		t.Errorf("result.Get(%q) = %v, want nil", "missing", v)
+	}
+
+	named := result.Named()
+	if len(named) != 2 || named["value"] != result.Get("value") {
+		t.Errorf("result.Named() = %v", named)
+	}
+
+	t.Run("Field", func(t *testing.T) {
+		result, err := parser.Field("value").Parse(FromString(in))
+		if err != nil {
+			t.Errorf("parser(%q): %v", in, err)
+			return
+		}
+		compareResults(t, result, *AsResult(42))
+		if result.Name != "value" {
+			t.Errorf("result.Name = %q, want %q", result.Name, "value")
+		}
+	})
+
+	t.Run("Children", func(t *testing.T) {
+		result, err := parser.Children(4, 0).Parse(FromString(in))
+		if err != nil {
+			t.Errorf("parser(%q): %v", in, err)
+			return
+		}
+		if v := result.Get("value"); v == nil || v != &result.Children[0] {
+			t.Errorf("result.Get(%q) = %v", "value", v)
+		}
+	})
+
+	t.Run("missing Field", func(t *testing.T) {
+		if _, err := parser.Field("missing").Parse(FromString(in)); err == nil {
+			t.Errorf("expected error")
+		}
+		if _, err := Byte().Field("missing").Parse(FromString(in)); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+}
+
+func TestBigNumbers(t *testing.T) {
+	t.Run("BigInt", func(t *testing.T) {
+		in := "-0xffff_ffff_ffff_ffff_ffff_ffff_ffff_ffff"
+		e, _ := new(big.Int).SetString("-ffffffffffffffffffffffffffffffff", 16)
+		result, err := BigInt(FullSyntax).Parse(FromString(in))
+		if err != nil {
+			t.Errorf("parser(%q): %v", in, err)
+			return
+		}
+		if v := result.Value.(*big.Int); v.Cmp(e) != 0 {
+			t.Errorf("parser(%q) = %v, want %v", in, v, e)
+		}
+	})
+
+	t.Run("BigFloat", func(t *testing.T) {
+		in := "1_234.5e-1"
+		e := big.NewFloat(123.45)
+		result, err := BigFloat(53, Underscores).Parse(FromString(in))
+		if err != nil {
+			t.Errorf("parser(%q): %v", in, err)
+			return
+		}
+		if v := result.Value.(*big.Float); v.Cmp(e) != 0 {
+			t.Errorf("parser(%q) = %v, want %v", in, v, e)
+		}
+	})
+
+	t.Run("BigRat", func(t *testing.T) {
+		in := "0.1e1000"
+		e, _ := new(big.Rat).SetString("1" + strings.Repeat("0", 999))
+		result, err := BigRat(Decimal).Parse(FromString(in))
+		if err != nil {
+			t.Errorf("parser(%q): %v", in, err)
+			return
+		}
+		if v := result.Value.(*big.Rat); v.Cmp(e) != 0 {
+			t.Errorf("parser(%q) = %v, want %v", in, v, e)
+		}
+
+		for _, in := range []string{"1e99999999", "1e-10001", "1e99999999999999999999"} {
+			_, err := BigRat(Decimal).Parse(FromString(in))
+			e := NewError("value out of range for big.Rat", Position{})
+			if !same(err, e) {
+				t.Errorf("parser(%q) = `%v`, want `%v`", in, err, e)
+			}
+		}
+	})
+}
+
+func TestNumberOverflow(t *testing.T) {
+	state := FromString("[300]")
+	Skip(state, 1)
+	_, err := Integer(8, Decimal).Parse(state)
+	e := NewError("value out of range for int8", Position{0, 1})
+	if !same(err, e) {
+		t.Errorf("parser(%q) = `%v`, want `%v`", "300", err, e)
+		return
+	}
+	if state.Position() != (Position{0, 1}) {
+		t.Errorf("state.Position() = %v, want %v", state.Position(), Position{0, 1})
+	}
+}
+
 func TestTimeMapping(t *testing.T) {
 	e := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
 	layout := "Mon Jan 2 15:04:05 -0700 MST 2006"
@@ -838,3 +1182,106 @@ func TestTimeMapping(t *testing.T) {
 		t.Errorf("expected error")
 	}
 }
+
+var allocTests = []struct {
+	name   string
+	p      Parser
+	in     string
+	allocs float64
+}{
+	{"Byte", Byte(), "a", 0},
+	{"Byte(a)", Byte('a'), "a", 0},
+	{"Byte(a, b)", Byte('a', 'b'), "b", 0},
+	{"ByteRange", Digit, "5", 0},
+	{"String", String("hello"), "hello", 0},
+	{"Rune", Rune('ä'), "ä", 0},
+	{"Filter", Filter(ascii.IsLetter), "a", 0},
+	{"Word", Word(ascii.IsLetter), "hello", 0},
+	{"EOL", EOL, "\r\n", 0},
+	{"Line", Line, "hello\nworld", 0},
+	{"Seq", Seq('a', 'b', 'c'), "abc", 1},
+	{"Maybe", Maybe(Seq('a', 'b')), "ac", 3},
+}
+
+var nop Parser = func(state *State, result *Result) error { return nil }
+
+func TestAllocs(t *testing.T) {
+	run := func(p Parser, in []byte) float64 {
+		return testing.AllocsPerRun(100, func() {
+			p(FromBytes(in), &Result{})
+		})
+	}
+
+	// Subtract the allocations for creating the state and result.
+	base := run(nop, nil)
+
+	for _, tt := range allocTests {
+		if n := run(tt.p, []byte(tt.in)) - base; n > tt.allocs {
+			t.Errorf("%s: %v allocations, want %v", tt.name, n, tt.allocs)
+		}
+	}
+}
+
+func TestTokenAlias(t *testing.T) {
+	p := []byte("ab\r\n")
+	state := FromBytes(p)
+	result, _ := Seq(Byte(), Digit.Error(io.EOF), EOL).Parse(state)
+	if result.Children != nil {
+		t.Fatalf("expected failure")
+	}
+	result, _ = Seq(Byte(), Byte(), EOL).Parse(state)
+	for i, e := range []int{0, 1, 2} {
+		token := result.Children[i].Token
+		if &token[0] != &p[e] {
+			t.Errorf("token %d does not alias the input", i)
+		}
+		if cap(token) != len(token) {
+			t.Errorf("cap(token %d) = %d, want %d", i, cap(token), len(token))
+		}
+	}
+}
+
+func TestManyEmptyMatch(t *testing.T) {
+	// An empty first match yields empty Children rather than its own result.
+	result, err := Many(Parser(Epsilon).Bind(42)).Parse(FromString("abc"))
+	if err != nil || result.Children == nil || len(result.Children) != 0 || result.Value != nil {
+		t.Errorf("Many(Epsilon) = %#v, %v, want empty Children", result, err)
+	}
+
+	// An empty match after consuming input stops the loop and is not included.
+	state := FromString("aab")
+	result, err = Many(Maybe('a').Bind("a")).Parse(state)
+	if err != nil || len(result.Children) != 2 {
+		t.Errorf("Many(Maybe('a')) = %#v, %v, want 2 children", result, err)
+	}
+	if e := (Position{Line: 0, Byte: 2}); state.Position() != e {
+		t.Errorf("state.Position() = %v, want %v", state.Position(), e)
+	}
+
+	result, err = Many(Line).Parse(FromString("a\nb"))
+	if err != nil || len(result.Children) != 2 {
+		t.Errorf("Many(Line) = %#v, %v, want 2 children", result, err)
+	}
+}
+
+func BenchmarkParsers(b *testing.B) {
+	in := []byte(strings.Repeat(hello+"\n", 64))
+	benchmarks := []struct {
+		name string
+		p    Parser
+	}{
+		{"Byte", Many(Byte())},
+		{"String", Many(Seq(hello, EOL))},
+		{"Word", Many(Seq(Word(ascii.IsLetter), Maybe('!'), Spaces))},
+		{"Line", Many(Line)},
+	}
+	for _, bb := range benchmarks {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(in)))
+			for i := 0; i < b.N; i++ {
+				bb.p(FromBytes(in), &Result{})
+			}
+		})
+	}
+}
@@ -14,7 +14,9 @@ type Map func(result *Result) error
 
 // Map applies the callback if the parser matches.
 func (p Parser) Map(f Map) Parser {
-	return func(state *State, result *Result) error {
+	n := &Node{Kind: KindMap, children: []Parser{p}}
+	p = direct(p)
+	return describe(n, func(state *State, result *Result) error {
 		state.Push()
 		if err := p(state, result); err != nil {
 			state.Pop()
@@ -22,12 +24,15 @@ func (p Parser) Map(f Map) Parser {
 		}
 		state.Drop()
 		return f(result)
-	}
+	})
 }
 
 // Child will map to the i'th child of the result.
 func (p Parser) Child(i int) Parser { return p.Map(Child(i)) }
 
+// Field will map to the child captured with the given name.
+func (p Parser) Field(name string) Parser { return p.Map(Field(name)) }
+
 // Children will keep the children associated to the given indices.
 func (p Parser) Children(indices ...int) Parser {
 	return p.Map(Children(indices...))
@@ -38,24 +43,28 @@ func (p Parser) ToString() Parser { return p.Map(ToString) }
 
 // Bind will bind the given value as the parser result value.
 func (p Parser) Bind(v interface{}) Parser {
-	return func(state *State, result *Result) error {
+	n := &Node{Kind: KindMap, children: []Parser{p}}
+	p = direct(p)
+	return describe(n, func(state *State, result *Result) error {
 		if err := p(state, result); err != nil {
 			return err
 		}
 		result.SetValue(v)
 		return nil
-	}
+	})
 }
 
 // Error will modify the Parser to return the given error if the Parser returns
 // an error.
 func (p Parser) Error(alt error) Parser {
-	return func(state *State, result *Result) error {
+	n := &Node{Kind: KindMap, children: []Parser{p}}
+	p = direct(p)
+	return describe(n, func(state *State, result *Result) error {
 		if err := p(state, result); err != nil {
 			return BoundError{alt, state.Position()}
 		}
 		return nil
-	}
+	})
 }
 
 // Parse the given state using the parser and return the Result.
@@ -73,9 +82,9 @@ func AsParser(q interface{}) Parser {
 	case func(*State, *Result) error:
 		return p
 	case *Parser:
-		return func(state *State, result *Result) error {
+		return describe(&Node{Kind: KindRef, ref: p}, func(state *State, result *Result) error {
 			return (*p)(state, result)
-		}
+		})
 	case byte:
 		return Byte(p)
 	case []byte:
@@ -0,0 +1,242 @@
+package peg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-pars/pars"
+)
+
+// Kind represents the kind of a grammar Node.
+type Kind int
+
+// Kinds of grammar nodes.
+const (
+	// Choice matches the first matching child: `a / b`.
+	Choice Kind = iota
+
+	// Sequence matches all children in order: `a b`.
+	Sequence
+
+	// And matches the child without consuming input: `&a`.
+	And
+
+	// Not matches if the child does not match: `!a`.
+	Not
+
+	// Optional matches the child zero or one times: `a?`.
+	Optional
+
+	// ZeroOrMore matches the child as many times as possible: `a*`.
+	ZeroOrMore
+
+	// OneOrMore matches the child at least once: `a+`.
+	OneOrMore
+
+	// Label names the result of the child: `name:a`.
+	Label
+
+	// Reference matches the rule with the given name: `a`.
+	Reference
+
+	// Literal matches the given text: `'a'`.
+	Literal
+
+	// Class matches a byte in the given ranges: `[a-z]`.
+	Class
+
+	// Dot matches any byte: `.`.
+	Dot
+)
+
+var kindNames = [...]string{
+	"Choice", "Sequence", "And", "Not", "Optional", "ZeroOrMore", "OneOrMore",
+	"Label", "Reference", "Literal", "Class", "Dot",
+}
+
+// String returns the name of the kind.
+func (k Kind) String() string { return kindNames[k] }
+
+// Range is an inclusive range of bytes in a character class.
+type Range struct {
+	Begin byte
+	End   byte
+}
+
+// Node is a node of the expression tree of a grammar rule.
+//   Text:     the name for Label and Reference, the unescaped text for Literal.
+//   Ranges:   the byte ranges for Class.
+//   Negated:  true if the Class is negated.
+//   Children: the operands of the node.
+type Node struct {
+	Kind     Kind
+	Pos      pars.Position
+	Text     string
+	Ranges   []Range
+	Negated  bool
+	Children []*Node
+}
+
+// Matches tests if the byte is matched by the Class node.
+func (n *Node) Matches(c byte) bool {
+	for _, r := range n.Ranges {
+		if r.Begin <= c && c <= r.End {
+			return !n.Negated
+		}
+	}
+	return n.Negated
+}
+
+// Rule is a single named rule of a grammar.
+type Rule struct {
+	Name string
+	Pos  pars.Position
+	Expr *Node
+}
+
+// Grammar is a parsed grammar text. The first rule is the start rule.
+type Grammar struct {
+	Rules []*Rule
+}
+
+// Rule returns the rule with the given name, or nil if there is none.
+func (g *Grammar) Rule(name string) *Rule {
+	for _, rule := range g.Rules {
+		if rule.Name == name {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Start returns the first rule of the grammar.
+func (g *Grammar) Start() *Rule {
+	if len(g.Rules) == 0 {
+		return nil
+	}
+	return g.Rules[0]
+}
+
+func escapeByte(c byte, quote byte) string {
+	switch {
+	case c == '\n':
+		return `\n`
+	case c == '\r':
+		return `\r`
+	case c == '\t':
+		return `\t`
+	case c == '\\' || c == quote:
+		return `\` + string(c)
+	case c < 0x20 || 0x7e < c:
+		return fmt.Sprintf(`\x%02x`, c)
+	default:
+		return string(c)
+	}
+}
+
+func precedence(k Kind) int {
+	switch k {
+	case Choice:
+		return 0
+	case Sequence:
+		return 1
+	case And, Not, Label:
+		return 2
+	case Optional, ZeroOrMore, OneOrMore:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func (n *Node) operand(i int) string {
+	child := n.Children[i]
+	if precedence(child.Kind) <= precedence(n.Kind) && child.Kind != Reference {
+		if !(child.Kind == Sequence && len(child.Children) == 0) {
+			return fmt.Sprintf("(%s)", child)
+		}
+	}
+	return child.String()
+}
+
+// String returns the node in grammar text notation.
+func (n *Node) String() string {
+	switch n.Kind {
+	case Choice, Sequence:
+		if len(n.Children) == 0 {
+			return "()"
+		}
+		sep := " "
+		if n.Kind == Choice {
+			sep = " / "
+		}
+		s := make([]string, len(n.Children))
+		for i := range n.Children {
+			s[i] = n.operand(i)
+		}
+		return strings.Join(s, sep)
+	case And:
+		return "&" + n.operand(0)
+	case Not:
+		return "!" + n.operand(0)
+	case Label:
+		return n.Text + ":" + n.operand(0)
+	case Optional:
+		return n.operand(0) + "?"
+	case ZeroOrMore:
+		return n.operand(0) + "*"
+	case OneOrMore:
+		return n.operand(0) + "+"
+	case Reference:
+		return n.Text
+	case Literal:
+		b := strings.Builder{}
+		b.WriteByte('\'')
+		for i := 0; i < len(n.Text); i++ {
+			b.WriteString(escapeByte(n.Text[i], '\''))
+		}
+		b.WriteByte('\'')
+		return b.String()
+	case Class:
+		b := strings.Builder{}
+		b.WriteByte('[')
+		if n.Negated {
+			b.WriteByte('^')
+		}
+		for _, r := range n.Ranges {
+			b.WriteString(escapeClassByte(r.Begin))
+			if r.Begin != r.End {
+				b.WriteByte('-')
+				b.WriteString(escapeClassByte(r.End))
+			}
+		}
+		b.WriteByte(']')
+		return b.String()
+	default:
+		return "."
+	}
+}
+
+func escapeClassByte(c byte) string {
+	switch c {
+	case '-', '[', '^':
+		return `\` + string(c)
+	default:
+		return escapeByte(c, ']')
+	}
+}
+
+// String returns the rule in grammar text notation.
+func (r *Rule) String() string {
+	return fmt.Sprintf("%s <- %s", r.Name, r.Expr)
+}
+
+// String returns the grammar in grammar text notation with a rule per line.
+func (g *Grammar) String() string {
+	b := strings.Builder{}
+	for _, rule := range g.Rules {
+		b.WriteString(rule.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,133 @@
+package peg
+
+import (
+	"fmt"
+
+	"github.com/go-pars/pars"
+)
+
+func classParser(n *Node) pars.Parser {
+	name := fmt.Sprintf("Class(%s)", n)
+	what := fmt.Sprintf("expected %s", n)
+
+	return func(state *pars.State, result *pars.Result) error {
+		c, err := pars.Next(state)
+		if err != nil {
+			return pars.NewNestedError(name, err)
+		}
+		if !n.Matches(c) {
+			return pars.NewError(what, state.Position())
+		}
+		result.SetToken([]byte{c})
+		state.Advance()
+		return nil
+	}
+}
+
+func flatten(result *pars.Result) error {
+	head, tail := result.Children[0], result.Children[1].Children
+	children := make([]pars.Result, len(tail)+1)
+	children[0] = head
+	copy(children[1:], tail)
+	result.SetChildren(children)
+	return nil
+}
+
+func compileNode(n *Node, refs map[string]*pars.Parser) pars.Parser {
+	children := make([]interface{}, len(n.Children))
+	for i, child := range n.Children {
+		children[i] = compileNode(child, refs)
+	}
+
+	switch n.Kind {
+	case Choice:
+		return pars.Any(children...)
+	case Sequence:
+		if len(children) == 0 {
+			return pars.Epsilon
+		}
+		return pars.Seq(children...)
+	case And:
+		return pars.Dry(children[0])
+	case Not:
+		return pars.Not(children[0])
+	case Optional:
+		return pars.Maybe(children[0])
+	case ZeroOrMore:
+		return pars.Many(children[0])
+	case OneOrMore:
+		return pars.Seq(children[0], pars.Many(children[0])).Map(flatten)
+	case Label:
+		return pars.Capture(n.Text, children[0])
+	case Reference:
+		return pars.AsParser(refs[n.Text])
+	case Literal:
+		return pars.String(n.Text)
+	case Class:
+		return classParser(n)
+	default:
+		return pars.Byte()
+	}
+}
+
+// Compile the grammar into a Parser for each rule keyed by the rule name.
+// Rules reference each other lazily so recursive rules are allowed, and each
+// rule is described with pars.Named for tools inspecting the grammar. The
+// results of the Parsers are as follows:
+//
+//   choice:      the result of the matching alternative.
+//   sequence:    the results of the elements as Children.
+//   `&`:         the result of the operand.
+//   `!`:         an empty result.
+//   `?`:         the result of the operand or an empty result.
+//   `*` and `+`: the results of each match as Children.
+//   `name:`:     the result of the operand captured with the name.
+//   literals:    the literal as a string Value.
+//   classes:     the matching byte as a Token.
+//   `.`:         the matching byte as a Token.
+//
+// The given actions are applied with Map to the rules of the same name. It is
+// an error to give an action for a rule which does not exist.
+func (g *Grammar) Compile(actions map[string]pars.Map) (map[string]pars.Parser, error) {
+	refs := make(map[string]*pars.Parser, len(g.Rules))
+	for _, rule := range g.Rules {
+		refs[rule.Name] = new(pars.Parser)
+	}
+
+	for name := range actions {
+		if _, ok := refs[name]; !ok {
+			return nil, fmt.Errorf("action given for undefined rule `%s`", name)
+		}
+	}
+
+	rules := make(map[string]pars.Parser, len(g.Rules))
+	for _, rule := range g.Rules {
+		p := compileNode(rule.Expr, refs)
+		if action, ok := actions[rule.Name]; ok {
+			p = p.Map(action)
+		}
+		p = pars.Named(rule.Name, p)
+		*refs[rule.Name] = p
+		rules[rule.Name] = p
+	}
+	return rules, nil
+}
+
+// Compile parses the grammar text and compiles it with the given actions.
+// See Parse and Grammar.Compile for details.
+func Compile(text string, actions map[string]pars.Map) (map[string]pars.Parser, error) {
+	g, err := Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return g.Compile(actions)
+}
+
+// MustCompile is like Compile but panics if an error occurs.
+func MustCompile(text string, actions map[string]pars.Map) map[string]pars.Parser {
+	rules, err := Compile(text, actions)
+	if err != nil {
+		panic(err)
+	}
+	return rules
+}
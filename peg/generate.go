@@ -0,0 +1,234 @@
+package peg
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// generator holds the state for generating Go source code from a grammar.
+type generator struct {
+	buf   bytes.Buffer
+	queue []*Node
+	names map[*Node]string
+	count int
+	bytes bool
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// name returns the name of the function generated for the node, queueing the
+// node for generation if it has not been seen before. References are called
+// directly by the name of the rule function.
+func (g *generator) name(rule string, n *Node) string {
+	if n.Kind == Reference {
+		return ruleFunc(n.Text)
+	}
+	if name, ok := g.names[n]; ok {
+		return name
+	}
+	g.count++
+	name := exprFunc(rule, g.count)
+	g.names[n] = name
+	g.queue = append(g.queue, n)
+	return name
+}
+
+// ruleFunc returns the name of the function generated for a rule.
+func ruleFunc(name string) string { return "rule" + name }
+
+// exprFunc returns the name of the function generated for the nth expression
+// of a rule. Rule names may contain digits and underscores, so a prefix other
+// than that of ruleFunc is used to keep the names from colliding, and the
+// number is separated by an underscore so the names of different rules
+// cannot collide with each other.
+func exprFunc(rule string, n int) string { return fmt.Sprintf("expr%s_%d", rule, n) }
+
+func classCondition(n *Node) string {
+	conds := make([]string, len(n.Ranges))
+	for i, r := range n.Ranges {
+		if r.Begin == r.End {
+			conds[i] = fmt.Sprintf("c == %s", byteLiteral(r.Begin))
+		} else {
+			conds[i] = fmt.Sprintf("%s <= c && c <= %s", byteLiteral(r.Begin), byteLiteral(r.End))
+		}
+	}
+	cond := strings.Join(conds, " || ")
+	if n.Negated {
+		return fmt.Sprintf("!(%s)", cond)
+	}
+	return cond
+}
+
+func byteLiteral(c byte) string {
+	if c < 0x20 || 0x7e < c || c == '\'' || c == '\\' {
+		return fmt.Sprintf("0x%02x", c)
+	}
+	return fmt.Sprintf("'%c'", c)
+}
+
+func (g *generator) node(rule string, n *Node) {
+	name := g.names[n]
+	g.printf("\nfunc (g *grammar) %s(state *pars.State, result *pars.Result) error {\n", name)
+	defer g.printf("}\n")
+
+	call := func(i int, result string) string {
+		return fmt.Sprintf("g.%s(state, %s)", g.name(rule, n.Children[i]), result)
+	}
+
+	switch n.Kind {
+	case Choice:
+		nested := strconv.Quote(fmt.Sprintf("Any(%d)", len(n.Children)))
+		g.printf("var err error\nstate.Push()\n")
+		for i := range n.Children {
+			g.printf("if err = %s; err == nil {\nstate.Drop()\nreturn nil\n}\n", call(i, "result"))
+			g.printf("if !state.Pushed() {\nreturn pars.NewNestedError(%s, err)\n}\n", nested)
+		}
+		g.printf("state.Pop()\nreturn pars.NewNestedError(%s, err)\n", nested)
+
+	case Sequence:
+		nested := strconv.Quote(fmt.Sprintf("Seq(%d)", len(n.Children)))
+		g.printf("v := make([]pars.Result, %d)\nstate.Push()\n", len(n.Children))
+		for i := range n.Children {
+			g.printf("if err := %s; err != nil {\n", call(i, fmt.Sprintf("&v[%d]", i)))
+			g.printf("state.Pop()\nreturn pars.NewNestedError(%s, err)\n}\n", nested)
+		}
+		g.printf("state.Drop()\nresult.SetChildren(v)\nreturn nil\n")
+
+	case And:
+		g.printf("state.Push()\nerr := %s\nstate.Pop()\nreturn err\n", call(0, "result"))
+
+	case Not:
+		g.printf("state.Push()\nerr := %s\nstate.Pop()\n", call(0, "&pars.Result{}"))
+		g.printf("if err == nil {\n")
+		g.printf("return pars.NewError(\"unexpected match\", state.Position())\n}\nreturn nil\n")
+
+	case Optional:
+		g.printf("state.Push()\nif err := %s; err != nil {\n", call(0, "result"))
+		g.printf("if !state.Pushed() {\nreturn pars.NewNestedError(\"Maybe\", err)\n}\n")
+		g.printf("state.Pop()\nreturn nil\n}\nstate.Drop()\nreturn nil\n")
+
+	case ZeroOrMore:
+		g.printf("v := []pars.Result{}\nstart := state.Position()\n")
+		g.printf("for %s == nil {\n", call(0, "result"))
+		g.printf("if start == state.Position() {\nbreak\n}\n")
+		g.printf("v = append(v, *result)\n*result = pars.Result{}\nstart = state.Position()\n}\n")
+		g.printf("result.SetChildren(v)\nreturn nil\n")
+
+	case OneOrMore:
+		g.printf("state.Push()\nhead := pars.Result{}\n")
+		g.printf("if err := %s; err != nil {\n", call(0, "&head"))
+		g.printf("state.Pop()\nreturn pars.NewNestedError(\"Seq(2)\", err)\n}\n")
+		g.printf("v := []pars.Result{head}\ntail := pars.Result{}\nstart := state.Position()\n")
+		g.printf("for %s == nil {\n", call(0, "&tail"))
+		g.printf("if start == state.Position() {\nbreak\n}\n")
+		g.printf("v = append(v, tail)\ntail = pars.Result{}\nstart = state.Position()\n}\n")
+		g.printf("state.Drop()\nresult.SetChildren(v)\nreturn nil\n")
+
+	case Label:
+		g.printf("if err := %s; err != nil {\nreturn err\n}\n", call(0, "result"))
+		g.printf("result.Name = %s\nreturn nil\n", strconv.Quote(n.Text))
+
+	case Literal:
+		text := strconv.Quote(n.Text)
+		nested := strconv.Quote(fmt.Sprintf("String(%s)", n.Text))
+		g.printf("if err := state.Request(%d); err != nil {\n", len(n.Text))
+		g.printf("return pars.NewNestedError(%s, err)\n}\n", nested)
+		switch len(n.Text) {
+		case 0:
+		case 1:
+			g.printf("if state.Buffer()[0] != %s {\n", byteLiteral(n.Text[0]))
+		default:
+			g.bytes = true
+			g.printf("if !bytes.Equal(state.Buffer(), []byte(%s)) {\n", text)
+		}
+		if len(n.Text) > 0 {
+			what := strconv.Quote(fmt.Sprintf(`expected "%s"`, n.Text))
+			g.printf("return pars.NewError(%s, state.Position())\n}\n", what)
+		}
+		g.printf("result.SetValue(%s)\nstate.Advance()\nreturn nil\n", text)
+
+	case Class:
+		g.printf("c, err := pars.Next(state)\nif err != nil {\n")
+		g.printf("return pars.NewNestedError(%s, err)\n}\n", strconv.Quote(fmt.Sprintf("Class(%s)", n)))
+		g.printf("if !(%s) {\n", classCondition(n))
+		g.printf("return pars.NewError(%s, state.Position())\n}\n", strconv.Quote(fmt.Sprintf("expected %s", n)))
+		g.printf("result.SetToken([]byte{c})\nstate.Advance()\nreturn nil\n")
+
+	default:
+		g.printf("if err := state.Request(1); err != nil {\n")
+		g.printf("return pars.NewNestedError(\"Byte\", err)\n}\n")
+		g.printf("result.SetToken([]byte{state.Buffer()[0]})\nstate.Advance()\nreturn nil\n")
+	}
+}
+
+func (g *generator) rule(rule *Rule) {
+	name, quoted := ruleFunc(rule.Name), strconv.Quote(rule.Name)
+	g.count = 0
+
+	g.printf("\n// %s parses the rule `%s`.\n", name, rule)
+	g.printf("func (g *grammar) %s(state *pars.State, result *pars.Result) error {\n", name)
+	g.printf("f := g.actions[%s]\nif f == nil {\n", quoted)
+	g.printf("return g.%s(state, result)\n}\n", g.name(rule.Name, rule.Expr))
+	g.printf("state.Push()\nif err := g.%s(state, result); err != nil {\n", g.name(rule.Name, rule.Expr))
+	g.printf("state.Pop()\nreturn err\n}\nstate.Drop()\nreturn f(result)\n}\n")
+
+	// Generating a node may queue more nodes so loop until the queue is empty.
+	for len(g.queue) > 0 {
+		n := g.queue[0]
+		g.queue = g.queue[1:]
+		g.node(rule.Name, n)
+	}
+}
+
+// Generate writes a Go source file for the given package which implements
+// the grammar with specialized functions instead of combinators. The file
+// declares a single exported function named Rules with the same signature as
+// Grammar.Compile which yields Parsers with the same results for any input.
+func (g *Grammar) Generate(w io.Writer, pkg string) error {
+	gen := &generator{names: make(map[*Node]string)}
+
+	for _, rule := range g.Rules {
+		gen.rule(rule)
+	}
+
+	names := make([]string, len(g.Rules))
+	for i, rule := range g.Rules {
+		names[i] = rule.Name
+	}
+	sort.Strings(names)
+
+	out := bytes.Buffer{}
+	fmt.Fprintf(&out, "// Code generated by pars-gen. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	if gen.bytes {
+		fmt.Fprintf(&out, "import (\n\"bytes\"\n\"fmt\"\n\n\"github.com/go-pars/pars\"\n)\n")
+	} else {
+		fmt.Fprintf(&out, "import (\n\"fmt\"\n\n\"github.com/go-pars/pars\"\n)\n")
+	}
+	fmt.Fprintf(&out, "\ntype grammar struct {\nactions map[string]pars.Map\n}\n")
+
+	fmt.Fprintf(&out, "\n// Rules creates a Parser for each rule of the grammar keyed by the rule name.\n")
+	fmt.Fprintf(&out, "// The given actions are applied to the results of the rules of the same name.\n")
+	fmt.Fprintf(&out, "func Rules(actions map[string]pars.Map) (map[string]pars.Parser, error) {\n")
+	fmt.Fprintf(&out, "g := &grammar{actions}\nrules := map[string]pars.Parser{\n")
+	for _, name := range names {
+		fmt.Fprintf(&out, "%s: g.%s,\n", strconv.Quote(name), ruleFunc(name))
+	}
+	fmt.Fprintf(&out, "}\nfor name := range actions {\nif _, ok := rules[name]; !ok {\n")
+	fmt.Fprintf(&out, "return nil, fmt.Errorf(\"action given for undefined rule `%%s`\", name)\n")
+	fmt.Fprintf(&out, "}\n}\nreturn rules, nil\n}\n")
+	out.Write(gen.buf.Bytes())
+
+	p, err := format.Source(out.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(p)
+	return err
+}
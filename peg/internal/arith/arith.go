@@ -0,0 +1,624 @@
+// Code generated by pars-gen. DO NOT EDIT.
+
+package arith
+
+import (
+	"fmt"
+
+	"github.com/go-pars/pars"
+)
+
+type grammar struct {
+	actions map[string]pars.Map
+}
+
+// Rules creates a Parser for each rule of the grammar keyed by the rule name.
+// The given actions are applied to the results of the rules of the same name.
+func Rules(actions map[string]pars.Map) (map[string]pars.Parser, error) {
+	g := &grammar{actions}
+	rules := map[string]pars.Parser{
+		"AddOp":   g.ruleAddOp,
+		"Expr":    g.ruleExpr,
+		"MulOp":   g.ruleMulOp,
+		"Number":  g.ruleNumber,
+		"Product": g.ruleProduct,
+		"Spacing": g.ruleSpacing,
+		"Sum":     g.ruleSum,
+		"Value":   g.ruleValue,
+	}
+	for name := range actions {
+		if _, ok := rules[name]; !ok {
+			return nil, fmt.Errorf("action given for undefined rule `%s`", name)
+		}
+	}
+	return rules, nil
+}
+
+// ruleExpr parses the rule `Expr <- Spacing Sum !.`.
+func (g *grammar) ruleExpr(state *pars.State, result *pars.Result) error {
+	f := g.actions["Expr"]
+	if f == nil {
+		return g.exprExpr_1(state, result)
+	}
+	state.Push()
+	if err := g.exprExpr_1(state, result); err != nil {
+		state.Pop()
+		return err
+	}
+	state.Drop()
+	return f(result)
+}
+
+func (g *grammar) exprExpr_1(state *pars.State, result *pars.Result) error {
+	v := make([]pars.Result, 3)
+	state.Push()
+	if err := g.ruleSpacing(state, &v[0]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(3)", err)
+	}
+	if err := g.ruleSum(state, &v[1]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(3)", err)
+	}
+	if err := g.exprExpr_2(state, &v[2]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(3)", err)
+	}
+	state.Drop()
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprExpr_2(state *pars.State, result *pars.Result) error {
+	state.Push()
+	err := g.exprExpr_3(state, &pars.Result{})
+	state.Pop()
+	if err == nil {
+		return pars.NewError("unexpected match", state.Position())
+	}
+	return nil
+}
+
+func (g *grammar) exprExpr_3(state *pars.State, result *pars.Result) error {
+	if err := state.Request(1); err != nil {
+		return pars.NewNestedError("Byte", err)
+	}
+	result.SetToken([]byte{state.Buffer()[0]})
+	state.Advance()
+	return nil
+}
+
+// ruleSum parses the rule `Sum <- head:Product tail:(AddOp Product)*`.
+func (g *grammar) ruleSum(state *pars.State, result *pars.Result) error {
+	f := g.actions["Sum"]
+	if f == nil {
+		return g.exprSum_1(state, result)
+	}
+	state.Push()
+	if err := g.exprSum_1(state, result); err != nil {
+		state.Pop()
+		return err
+	}
+	state.Drop()
+	return f(result)
+}
+
+func (g *grammar) exprSum_1(state *pars.State, result *pars.Result) error {
+	v := make([]pars.Result, 2)
+	state.Push()
+	if err := g.exprSum_2(state, &v[0]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	if err := g.exprSum_3(state, &v[1]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	state.Drop()
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprSum_2(state *pars.State, result *pars.Result) error {
+	if err := g.ruleProduct(state, result); err != nil {
+		return err
+	}
+	result.Name = "head"
+	return nil
+}
+
+func (g *grammar) exprSum_3(state *pars.State, result *pars.Result) error {
+	if err := g.exprSum_4(state, result); err != nil {
+		return err
+	}
+	result.Name = "tail"
+	return nil
+}
+
+func (g *grammar) exprSum_4(state *pars.State, result *pars.Result) error {
+	v := []pars.Result{}
+	start := state.Position()
+	for g.exprSum_5(state, result) == nil {
+		if start == state.Position() {
+			break
+		}
+		v = append(v, *result)
+		*result = pars.Result{}
+		start = state.Position()
+	}
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprSum_5(state *pars.State, result *pars.Result) error {
+	v := make([]pars.Result, 2)
+	state.Push()
+	if err := g.ruleAddOp(state, &v[0]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	if err := g.ruleProduct(state, &v[1]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	state.Drop()
+	result.SetChildren(v)
+	return nil
+}
+
+// ruleProduct parses the rule `Product <- head:Value tail:(MulOp Value)*`.
+func (g *grammar) ruleProduct(state *pars.State, result *pars.Result) error {
+	f := g.actions["Product"]
+	if f == nil {
+		return g.exprProduct_1(state, result)
+	}
+	state.Push()
+	if err := g.exprProduct_1(state, result); err != nil {
+		state.Pop()
+		return err
+	}
+	state.Drop()
+	return f(result)
+}
+
+func (g *grammar) exprProduct_1(state *pars.State, result *pars.Result) error {
+	v := make([]pars.Result, 2)
+	state.Push()
+	if err := g.exprProduct_2(state, &v[0]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	if err := g.exprProduct_3(state, &v[1]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	state.Drop()
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprProduct_2(state *pars.State, result *pars.Result) error {
+	if err := g.ruleValue(state, result); err != nil {
+		return err
+	}
+	result.Name = "head"
+	return nil
+}
+
+func (g *grammar) exprProduct_3(state *pars.State, result *pars.Result) error {
+	if err := g.exprProduct_4(state, result); err != nil {
+		return err
+	}
+	result.Name = "tail"
+	return nil
+}
+
+func (g *grammar) exprProduct_4(state *pars.State, result *pars.Result) error {
+	v := []pars.Result{}
+	start := state.Position()
+	for g.exprProduct_5(state, result) == nil {
+		if start == state.Position() {
+			break
+		}
+		v = append(v, *result)
+		*result = pars.Result{}
+		start = state.Position()
+	}
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprProduct_5(state *pars.State, result *pars.Result) error {
+	v := make([]pars.Result, 2)
+	state.Push()
+	if err := g.ruleMulOp(state, &v[0]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	if err := g.ruleValue(state, &v[1]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	state.Drop()
+	result.SetChildren(v)
+	return nil
+}
+
+// ruleValue parses the rule `Value <- Number / '(' Spacing Sum ')' Spacing`.
+func (g *grammar) ruleValue(state *pars.State, result *pars.Result) error {
+	f := g.actions["Value"]
+	if f == nil {
+		return g.exprValue_1(state, result)
+	}
+	state.Push()
+	if err := g.exprValue_1(state, result); err != nil {
+		state.Pop()
+		return err
+	}
+	state.Drop()
+	return f(result)
+}
+
+func (g *grammar) exprValue_1(state *pars.State, result *pars.Result) error {
+	var err error
+	state.Push()
+	if err = g.ruleNumber(state, result); err == nil {
+		state.Drop()
+		return nil
+	}
+	if !state.Pushed() {
+		return pars.NewNestedError("Any(2)", err)
+	}
+	if err = g.exprValue_2(state, result); err == nil {
+		state.Drop()
+		return nil
+	}
+	if !state.Pushed() {
+		return pars.NewNestedError("Any(2)", err)
+	}
+	state.Pop()
+	return pars.NewNestedError("Any(2)", err)
+}
+
+func (g *grammar) exprValue_2(state *pars.State, result *pars.Result) error {
+	v := make([]pars.Result, 5)
+	state.Push()
+	if err := g.exprValue_3(state, &v[0]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(5)", err)
+	}
+	if err := g.ruleSpacing(state, &v[1]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(5)", err)
+	}
+	if err := g.ruleSum(state, &v[2]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(5)", err)
+	}
+	if err := g.exprValue_4(state, &v[3]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(5)", err)
+	}
+	if err := g.ruleSpacing(state, &v[4]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(5)", err)
+	}
+	state.Drop()
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprValue_3(state *pars.State, result *pars.Result) error {
+	if err := state.Request(1); err != nil {
+		return pars.NewNestedError("String(()", err)
+	}
+	if state.Buffer()[0] != '(' {
+		return pars.NewError("expected \"(\"", state.Position())
+	}
+	result.SetValue("(")
+	state.Advance()
+	return nil
+}
+
+func (g *grammar) exprValue_4(state *pars.State, result *pars.Result) error {
+	if err := state.Request(1); err != nil {
+		return pars.NewNestedError("String())", err)
+	}
+	if state.Buffer()[0] != ')' {
+		return pars.NewError("expected \")\"", state.Position())
+	}
+	result.SetValue(")")
+	state.Advance()
+	return nil
+}
+
+// ruleNumber parses the rule `Number <- '-'? [0-9]+ Spacing`.
+func (g *grammar) ruleNumber(state *pars.State, result *pars.Result) error {
+	f := g.actions["Number"]
+	if f == nil {
+		return g.exprNumber_1(state, result)
+	}
+	state.Push()
+	if err := g.exprNumber_1(state, result); err != nil {
+		state.Pop()
+		return err
+	}
+	state.Drop()
+	return f(result)
+}
+
+func (g *grammar) exprNumber_1(state *pars.State, result *pars.Result) error {
+	v := make([]pars.Result, 3)
+	state.Push()
+	if err := g.exprNumber_2(state, &v[0]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(3)", err)
+	}
+	if err := g.exprNumber_3(state, &v[1]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(3)", err)
+	}
+	if err := g.ruleSpacing(state, &v[2]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(3)", err)
+	}
+	state.Drop()
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprNumber_2(state *pars.State, result *pars.Result) error {
+	state.Push()
+	if err := g.exprNumber_4(state, result); err != nil {
+		if !state.Pushed() {
+			return pars.NewNestedError("Maybe", err)
+		}
+		state.Pop()
+		return nil
+	}
+	state.Drop()
+	return nil
+}
+
+func (g *grammar) exprNumber_3(state *pars.State, result *pars.Result) error {
+	state.Push()
+	head := pars.Result{}
+	if err := g.exprNumber_5(state, &head); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	v := []pars.Result{head}
+	tail := pars.Result{}
+	start := state.Position()
+	for g.exprNumber_5(state, &tail) == nil {
+		if start == state.Position() {
+			break
+		}
+		v = append(v, tail)
+		tail = pars.Result{}
+		start = state.Position()
+	}
+	state.Drop()
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprNumber_4(state *pars.State, result *pars.Result) error {
+	if err := state.Request(1); err != nil {
+		return pars.NewNestedError("String(-)", err)
+	}
+	if state.Buffer()[0] != '-' {
+		return pars.NewError("expected \"-\"", state.Position())
+	}
+	result.SetValue("-")
+	state.Advance()
+	return nil
+}
+
+func (g *grammar) exprNumber_5(state *pars.State, result *pars.Result) error {
+	c, err := pars.Next(state)
+	if err != nil {
+		return pars.NewNestedError("Class([0-9])", err)
+	}
+	if !('0' <= c && c <= '9') {
+		return pars.NewError("expected [0-9]", state.Position())
+	}
+	result.SetToken([]byte{c})
+	state.Advance()
+	return nil
+}
+
+// ruleAddOp parses the rule `AddOp <- ('+' / '-') Spacing`.
+func (g *grammar) ruleAddOp(state *pars.State, result *pars.Result) error {
+	f := g.actions["AddOp"]
+	if f == nil {
+		return g.exprAddOp_1(state, result)
+	}
+	state.Push()
+	if err := g.exprAddOp_1(state, result); err != nil {
+		state.Pop()
+		return err
+	}
+	state.Drop()
+	return f(result)
+}
+
+func (g *grammar) exprAddOp_1(state *pars.State, result *pars.Result) error {
+	v := make([]pars.Result, 2)
+	state.Push()
+	if err := g.exprAddOp_2(state, &v[0]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	if err := g.ruleSpacing(state, &v[1]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	state.Drop()
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprAddOp_2(state *pars.State, result *pars.Result) error {
+	var err error
+	state.Push()
+	if err = g.exprAddOp_3(state, result); err == nil {
+		state.Drop()
+		return nil
+	}
+	if !state.Pushed() {
+		return pars.NewNestedError("Any(2)", err)
+	}
+	if err = g.exprAddOp_4(state, result); err == nil {
+		state.Drop()
+		return nil
+	}
+	if !state.Pushed() {
+		return pars.NewNestedError("Any(2)", err)
+	}
+	state.Pop()
+	return pars.NewNestedError("Any(2)", err)
+}
+
+func (g *grammar) exprAddOp_3(state *pars.State, result *pars.Result) error {
+	if err := state.Request(1); err != nil {
+		return pars.NewNestedError("String(+)", err)
+	}
+	if state.Buffer()[0] != '+' {
+		return pars.NewError("expected \"+\"", state.Position())
+	}
+	result.SetValue("+")
+	state.Advance()
+	return nil
+}
+
+func (g *grammar) exprAddOp_4(state *pars.State, result *pars.Result) error {
+	if err := state.Request(1); err != nil {
+		return pars.NewNestedError("String(-)", err)
+	}
+	if state.Buffer()[0] != '-' {
+		return pars.NewError("expected \"-\"", state.Position())
+	}
+	result.SetValue("-")
+	state.Advance()
+	return nil
+}
+
+// ruleMulOp parses the rule `MulOp <- ('*' / '/') Spacing`.
+func (g *grammar) ruleMulOp(state *pars.State, result *pars.Result) error {
+	f := g.actions["MulOp"]
+	if f == nil {
+		return g.exprMulOp_1(state, result)
+	}
+	state.Push()
+	if err := g.exprMulOp_1(state, result); err != nil {
+		state.Pop()
+		return err
+	}
+	state.Drop()
+	return f(result)
+}
+
+func (g *grammar) exprMulOp_1(state *pars.State, result *pars.Result) error {
+	v := make([]pars.Result, 2)
+	state.Push()
+	if err := g.exprMulOp_2(state, &v[0]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	if err := g.ruleSpacing(state, &v[1]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	state.Drop()
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprMulOp_2(state *pars.State, result *pars.Result) error {
+	var err error
+	state.Push()
+	if err = g.exprMulOp_3(state, result); err == nil {
+		state.Drop()
+		return nil
+	}
+	if !state.Pushed() {
+		return pars.NewNestedError("Any(2)", err)
+	}
+	if err = g.exprMulOp_4(state, result); err == nil {
+		state.Drop()
+		return nil
+	}
+	if !state.Pushed() {
+		return pars.NewNestedError("Any(2)", err)
+	}
+	state.Pop()
+	return pars.NewNestedError("Any(2)", err)
+}
+
+func (g *grammar) exprMulOp_3(state *pars.State, result *pars.Result) error {
+	if err := state.Request(1); err != nil {
+		return pars.NewNestedError("String(*)", err)
+	}
+	if state.Buffer()[0] != '*' {
+		return pars.NewError("expected \"*\"", state.Position())
+	}
+	result.SetValue("*")
+	state.Advance()
+	return nil
+}
+
+func (g *grammar) exprMulOp_4(state *pars.State, result *pars.Result) error {
+	if err := state.Request(1); err != nil {
+		return pars.NewNestedError("String(/)", err)
+	}
+	if state.Buffer()[0] != '/' {
+		return pars.NewError("expected \"/\"", state.Position())
+	}
+	result.SetValue("/")
+	state.Advance()
+	return nil
+}
+
+// ruleSpacing parses the rule `Spacing <- [ \t\n]*`.
+func (g *grammar) ruleSpacing(state *pars.State, result *pars.Result) error {
+	f := g.actions["Spacing"]
+	if f == nil {
+		return g.exprSpacing_1(state, result)
+	}
+	state.Push()
+	if err := g.exprSpacing_1(state, result); err != nil {
+		state.Pop()
+		return err
+	}
+	state.Drop()
+	return f(result)
+}
+
+func (g *grammar) exprSpacing_1(state *pars.State, result *pars.Result) error {
+	v := []pars.Result{}
+	start := state.Position()
+	for g.exprSpacing_2(state, result) == nil {
+		if start == state.Position() {
+			break
+		}
+		v = append(v, *result)
+		*result = pars.Result{}
+		start = state.Position()
+	}
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprSpacing_2(state *pars.State, result *pars.Result) error {
+	c, err := pars.Next(state)
+	if err != nil {
+		return pars.NewNestedError("Class([ \\t\\n])", err)
+	}
+	if !(c == ' ' || c == 0x09 || c == 0x0a) {
+		return pars.NewError("expected [ \\t\\n]", state.Position())
+	}
+	result.SetToken([]byte{c})
+	state.Advance()
+	return nil
+}
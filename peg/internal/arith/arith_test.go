@@ -0,0 +1,122 @@
+package arith
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/go-pars/pars"
+	"github.com/go-pars/pars/peg"
+)
+
+func fold(result *pars.Result) error {
+	v := result.Get("head").Value.(int)
+	for _, op := range result.Get("tail").Children {
+		n := op.Children[1].Value.(int)
+		switch op.Children[0].Value.(string) {
+		case "+":
+			v += n
+		case "-":
+			v -= n
+		case "*":
+			v *= n
+		case "/":
+			v /= n
+		}
+	}
+	result.SetValue(v)
+	return nil
+}
+
+var actions = map[string]pars.Map{
+	"Expr":    pars.Child(1),
+	"Sum":     fold,
+	"Product": fold,
+	"Value": func(result *pars.Result) error {
+		if result.Children != nil {
+			*result = result.Children[2]
+		}
+		return nil
+	},
+	"Number": func(result *pars.Result) error {
+		p := []byte{}
+		if result.Children[0].Value != nil {
+			p = append(p, '-')
+		}
+		for _, digit := range result.Children[1].Children {
+			p = append(p, digit.Token...)
+		}
+		n, err := strconv.Atoi(string(p))
+		result.SetValue(n)
+		return err
+	},
+	"AddOp": pars.Child(0),
+	"MulOp": pars.Child(0),
+}
+
+var inputs = []string{
+	"", "42", "-42", " 1 + 2 * 3 ", "(1 + 2) * 3", "10 / 2 - 3",
+	"2 * (3 + -4) * 5", "1 +", "(1", "1)", "a", "1 2",
+}
+
+func loadGrammar(t *testing.T) *peg.Grammar {
+	t.Helper()
+	text, err := ioutil.ReadFile("arith.peg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return peg.MustParse(string(text))
+}
+
+func TestGenerated(t *testing.T) {
+	generated, err := Rules(actions)
+	if err != nil {
+		t.Fatalf("Rules(): %v", err)
+	}
+	compiled, err := loadGrammar(t).Compile(actions)
+	if err != nil {
+		t.Fatalf("Compile(): %v", err)
+	}
+
+	for name := range compiled {
+		for _, in := range inputs {
+			gs, cs := pars.FromString(in), pars.FromString(in)
+			gr, gerr := generated[name].Parse(gs)
+			cr, cerr := compiled[name].Parse(cs)
+			if !reflect.DeepEqual(gr, cr) {
+				t.Errorf("%s.Parse(%q) = %#v, want %#v", name, in, gr, cr)
+			}
+			if !reflect.DeepEqual(gerr, cerr) {
+				t.Errorf("%s.Parse(%q): error `%v`, want `%v`", name, in, gerr, cerr)
+			}
+			if gs.Position() != cs.Position() {
+				t.Errorf("%s.Parse(%q): position %s, want %s", name, in, gs.Position(), cs.Position())
+			}
+		}
+	}
+
+	in := "2 * (3 + -4) * 5"
+	if result, err := generated["Expr"].Parse(pars.FromString(in)); err != nil || result.Value != -10 {
+		t.Errorf("Expr.Parse(%q) = %v, %v, want -10, nil", in, result.Value, err)
+	}
+
+	if _, err := Rules(map[string]pars.Map{"Missing": pars.ToString}); err == nil {
+		t.Errorf("Rules(): expected error")
+	}
+}
+
+func TestUpToDate(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := loadGrammar(t).Generate(&buf, "arith"); err != nil {
+		t.Fatalf("Generate(): %v", err)
+	}
+	p, err := ioutil.ReadFile("arith.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), p) {
+		t.Errorf("arith.go is out of date, run go generate")
+	}
+}
@@ -0,0 +1,5 @@
+// Package arith is an arithmetic grammar generated by pars-gen for testing the
+// generated code against the grammar compiled with the peg package.
+package arith
+
+//go:generate go run ../../../cmd/pars-gen -o arith.go arith.peg
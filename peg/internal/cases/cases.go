@@ -0,0 +1,402 @@
+// Code generated by pars-gen. DO NOT EDIT.
+
+package cases
+
+import (
+	"fmt"
+
+	"github.com/go-pars/pars"
+)
+
+type grammar struct {
+	actions map[string]pars.Map
+}
+
+// Rules creates a Parser for each rule of the grammar keyed by the rule name.
+// The given actions are applied to the results of the rules of the same name.
+func Rules(actions map[string]pars.Map) (map[string]pars.Parser, error) {
+	g := &grammar{actions}
+	rules := map[string]pars.Parser{
+		"A":   g.ruleA,
+		"A1":  g.ruleA1,
+		"A_1": g.ruleA_1,
+		"A_2": g.ruleA_2,
+	}
+	for name := range actions {
+		if _, ok := rules[name]; !ok {
+			return nil, fmt.Errorf("action given for undefined rule `%s`", name)
+		}
+	}
+	return rules, nil
+}
+
+// ruleA parses the rule `A <- A1 (A1 / 'x')* !.`.
+func (g *grammar) ruleA(state *pars.State, result *pars.Result) error {
+	f := g.actions["A"]
+	if f == nil {
+		return g.exprA_1(state, result)
+	}
+	state.Push()
+	if err := g.exprA_1(state, result); err != nil {
+		state.Pop()
+		return err
+	}
+	state.Drop()
+	return f(result)
+}
+
+func (g *grammar) exprA_1(state *pars.State, result *pars.Result) error {
+	v := make([]pars.Result, 3)
+	state.Push()
+	if err := g.ruleA1(state, &v[0]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(3)", err)
+	}
+	if err := g.exprA_2(state, &v[1]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(3)", err)
+	}
+	if err := g.exprA_3(state, &v[2]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(3)", err)
+	}
+	state.Drop()
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprA_2(state *pars.State, result *pars.Result) error {
+	v := []pars.Result{}
+	start := state.Position()
+	for g.exprA_4(state, result) == nil {
+		if start == state.Position() {
+			break
+		}
+		v = append(v, *result)
+		*result = pars.Result{}
+		start = state.Position()
+	}
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprA_3(state *pars.State, result *pars.Result) error {
+	state.Push()
+	err := g.exprA_5(state, &pars.Result{})
+	state.Pop()
+	if err == nil {
+		return pars.NewError("unexpected match", state.Position())
+	}
+	return nil
+}
+
+func (g *grammar) exprA_4(state *pars.State, result *pars.Result) error {
+	var err error
+	state.Push()
+	if err = g.ruleA1(state, result); err == nil {
+		state.Drop()
+		return nil
+	}
+	if !state.Pushed() {
+		return pars.NewNestedError("Any(2)", err)
+	}
+	if err = g.exprA_6(state, result); err == nil {
+		state.Drop()
+		return nil
+	}
+	if !state.Pushed() {
+		return pars.NewNestedError("Any(2)", err)
+	}
+	state.Pop()
+	return pars.NewNestedError("Any(2)", err)
+}
+
+func (g *grammar) exprA_5(state *pars.State, result *pars.Result) error {
+	if err := state.Request(1); err != nil {
+		return pars.NewNestedError("Byte", err)
+	}
+	result.SetToken([]byte{state.Buffer()[0]})
+	state.Advance()
+	return nil
+}
+
+func (g *grammar) exprA_6(state *pars.State, result *pars.Result) error {
+	if err := state.Request(1); err != nil {
+		return pars.NewNestedError("String(x)", err)
+	}
+	if state.Buffer()[0] != 'x' {
+		return pars.NewError("expected \"x\"", state.Position())
+	}
+	result.SetValue("x")
+	state.Advance()
+	return nil
+}
+
+// ruleA1 parses the rule `A1 <- 'a'? 'b'?`.
+func (g *grammar) ruleA1(state *pars.State, result *pars.Result) error {
+	f := g.actions["A1"]
+	if f == nil {
+		return g.exprA1_1(state, result)
+	}
+	state.Push()
+	if err := g.exprA1_1(state, result); err != nil {
+		state.Pop()
+		return err
+	}
+	state.Drop()
+	return f(result)
+}
+
+func (g *grammar) exprA1_1(state *pars.State, result *pars.Result) error {
+	v := make([]pars.Result, 2)
+	state.Push()
+	if err := g.exprA1_2(state, &v[0]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	if err := g.exprA1_3(state, &v[1]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	state.Drop()
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprA1_2(state *pars.State, result *pars.Result) error {
+	state.Push()
+	if err := g.exprA1_4(state, result); err != nil {
+		if !state.Pushed() {
+			return pars.NewNestedError("Maybe", err)
+		}
+		state.Pop()
+		return nil
+	}
+	state.Drop()
+	return nil
+}
+
+func (g *grammar) exprA1_3(state *pars.State, result *pars.Result) error {
+	state.Push()
+	if err := g.exprA1_5(state, result); err != nil {
+		if !state.Pushed() {
+			return pars.NewNestedError("Maybe", err)
+		}
+		state.Pop()
+		return nil
+	}
+	state.Drop()
+	return nil
+}
+
+func (g *grammar) exprA1_4(state *pars.State, result *pars.Result) error {
+	if err := state.Request(1); err != nil {
+		return pars.NewNestedError("String(a)", err)
+	}
+	if state.Buffer()[0] != 'a' {
+		return pars.NewError("expected \"a\"", state.Position())
+	}
+	result.SetValue("a")
+	state.Advance()
+	return nil
+}
+
+func (g *grammar) exprA1_5(state *pars.State, result *pars.Result) error {
+	if err := state.Request(1); err != nil {
+		return pars.NewNestedError("String(b)", err)
+	}
+	if state.Buffer()[0] != 'b' {
+		return pars.NewError("expected \"b\"", state.Position())
+	}
+	result.SetValue("b")
+	state.Advance()
+	return nil
+}
+
+// ruleA_1 parses the rule `A_1 <- ('c'?)+ 'd' A_2*`.
+func (g *grammar) ruleA_1(state *pars.State, result *pars.Result) error {
+	f := g.actions["A_1"]
+	if f == nil {
+		return g.exprA_1_1(state, result)
+	}
+	state.Push()
+	if err := g.exprA_1_1(state, result); err != nil {
+		state.Pop()
+		return err
+	}
+	state.Drop()
+	return f(result)
+}
+
+func (g *grammar) exprA_1_1(state *pars.State, result *pars.Result) error {
+	v := make([]pars.Result, 3)
+	state.Push()
+	if err := g.exprA_1_2(state, &v[0]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(3)", err)
+	}
+	if err := g.exprA_1_3(state, &v[1]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(3)", err)
+	}
+	if err := g.exprA_1_4(state, &v[2]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(3)", err)
+	}
+	state.Drop()
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprA_1_2(state *pars.State, result *pars.Result) error {
+	state.Push()
+	head := pars.Result{}
+	if err := g.exprA_1_5(state, &head); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	v := []pars.Result{head}
+	tail := pars.Result{}
+	start := state.Position()
+	for g.exprA_1_5(state, &tail) == nil {
+		if start == state.Position() {
+			break
+		}
+		v = append(v, tail)
+		tail = pars.Result{}
+		start = state.Position()
+	}
+	state.Drop()
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprA_1_3(state *pars.State, result *pars.Result) error {
+	if err := state.Request(1); err != nil {
+		return pars.NewNestedError("String(d)", err)
+	}
+	if state.Buffer()[0] != 'd' {
+		return pars.NewError("expected \"d\"", state.Position())
+	}
+	result.SetValue("d")
+	state.Advance()
+	return nil
+}
+
+func (g *grammar) exprA_1_4(state *pars.State, result *pars.Result) error {
+	v := []pars.Result{}
+	start := state.Position()
+	for g.ruleA_2(state, result) == nil {
+		if start == state.Position() {
+			break
+		}
+		v = append(v, *result)
+		*result = pars.Result{}
+		start = state.Position()
+	}
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprA_1_5(state *pars.State, result *pars.Result) error {
+	state.Push()
+	if err := g.exprA_1_6(state, result); err != nil {
+		if !state.Pushed() {
+			return pars.NewNestedError("Maybe", err)
+		}
+		state.Pop()
+		return nil
+	}
+	state.Drop()
+	return nil
+}
+
+func (g *grammar) exprA_1_6(state *pars.State, result *pars.Result) error {
+	if err := state.Request(1); err != nil {
+		return pars.NewNestedError("String(c)", err)
+	}
+	if state.Buffer()[0] != 'c' {
+		return pars.NewError("expected \"c\"", state.Position())
+	}
+	result.SetValue("c")
+	state.Advance()
+	return nil
+}
+
+// ruleA_2 parses the rule `A_2 <- !'e' 'f'?`.
+func (g *grammar) ruleA_2(state *pars.State, result *pars.Result) error {
+	f := g.actions["A_2"]
+	if f == nil {
+		return g.exprA_2_1(state, result)
+	}
+	state.Push()
+	if err := g.exprA_2_1(state, result); err != nil {
+		state.Pop()
+		return err
+	}
+	state.Drop()
+	return f(result)
+}
+
+func (g *grammar) exprA_2_1(state *pars.State, result *pars.Result) error {
+	v := make([]pars.Result, 2)
+	state.Push()
+	if err := g.exprA_2_2(state, &v[0]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	if err := g.exprA_2_3(state, &v[1]); err != nil {
+		state.Pop()
+		return pars.NewNestedError("Seq(2)", err)
+	}
+	state.Drop()
+	result.SetChildren(v)
+	return nil
+}
+
+func (g *grammar) exprA_2_2(state *pars.State, result *pars.Result) error {
+	state.Push()
+	err := g.exprA_2_4(state, &pars.Result{})
+	state.Pop()
+	if err == nil {
+		return pars.NewError("unexpected match", state.Position())
+	}
+	return nil
+}
+
+func (g *grammar) exprA_2_3(state *pars.State, result *pars.Result) error {
+	state.Push()
+	if err := g.exprA_2_5(state, result); err != nil {
+		if !state.Pushed() {
+			return pars.NewNestedError("Maybe", err)
+		}
+		state.Pop()
+		return nil
+	}
+	state.Drop()
+	return nil
+}
+
+func (g *grammar) exprA_2_4(state *pars.State, result *pars.Result) error {
+	if err := state.Request(1); err != nil {
+		return pars.NewNestedError("String(e)", err)
+	}
+	if state.Buffer()[0] != 'e' {
+		return pars.NewError("expected \"e\"", state.Position())
+	}
+	result.SetValue("e")
+	state.Advance()
+	return nil
+}
+
+func (g *grammar) exprA_2_5(state *pars.State, result *pars.Result) error {
+	if err := state.Request(1); err != nil {
+		return pars.NewNestedError("String(f)", err)
+	}
+	if state.Buffer()[0] != 'f' {
+		return pars.NewError("expected \"f\"", state.Position())
+	}
+	result.SetValue("f")
+	state.Advance()
+	return nil
+}
@@ -0,0 +1,69 @@
+package cases
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/go-pars/pars"
+	"github.com/go-pars/pars/peg"
+)
+
+var inputs = map[string][]string{
+	"A":   {"", "a", "ab", "abx", "xa", "abab", "ba", "c"},
+	"A1":  {"", "a", "b", "ab", "ba"},
+	"A_1": {"", "d", "cd", "cccd", "dff", "dfe", "c"},
+	"A_2": {"", "f", "e", "ff"},
+}
+
+func loadGrammar(t *testing.T) *peg.Grammar {
+	t.Helper()
+	text, err := ioutil.ReadFile("cases.peg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return peg.MustParse(string(text))
+}
+
+func TestGenerated(t *testing.T) {
+	generated, err := Rules(nil)
+	if err != nil {
+		t.Fatalf("Rules(): %v", err)
+	}
+	compiled, err := loadGrammar(t).Compile(nil)
+	if err != nil {
+		t.Fatalf("Compile(): %v", err)
+	}
+
+	for name, ins := range inputs {
+		for _, in := range ins {
+			gs, cs := pars.FromString(in), pars.FromString(in)
+			gr, gerr := generated[name].Parse(gs)
+			cr, cerr := compiled[name].Parse(cs)
+			if !reflect.DeepEqual(gr, cr) {
+				t.Errorf("%s.Parse(%q) = %#v, want %#v", name, in, gr, cr)
+			}
+			if !reflect.DeepEqual(gerr, cerr) {
+				t.Errorf("%s.Parse(%q): error `%v`, want `%v`", name, in, gerr, cerr)
+			}
+			if gs.Position() != cs.Position() {
+				t.Errorf("%s.Parse(%q): position %s, want %s", name, in, gs.Position(), cs.Position())
+			}
+		}
+	}
+}
+
+func TestUpToDate(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := loadGrammar(t).Generate(&buf, "cases"); err != nil {
+		t.Fatalf("Generate(): %v", err)
+	}
+	p, err := ioutil.ReadFile("cases.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), p) {
+		t.Errorf("cases.go is out of date, run go generate")
+	}
+}
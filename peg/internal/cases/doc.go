@@ -0,0 +1,6 @@
+// Package cases is a grammar generated by pars-gen for testing corner cases of
+// the generated code, such as the names of rules and loops with bodies which
+// match the empty string, against the grammar compiled with the peg package.
+package cases
+
+//go:generate go run ../../../cmd/pars-gen -o cases.go cases.peg
@@ -0,0 +1,377 @@
+package peg
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/go-ascii/ascii"
+	"github.com/go-pars/pars"
+)
+
+// Error is an error in a grammar text.
+type Error struct {
+	what string
+	pos  pars.Position
+}
+
+// NewError creates a new Error.
+func NewError(what string, pos pars.Position) error { return Error{what, pos} }
+
+// Error satisfies the error interface.
+func (e Error) Error() string { return fmt.Sprintf("%s at %s", e.what, e.pos) }
+
+// Position returns the position in the grammar text where the error occurred.
+func (e Error) Position() pars.Position { return e.pos }
+
+var (
+	comment = pars.Seq('#', pars.Line)
+	spacing = pars.Many(pars.Any(pars.Word(ascii.IsSpace), comment))
+	snake   = pars.Word(ascii.IsSnake)
+
+	singleQuoted = pars.Quoted('\'')
+	doubleQuoted = pars.Quoted('"')
+	bracketed    = pars.Between('[', ']')
+)
+
+func token(q interface{}) pars.Parser {
+	return pars.Seq(q, spacing).Child(0)
+}
+
+func identifierStart(c byte) bool { return ascii.IsLetter(c) || c == '_' }
+
+// identifier will match an identifier and return it as a Reference node.
+func identifier(state *pars.State, result *pars.Result) error {
+	pos := state.Position()
+	c, err := pars.Next(state)
+	if err != nil || !identifierStart(c) {
+		return pars.NewError("expected an identifier", pos)
+	}
+	if err := snake(state, result); err != nil {
+		return err
+	}
+	result.SetValue(&Node{Kind: Reference, Pos: pos, Text: string(result.Token)})
+	return nil
+}
+
+func unescape(p []byte, i int) (byte, int, error) {
+	if p[i] != '\\' {
+		return p[i], i + 1, nil
+	}
+	i++
+	switch c := p[i]; c {
+	case 'n':
+		return '\n', i + 1, nil
+	case 'r':
+		return '\r', i + 1, nil
+	case 't':
+		return '\t', i + 1, nil
+	case '\\', '\'', '"', '[', ']', '-', '^':
+		return c, i + 1, nil
+	case 'x':
+		if len(p) < i+3 {
+			return 0, i, errors.New("invalid hexadecimal escape")
+		}
+		n, err := strconv.ParseUint(string(p[i+1:i+3]), 16, 8)
+		if err != nil {
+			return 0, i, errors.New("invalid hexadecimal escape")
+		}
+		return byte(n), i + 3, nil
+	default:
+		return 0, i, fmt.Errorf("unknown escape sequence `\\%c`", c)
+	}
+}
+
+// literal will match a single or double quoted string and return it as a
+// Literal node.
+func literal(state *pars.State, result *pars.Result) error {
+	pos := state.Position()
+	c, err := pars.Next(state)
+	if err != nil || (c != '\'' && c != '"') {
+		return pars.NewError("expected a literal", pos)
+	}
+
+	quoted := singleQuoted
+	if c == '"' {
+		quoted = doubleQuoted
+	}
+	if err := quoted(state, result); err != nil {
+		return NewError("unterminated literal", pos)
+	}
+
+	p, s := result.Token, make([]byte, 0, len(result.Token))
+	for i := 0; i < len(p); {
+		var c byte
+		if c, i, err = unescape(p, i); err != nil {
+			return NewError(err.Error(), pos)
+		}
+		s = append(s, c)
+	}
+
+	result.SetValue(&Node{Kind: Literal, Pos: pos, Text: string(s)})
+	return nil
+}
+
+// class will match a bracketed character class and return it as a Class
+// node.
+func class(state *pars.State, result *pars.Result) error {
+	pos := state.Position()
+	c, err := pars.Next(state)
+	if err != nil || c != '[' {
+		return pars.NewError("expected a character class", pos)
+	}
+	if err := bracketed(state, result); err != nil {
+		return NewError("unterminated character class", pos)
+	}
+
+	n := &Node{Kind: Class, Pos: pos}
+	p, i := result.Token, 0
+	if len(p) > 0 && p[0] == '^' {
+		n.Negated = true
+		i++
+	}
+	if i == len(p) {
+		return NewError("empty character class", pos)
+	}
+
+	for i < len(p) {
+		var begin, end byte
+		if begin, i, err = unescape(p, i); err != nil {
+			return NewError(err.Error(), pos)
+		}
+		end = begin
+		if i+1 < len(p) && p[i] == '-' {
+			if end, i, err = unescape(p, i+1); err != nil {
+				return NewError(err.Error(), pos)
+			}
+			if end < begin {
+				return NewError("invalid character class range", pos)
+			}
+		}
+		n.Ranges = append(n.Ranges, Range{begin, end})
+	}
+
+	result.SetValue(n)
+	return nil
+}
+
+// dot will match a `.` and return it as a Dot node.
+func dot(state *pars.State, result *pars.Result) error {
+	pos := state.Position()
+	if err := pars.Byte('.')(state, result); err != nil {
+		return err
+	}
+	result.SetValue(&Node{Kind: Dot, Pos: pos})
+	return nil
+}
+
+func wrap(kind Kind, text string, child *Node) *Node {
+	return &Node{Kind: kind, Pos: child.Pos, Text: text, Children: []*Node{child}}
+}
+
+func suffixMap(result *pars.Result) error {
+	n := result.Children[0].Value.(*Node)
+	switch op := result.Children[1].Token; {
+	case op == nil:
+	case op[0] == '?':
+		n = wrap(Optional, "", n)
+	case op[0] == '*':
+		n = wrap(ZeroOrMore, "", n)
+	default:
+		n = wrap(OneOrMore, "", n)
+	}
+	result.SetValue(n)
+	return nil
+}
+
+func prefixMap(result *pars.Result) error {
+	n := result.Children[3].Value.(*Node)
+	if op := result.Children[2].Token; op != nil {
+		if op[0] == '&' {
+			n = wrap(And, "", n)
+		} else {
+			n = wrap(Not, "", n)
+		}
+		n.Pos = result.Children[1].Value.(pars.Position)
+	}
+	if label, ok := result.Children[0].Value.(*Node); ok {
+		n = wrap(Label, label.Text, n)
+		n.Pos = label.Pos
+	}
+	result.SetValue(n)
+	return nil
+}
+
+func nodes(children []pars.Result) []*Node {
+	v := make([]*Node, len(children))
+	for i, child := range children {
+		v[i] = child.Value.(*Node)
+	}
+	return v
+}
+
+func sequenceMap(pos pars.Position, result *pars.Result) *Node {
+	if len(result.Children) == 1 {
+		return result.Children[0].Value.(*Node)
+	}
+	return &Node{Kind: Sequence, Pos: pos, Children: nodes(result.Children)}
+}
+
+// mark will set the current position as the result value.
+func mark(state *pars.State, result *pars.Result) error {
+	result.SetValue(state.Position())
+	return nil
+}
+
+func sequence(state *pars.State, result *pars.Result) error {
+	pos := state.Position()
+	if err := prefixes(state, result); err != nil {
+		return err
+	}
+	result.SetValue(sequenceMap(pos, result))
+	return nil
+}
+
+func choiceMap(result *pars.Result) error {
+	if len(result.Children) == 1 {
+		*result = result.Children[0]
+		return nil
+	}
+	n := nodes(result.Children)
+	result.SetValue(&Node{Kind: Choice, Pos: n[0].Pos, Children: n})
+	return nil
+}
+
+// Grammar text parser parts.
+var (
+	expression pars.Parser
+
+	arrow  = token(pars.Any("<-", '='))
+	slash  = token(pars.Byte('/', '|'))
+	lparen = token('(')
+	rparen = token(')')
+	label  = pars.Seq(token(identifier), token(':')).Child(0)
+	header = pars.Seq(token(identifier), arrow).Child(0)
+
+	primary = pars.Any(
+		pars.Seq(token(identifier), pars.Not(arrow)).Child(0),
+		pars.Seq(lparen, &expression, rparen).Child(1),
+		token(literal),
+		token(class),
+		token(dot),
+	)
+	suffix = pars.Seq(primary, pars.Maybe(token(pars.Byte('?', '*', '+')))).Map(suffixMap)
+	prefix = pars.Seq(
+		pars.Maybe(label),
+		mark,
+		pars.Maybe(token(pars.Byte('&', '!'))),
+		suffix,
+	).Map(prefixMap)
+	prefixes = pars.Many(prefix)
+)
+
+func init() {
+	expression = pars.Delim(sequence, slash).Map(choiceMap)
+}
+
+func unexpected(state *pars.State) error {
+	// Attempt to find a more specific error for the unexpected token.
+	var e Error
+	for _, p := range []pars.Parser{literal, class} {
+		if _, err := p.Parse(state); errors.As(err, &e) {
+			return e
+		}
+	}
+	c, err := pars.Next(state)
+	if err != nil {
+		return NewError("unexpected end of grammar", state.Position())
+	}
+	return NewError(fmt.Sprintf("unexpected %s", strconv.QuoteRune(rune(c))), state.Position())
+}
+
+func walk(n *Node, f func(*Node)) {
+	f(n)
+	for _, child := range n.Children {
+		walk(child, f)
+	}
+}
+
+func validate(g *Grammar) error {
+	defined := make(map[string]*Rule)
+	for _, rule := range g.Rules {
+		if prev, ok := defined[rule.Name]; ok {
+			what := fmt.Sprintf("rule `%s` redefined (previously defined at %s)", rule.Name, prev.Pos)
+			return NewError(what, rule.Pos)
+		}
+		defined[rule.Name] = rule
+	}
+
+	var err error
+	for _, rule := range g.Rules {
+		walk(rule.Expr, func(n *Node) {
+			if _, ok := defined[n.Text]; err == nil && n.Kind == Reference && !ok {
+				err = NewError(fmt.Sprintf("undefined rule `%s`", n.Text), n.Pos)
+			}
+		})
+	}
+	return err
+}
+
+// Parse the given grammar text. A grammar consists of one or more rules in
+// the following PEG notation:
+//
+//   rule       <- name ( '<-' / '=' ) expression ';'?
+//   expression <- sequence ( ( '/' / '|' ) sequence )*
+//   sequence   <- prefix*
+//   prefix     <- ( name ':' )? ( '&' / '!' )? suffix
+//   suffix     <- primary ( '?' / '*' / '+' )?
+//   primary    <- name !( '<-' / '=' ) / '(' expression ')'
+//               / literal / class / '.'
+//
+// Literals are quoted with either `'` or `"`, classes are bracketed byte
+// ranges like `[a-z_]` or `[^"]`, and both may contain the escape sequences
+// `\n`, `\r`, `\t`, `\xHH`, and a backslash followed by a punctuation. A `#`
+// starts a comment which runs to the end of the line. All rule references
+// must be defined.
+func Parse(text string) (*Grammar, error) {
+	state := pars.FromString(text)
+	spacing(state, &pars.Result{})
+
+	g := &Grammar{}
+	for pars.End(state, nil) != nil {
+		result, err := header.Parse(state)
+		if err != nil {
+			if len(g.Rules) == 0 {
+				return nil, NewError("expected a rule definition", state.Position())
+			}
+			return nil, unexpected(state)
+		}
+		ref := result.Value.(*Node)
+
+		result, err = expression.Parse(state)
+		if err != nil {
+			return nil, unexpected(state)
+		}
+		token(';')(state, &pars.Result{})
+
+		g.Rules = append(g.Rules, &Rule{ref.Text, ref.Pos, result.Value.(*Node)})
+	}
+
+	if len(g.Rules) == 0 {
+		return nil, NewError("expected a rule definition", state.Position())
+	}
+
+	if err := validate(g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// MustParse is like Parse but panics if the grammar text cannot be parsed.
+func MustParse(text string) *Grammar {
+	g, err := Parse(text)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
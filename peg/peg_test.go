@@ -0,0 +1,196 @@
+package peg
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/go-pars/pars"
+)
+
+func same(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+func at(line, byte int) pars.Position {
+	return pars.Position{Line: line, Byte: byte}
+}
+
+const arithmetic = `
+# Simple arithmetic with the usual precedence.
+Expr    <- Sum !.
+Sum     <- head:Product tail:(('+' / '-') Product)*
+Product <- head:Value tail:(("*" | "/") Value)*
+Value   <- Number / '(' Sum ')'
+Number  = [0-9]+ ;
+`
+
+func fold(result *pars.Result) error {
+	v := result.Get("head").Value.(int)
+	for _, op := range result.Get("tail").Children {
+		n := op.Children[1].Value.(int)
+		switch op.Children[0].Value.(string) {
+		case "+":
+			v += n
+		case "-":
+			v -= n
+		case "*":
+			v *= n
+		case "/":
+			v /= n
+		}
+	}
+	result.SetValue(v)
+	return nil
+}
+
+var arithmeticActions = map[string]pars.Map{
+	"Expr":    pars.Child(0),
+	"Sum":     fold,
+	"Product": fold,
+	"Value": func(result *pars.Result) error {
+		if result.Children != nil {
+			*result = result.Children[1]
+		}
+		return nil
+	},
+	"Number": func(result *pars.Result) error {
+		if err := pars.Cat(result); err != nil {
+			return err
+		}
+		n, err := strconv.Atoi(string(result.Token))
+		result.SetValue(n)
+		return err
+	},
+}
+
+var arithmeticTests = []struct {
+	in  string
+	out int
+}{
+	{"42", 42},
+	{"1+2*3", 7},
+	{"(1+2)*3", 9},
+	{"10/2-3", 2},
+	{"2*(3+4)*5", 70},
+}
+
+func TestCompile(t *testing.T) {
+	rules, err := Compile(arithmetic, arithmeticActions)
+	if err != nil {
+		t.Fatalf("Compile(): %v", err)
+	}
+
+	for _, tt := range arithmeticTests {
+		result, err := rules["Expr"].Parse(pars.FromString(tt.in))
+		if err != nil {
+			t.Errorf("Expr.Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if result.Value != tt.out {
+			t.Errorf("Expr.Parse(%q) = %v, want %d", tt.in, result.Value, tt.out)
+		}
+	}
+
+	for _, in := range []string{"", "1+", "(1", "1)", "a"} {
+		if _, err := rules["Expr"].Parse(pars.FromString(in)); err == nil {
+			t.Errorf("Expr.Parse(%q): expected error", in)
+		}
+	}
+}
+
+var compileTests = []struct {
+	grammar string
+	pass    []string
+	fail    []string
+}{
+	{`A <- 'a' &'b' . !.`, []string{"ab"}, []string{"a", "ac", "abc"}},
+	{`A <- 'a'? 'b'+ !.`, []string{"b", "abbb"}, []string{"a", "aa", "ba"}},
+	{`A <- [^a-c\]] !.`, []string{"d", "["}, []string{"a", "c", "]", ""}},
+	{`A <- "\x41\n" () !.`, []string{"A\n"}, []string{"A", "a\n"}},
+	{`A <- B* !. B <- 'x' / 'y'`, []string{"", "xyx"}, []string{"z"}},
+}
+
+func TestCompileExpressions(t *testing.T) {
+	for _, tt := range compileTests {
+		rules, err := Compile(tt.grammar, nil)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.grammar, err)
+			continue
+		}
+		for _, in := range tt.pass {
+			if _, err := rules["A"].Parse(pars.FromString(in)); err != nil {
+				t.Errorf("%q: A.Parse(%q): %v", tt.grammar, in, err)
+			}
+		}
+		for _, in := range tt.fail {
+			if _, err := rules["A"].Parse(pars.FromString(in)); err == nil {
+				t.Errorf("%q: A.Parse(%q): expected error", tt.grammar, in)
+			}
+		}
+	}
+}
+
+func TestGrammarString(t *testing.T) {
+	g := MustParse(arithmetic)
+	e := `Expr <- Sum !.
+Sum <- head:Product tail:(('+' / '-') Product)*
+Product <- head:Value tail:(('*' / '/') Value)*
+Value <- Number / '(' Sum ')'
+Number <- [0-9]+
+`
+	if s := g.String(); s != e {
+		t.Errorf("g.String() = %q, want %q", s, e)
+	}
+
+	h := MustParse(g.String())
+	if s := h.String(); s != e {
+		t.Errorf("MustParse(g.String()).String() = %q, want %q", s, e)
+	}
+
+	if g.Start().Name != "Expr" || g.Rule("Value") == nil || g.Rule("Missing") != nil {
+		t.Errorf("unexpected rule lookup results")
+	}
+}
+
+var parseErrorTests = []struct {
+	grammar string
+	err     error
+}{
+	{``, NewError("expected a rule definition", at(0, 0))},
+	{`# nothing`, NewError("expected a rule definition", at(0, 9))},
+	{`'a'`, NewError("expected a rule definition", at(0, 0))},
+	{"A <- 'a'\n  @", NewError("unexpected '@'", at(1, 2))},
+	{"A <- 'a' )", NewError("unexpected ')'", at(0, 9))},
+	{"A <- ('a'", NewError("unexpected '('", at(0, 5))},
+	{"A <- 'a", NewError("unterminated literal", at(0, 5))},
+	{"A <- [a", NewError("unterminated character class", at(0, 5))},
+	{"A <- []", NewError("empty character class", at(0, 5))},
+	{"A <- [z-a]", NewError("invalid character class range", at(0, 5))},
+	{`A <- 'a\q'`, NewError("unknown escape sequence `\\q`", at(0, 5))},
+	{`A <- 'a\xZZ'`, NewError("invalid hexadecimal escape", at(0, 5))},
+	{"A <- B", NewError("undefined rule `B`", at(0, 5))},
+	{"A <- 'a'\nA <- 'b'", NewError(
+		"rule `A` redefined (previously defined at line 1, byte 1)",
+		at(1, 0),
+	)},
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tt := range parseErrorTests {
+		_, err := Parse(tt.grammar)
+		if !same(err, tt.err) {
+			t.Errorf("Parse(%q) = `%v`, want `%v`", tt.grammar, err, tt.err)
+		}
+	}
+
+	var e Error
+	if _, err := Compile("A <- B", nil); !errors.As(err, &e) || e.Position() != (at(0, 5)) {
+		t.Errorf("Compile() = %v, want error at %v", err, at(0, 5))
+	}
+
+	if _, err := Compile("A <- 'a'", map[string]pars.Map{"B": pars.ToString}); err == nil {
+		t.Errorf("Compile(): expected error")
+	}
+}
@@ -8,8 +8,14 @@ import (
 
 func evaluate(result *pars.Result) error {
 	op := result.Children[0].Token[0]
-	a := result.Children[2].Value.(float64)
-	b := result.Children[4].Value.(float64)
+	a, ok := result.Children[2].Value.(float64)
+	if !ok {
+		return errors.New("left operand is not a number")
+	}
+	b, ok := result.Children[4].Value.(float64)
+	if !ok {
+		return errors.New("right operand is not a number")
+	}
 	switch op {
 	case '+':
 		result.SetValue(a + b)
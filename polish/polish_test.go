@@ -29,3 +29,38 @@ func TestPolish(t *testing.T) {
 		}
 	}
 }
+
+func operation(op byte, a, b interface{}) *pars.Result {
+	return &pars.Result{Children: []pars.Result{
+		{Token: []byte{op}}, {}, {Value: a}, {}, {Value: b},
+	}}
+}
+
+var evaluateErrorTestCases = []struct {
+	in  *pars.Result
+	err string
+}{
+	{operation('+', "2", 2.0), "left operand is not a number"},
+	{operation('+', 2.0, nil), "right operand is not a number"},
+	{operation('^', 2.0, 2.0), "operator matched a wrong byte"},
+}
+
+func TestEvaluateError(t *testing.T) {
+	for _, tt := range evaluateErrorTestCases {
+		err := evaluate(tt.in)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("evaluate(%c) = %v, wanted %q", tt.in.Children[0].Token[0], err, tt.err)
+		}
+	}
+}
+
+func BenchmarkPolish(b *testing.B) {
+	p := []byte("+ * - 5 6 7 / + 1.5 2.5 - 10 * 2 3")
+	b.ReportAllocs()
+	b.SetBytes(int64(len(p)))
+	for i := 0; i < b.N; i++ {
+		if _, err := Expression.Parse(pars.FromBytes(p)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package pars
+
+import "sync"
+
+// resultPool recycles the slices of child results of a Parser which failed
+// to match. The slice of a matching Parser is handed over to the result so a
+// new slice is only allocated for each match instead of each attempt.
+type resultPool struct {
+	pool sync.Pool
+	size int
+}
+
+func newResultPool(size int) *resultPool {
+	return &resultPool{sync.Pool{New: func() interface{} { return new([]Result) }}, size}
+}
+
+// get returns a holder of a zeroed slice of results.
+func (p *resultPool) get() *[]Result {
+	h := p.pool.Get().(*[]Result)
+	if *h == nil {
+		*h = make([]Result, p.size)
+	}
+	return h
+}
+
+// keep hands over the slice to the caller and recycles the holder.
+func (p *resultPool) keep(h *[]Result) []Result {
+	v := *h
+	*h = nil
+	p.pool.Put(h)
+	return v
+}
+
+// release recycles the holder along with the slice.
+func (p *resultPool) release(h *[]Result) {
+	v := *h
+	for i := range v {
+		v[i] = Result{}
+	}
+	p.pool.Put(h)
+}
@@ -20,3 +20,28 @@ func TestPositionLess(t *testing.T) {
 		}
 	}
 }
+
+var advancePositionTests = []struct {
+	in  string
+	n   []int
+	pos Position
+}{
+	{"abc", []int{1, 1, 1}, Position{0, 3}},
+	{"abc", []int{3}, Position{0, 3}},
+	{"a\nbc", []int{1, 1}, Position{1, 0}},
+	{"a\nbc\n\nde", []int{8}, Position{3, 2}},
+	{"a\nbc\n\nde", []int{5, 3}, Position{3, 2}},
+	{"ab\n", []int{3}, Position{1, 0}},
+}
+
+func TestAdvancePosition(t *testing.T) {
+	for _, tt := range advancePositionTests {
+		s := FromString(tt.in)
+		for _, n := range tt.n {
+			Skip(s, n)
+		}
+		if pos := s.Position(); pos != tt.pos {
+			t.Errorf("%q advanced by %v: position = %v, want %v", tt.in, tt.n, pos, tt.pos)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package pars
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNeedMore is returned when a push state has run out of input which has
+// not been closed yet. The parse can be resumed once more input is fed.
+var ErrNeedMore = errors.New("more input is needed")
+
+// NewPushState creates a new state which is fed input with Feed instead of
+// reading from an io.Reader, for parsing data which arrives in fragments such
+// as from a network connection handled by an event loop. Parsing the state
+// never blocks: a Parser which runs out of input will fail and ParsePartial
+// will report ErrNeedMore instead of the error until CloseInput is called.
+func NewPushState() *State {
+	return &State{
+		rd:   nil,
+		buf:  nil,
+		off:  0,
+		end:  -1,
+		err:  nil,
+		pos:  Position{0, 0},
+		stk:  newStack(),
+		size: bufferReadSize,
+	}
+}
+
+// Feed appends the given bytes to the input of a push state. The bytes are
+// copied so the given slice may be reused. The bytes before the commit point
+// are discarded as the buffer grows, as with a state reading from an
+// io.Reader. Feed panics if the input has been closed or if the state was not
+// created with NewPushState.
+func (s *State) Feed(p []byte) {
+	if s.rd != nil || s.err != nil {
+		panic("Feed called for a state which is not an open push state")
+	}
+	s.grow()
+	s.buf = append(s.buf, p...)
+}
+
+// CloseInput marks the end of the input of a push state. Parsers will treat
+// the end of the fed bytes as the end of the input afterwards.
+func (s *State) CloseInput() {
+	if s.rd == nil && s.err == nil {
+		s.err = io.EOF
+	}
+}
+
+// ParsePartial applies the Parser to the state like Parse. If the Parser ran
+// out of input of a push state which is not closed, the state is restored to
+// the position before the Parser was applied and ErrNeedMore is returned, so
+// the call can be repeated once more input is fed. This is the case even if
+// the Parser matched, as the match might change with more input. Otherwise,
+// the bytes before the end of the match are released, so only the bytes of
+// the unfinished match are kept between calls. Combine with Memoize to avoid
+// applying the parts of the Parser which already matched again.
+func (p Parser) ParsePartial(s *State) (Result, error) {
+	s.starved = false
+	s.Push()
+	result, err := p.Parse(s)
+	if s.starved {
+		s.Pop()
+		return Result{}, ErrNeedMore
+	}
+	if err != nil {
+		s.Pop()
+		return result, err
+	}
+	s.Drop()
+	s.Commit()
+
+	// The memoized results before the commit point are no longer needed.
+	s.memo = nil
+	return result, nil
+}
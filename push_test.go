@@ -0,0 +1,131 @@
+package pars
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-ascii/ascii"
+)
+
+func TestPushState(t *testing.T) {
+	in := "a=1\nbc=22\r\nd=3"
+	state := NewPushState()
+	v := []string{}
+	needs := 0
+	for i := 0; i <= len(in); i++ {
+		if i < len(in) {
+			state.Feed([]byte{in[i]})
+		} else {
+			state.CloseInput()
+		}
+		for len(v) < 3 {
+			result, err := record.ParsePartial(state)
+			if err == ErrNeedMore {
+				needs++
+				break
+			}
+			if err != nil {
+				t.Fatalf("record.ParsePartial() after %q: %v", in[:i], err)
+			}
+			v = append(v, result.Value.(string))
+		}
+	}
+	if e := []string{"a:1", "bc:22", "d:3"}; !same(v, e) {
+		t.Errorf("records = %v, want %v", v, e)
+	}
+	if needs != len(in) {
+		t.Errorf("needed more input %d times, want %d", needs, len(in))
+	}
+	if err := End(state, nil); err != nil {
+		t.Errorf("state is not at end: %v", err)
+	}
+
+	// Errors are reported once the Parser fails before running out of input.
+	state = NewPushState()
+	state.Feed([]byte("a="))
+	if _, err := record.ParsePartial(state); err != ErrNeedMore {
+		t.Errorf("record.ParsePartial() = %v, want %v", err, ErrNeedMore)
+	}
+	state.Feed([]byte("x"))
+	var pe Error
+	if _, err := record.ParsePartial(state); !errors.As(err, &pe) || pe.Position() != (Position{0, 2}) {
+		t.Errorf("record.ParsePartial() = %v, want error at %v", err, Position{0, 2})
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected Feed to panic after CloseInput")
+		}
+	}()
+	state.CloseInput()
+	state.Feed([]byte("x"))
+}
+
+func TestPushStateEnd(t *testing.T) {
+	eol := Parser(EOL)
+	state := NewPushState()
+	state.Feed([]byte("a\r"))
+	Skip(state, 1)
+	if err := End(state, nil); err == nil {
+		t.Errorf("End() matched before the end of the input")
+	}
+	if _, err := eol.ParsePartial(state); err != ErrNeedMore {
+		t.Fatalf("EOL.ParsePartial() = %v, want %v", err, ErrNeedMore)
+	}
+	state.Feed([]byte("\n"))
+	result, err := eol.ParsePartial(state)
+	if err != nil || string(result.Token) != "\r\n" {
+		t.Fatalf("EOL.ParsePartial() = %q, %v, want %q", result.Token, err, "\r\n")
+	}
+	if err := End(state, nil); err != ErrNeedMore {
+		t.Errorf("End() = %v, want %v", err, ErrNeedMore)
+	}
+	if _, err := eol.ParsePartial(state); err != ErrNeedMore {
+		t.Errorf("EOL.ParsePartial() = %v, want %v", err, ErrNeedMore)
+	}
+	state.CloseInput()
+	if err := End(state, nil); err != nil {
+		t.Errorf("End() = %v, want nil", err)
+	}
+	if result, err := eol.ParsePartial(state); err != nil || result.Token != nil {
+		t.Errorf("EOL.ParsePartial() = %q, %v, want end of state", result.Token, err)
+	}
+}
+
+func TestPushStateMemoize(t *testing.T) {
+	calls := 0
+	word := Word(ascii.IsLetter)
+	key := Memoize(func(state *State, result *Result) error {
+		calls++
+		return word(state, result)
+	})
+	p := Seq(key, '=', Word(ascii.IsDigit), ';')
+
+	state := NewPushState()
+	state.Feed([]byte("key"))
+	if _, err := p.ParsePartial(state); err != ErrNeedMore {
+		t.Fatalf("p.ParsePartial() = %v, want %v", err, ErrNeedMore)
+	}
+	for _, s := range []string{"=", "12", "34", ";"} {
+		state.Feed([]byte(s))
+		result, err := p.ParsePartial(state)
+		if s != ";" {
+			if err != ErrNeedMore {
+				t.Fatalf("p.ParsePartial() = %v, want %v", err, ErrNeedMore)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k, v := string(result.Children[0].Token), string(result.Children[2].Token); k != "key" || v != "1234" {
+			t.Errorf("result = %s=%s, want key=1234", k, v)
+		}
+	}
+
+	// The key is parsed again until the byte after it arrives, and then
+	// reused for the rest of the attempts.
+	if calls != 2 {
+		t.Errorf("memoized parser applied %d times, want 2", calls)
+	}
+}
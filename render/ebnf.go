@@ -0,0 +1,115 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func precedence(e *expr) int {
+	switch e.kind {
+	case exprAlt:
+		return 0
+	case exprSeq, exprList:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// operand returns the expression enclosed in parentheses if it binds looser
+// than the given precedence.
+func operand(e *expr, prec int) string {
+	if precedence(e) < prec {
+		return fmt.Sprintf("(%s)", e)
+	}
+	return e.String()
+}
+
+// placeholder is the nonterminal written for an operand which only consists
+// of comments, since EBNF requires at least one term in each alternative.
+const placeholder = "opaque"
+
+// opaque tests if the expression only consists of comments.
+func (e *expr) opaque() bool {
+	switch e.kind {
+	case exprComment:
+		return true
+	case exprSeq:
+		for _, child := range e.children {
+			if !child.opaque() {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// term returns the operand like operand, preceded by the placeholder if it
+// only consists of comments.
+func term(e *expr, prec int) string {
+	if e.opaque() {
+		return fmt.Sprintf("%s %s", placeholder, e)
+	}
+	return operand(e, prec)
+}
+
+// String returns the expression in EBNF notation.
+func (e *expr) String() string {
+	switch e.kind {
+	case exprEmpty:
+		return "/* empty */"
+	case exprName, exprTerminal:
+		return e.text
+	case exprComment:
+		return fmt.Sprintf("/* %s */", e.text)
+	case exprSeq, exprAlt:
+		s := make([]string, len(e.children))
+		for i, child := range e.children {
+			if e.kind == exprAlt {
+				s[i] = term(child, 1)
+			} else {
+				s[i] = operand(child, 2)
+			}
+		}
+		if e.kind == exprAlt {
+			return strings.Join(s, " | ")
+		}
+		return strings.Join(s, " ")
+	case exprOpt:
+		return fmt.Sprintf("[ %s ]", term(e.children[0], 0))
+	case exprRep:
+		return fmt.Sprintf("{ %s }", term(e.children[0], 0))
+	default:
+		item, delim := term(e.children[0], 2), term(e.children[1], 2)
+		return fmt.Sprintf("%s { %s %s }", item, delim, item)
+	}
+}
+
+// EBNF writes the given rules in the EBNF notation used by the Go language
+// specification, one production per line in the given order. Each rule must
+// be created with pars.Named and references to other named Parsers are
+// written as their names. Constructs which cannot be expressed in EBNF, such
+// as pars.Int or pars.Not, are written as comments. A comment standing in for
+// an alternative, an option, or a repetition on its own is preceded by the
+// nonterminal `opaque` so the output remains valid EBNF.
+func EBNF(w io.Writer, qs ...interface{}) error {
+	nodes, err := rules(qs)
+	if err != nil {
+		return err
+	}
+	buf := bytes.Buffer{}
+	for _, n := range nodes {
+		e := ruleExpr(n)
+		if e.kind == exprEmpty {
+			fmt.Fprintf(&buf, "%s = .\n", n.Name)
+		} else {
+			fmt.Fprintf(&buf, "%s = %s .\n", n.Name, e)
+		}
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,208 @@
+// Package render generates documentation for grammars built with pars. A
+// grammar is given as a list of rules created with pars.Named and can be
+// rendered as EBNF text or as SVG railroad diagrams. The output only depends
+// on the structure of the grammar so it is suitable for golden file testing.
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-pars/pars"
+)
+
+type exprKind int
+
+const (
+	exprEmpty exprKind = iota
+	exprName
+	exprTerminal
+	exprComment
+	exprSeq
+	exprAlt
+	exprOpt
+	exprRep
+	exprList
+)
+
+// expr is the simplified form of a parser used for rendering.
+//   text:     the name, terminal, or comment text.
+//   children: the operands, which are the item and separator for exprList.
+type expr struct {
+	kind     exprKind
+	text     string
+	children []*expr
+}
+
+var empty = &expr{kind: exprEmpty}
+
+func comment(format string, args ...interface{}) *expr {
+	return &expr{kind: exprComment, text: fmt.Sprintf(format, args...)}
+}
+
+func terminal(s string) *expr {
+	return &expr{kind: exprTerminal, text: strconv.Quote(s)}
+}
+
+func seq(children ...*expr) *expr {
+	v := []*expr{}
+	for _, child := range children {
+		if child.kind != exprEmpty {
+			v = append(v, child)
+		}
+	}
+	switch len(v) {
+	case 0:
+		return empty
+	case 1:
+		return v[0]
+	default:
+		return &expr{kind: exprSeq, children: v}
+	}
+}
+
+func alt(children ...*expr) *expr {
+	if len(children) == 1 {
+		return children[0]
+	}
+	return &expr{kind: exprAlt, children: children}
+}
+
+// funcName returns the package qualified name of the function.
+func funcName(name string) string {
+	return name[strings.LastIndexByte(name, '/')+1:]
+}
+
+type converter struct {
+	visited map[*pars.Node]bool
+}
+
+func ruleExpr(rule *pars.Node) *expr {
+	c := &converter{make(map[*pars.Node]bool)}
+	return c.convert(rule.Children()[0])
+}
+
+func (c *converter) convert(n *pars.Node) *expr {
+	// Unnamed recursive parsers cannot be expressed without a name.
+	if c.visited[n] {
+		return comment("recursion")
+	}
+	c.visited[n] = true
+	defer delete(c.visited, n)
+
+	children := n.Children()
+	exprs := func() []*expr {
+		v := make([]*expr, len(children))
+		for i, child := range children {
+			v[i] = c.convert(child)
+		}
+		return v
+	}
+
+	switch n.Kind {
+	case pars.KindNamed:
+		return &expr{kind: exprName, text: n.Name}
+	case pars.KindMap, pars.KindCapture, pars.KindRef, pars.KindWrap:
+		if len(children) == 0 {
+			return comment("nil")
+		}
+		return c.convert(children[0])
+	case pars.KindSeq:
+		return seq(exprs()...)
+	case pars.KindLengthPrefixed:
+		v := exprs()
+		if len(v) == 1 {
+			return seq(v[0], comment("prefixed bytes"))
+		}
+		return seq(v[0], comment("prefixed %s", v[1]))
+	case pars.KindAny:
+		return alt(exprs()...)
+	case pars.KindMaybe:
+		return &expr{kind: exprOpt, children: exprs()}
+	case pars.KindMany:
+		return &expr{kind: exprRep, children: exprs()}
+	case pars.KindDelim:
+		return &expr{kind: exprList, children: exprs()}
+	case pars.KindDry:
+		return comment("followed by %s", exprs()[0])
+	case pars.KindNot:
+		return comment("not %s", exprs()[0])
+	case pars.KindByte:
+		if n.Literal == nil {
+			return comment("any byte")
+		}
+		v := make([]*expr, len(n.Literal))
+		for i, b := range n.Literal {
+			v[i] = terminal(string([]byte{b}))
+		}
+		return alt(v...)
+	case pars.KindRune:
+		if n.Runes == nil {
+			return comment("any rune")
+		}
+		v := make([]*expr, len(n.Runes))
+		for i, r := range n.Runes {
+			v[i] = terminal(string(r))
+		}
+		return alt(v...)
+	case pars.KindByteRange:
+		return &expr{kind: exprTerminal, text: fmt.Sprintf(
+			"%s … %s",
+			strconv.Quote(string([]byte{n.Literal[0]})),
+			strconv.Quote(string([]byte{n.Literal[1]})),
+		)}
+	case pars.KindRuneRange:
+		return &expr{kind: exprTerminal, text: fmt.Sprintf(
+			"%s … %s",
+			strconv.Quote(string(n.Runes[0])),
+			strconv.Quote(string(n.Runes[1])),
+		)}
+	case pars.KindBytes, pars.KindString:
+		if len(n.Literal) == 0 {
+			return empty
+		}
+		return terminal(string(n.Literal))
+	case pars.KindRunes:
+		if len(n.Runes) == 0 {
+			return empty
+		}
+		return terminal(string(n.Runes))
+	case pars.KindFilter:
+		return comment("%s", funcName(n.Name))
+	case pars.KindWord:
+		return comment("word of %s", funcName(n.Name))
+	case pars.KindUntil:
+		switch {
+		case n.Name != "":
+			return comment("until %s", funcName(n.Name))
+		case n.Literal != nil:
+			return comment("until %s", strconv.Quote(string(n.Literal)))
+		default:
+			return comment("until %s", exprs()[0])
+		}
+	case pars.KindBetween:
+		return seq(
+			terminal(string([]byte{n.Literal[0]})),
+			comment("escaped text"),
+			terminal(string([]byte{n.Literal[1]})),
+		)
+	case pars.KindPrimitive:
+		return comment("%s", n.Name)
+	default:
+		return comment("%s", funcName(n.Name))
+	}
+}
+
+// rules inspects the given rules and checks that they are named.
+func rules(qs []interface{}) ([]*pars.Node, error) {
+	nodes := make([]*pars.Node, len(qs))
+	for i, q := range qs {
+		n := pars.Inspect(q)
+		if n.Kind != pars.KindNamed {
+			return nil, fmt.Errorf("rule %d is not created with pars.Named: %s", i, n)
+		}
+		nodes[i] = n
+	}
+	return nodes, nil
+}
@@ -0,0 +1,121 @@
+package render
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-ascii/ascii"
+	"github.com/go-pars/pars"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// A small query language such as `select a, b from t where a = 'x' limit 10`.
+var (
+	condition pars.Parser
+
+	identifier = pars.Named("identifier", pars.Seq(pars.Letter, pars.Maybe(pars.Word(ascii.IsSnake))))
+	value      = pars.Named("value", pars.Any(pars.Quoted('\''), pars.Int, "null"))
+	comparison = pars.Named("comparison", pars.Seq(identifier, pars.Any("=", "!=", '<', '>'), value))
+	columns    = pars.Named("columns", pars.Any('*', pars.Delim(identifier, ',')))
+	query      = pars.Named("query", pars.Seq(
+		"select", columns,
+		"from", identifier,
+		pars.Maybe(pars.Seq("where", &condition)),
+		pars.Maybe(pars.Seq("limit", pars.Int)),
+		pars.End,
+	))
+)
+
+var queryRules []interface{}
+
+func init() {
+	condition = pars.Named("condition", pars.Delim(
+		pars.Any(comparison, pars.Seq('(', &condition, ')')),
+		pars.Any("and", "or"),
+	))
+	queryRules = []interface{}{query, columns, condition, comparison, identifier, value}
+}
+
+func golden(t *testing.T, name string, p []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := ioutil.WriteFile(path, p, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(p, e) {
+		t.Errorf("output differs from %s, run go test with -update to regenerate:\n%s", path, p)
+	}
+}
+
+func TestEBNF(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := EBNF(&buf, queryRules...); err != nil {
+		t.Fatalf("EBNF(): %v", err)
+	}
+	golden(t, "query.ebnf", buf.Bytes())
+}
+
+func TestSVG(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := SVG(&buf, queryRules...); err != nil {
+		t.Fatalf("SVG(): %v", err)
+	}
+	golden(t, "query.svg", buf.Bytes())
+
+	// The output must not depend on anything but the grammar.
+	again := bytes.Buffer{}
+	SVG(&again, queryRules...)
+	if !bytes.Equal(buf.Bytes(), again.Bytes()) {
+		t.Errorf("SVG() is not deterministic")
+	}
+}
+
+var exprTests = []struct {
+	q   interface{}
+	out string
+}{
+	{pars.Seq('a', pars.Any('b', 'c')), `"a" ("b" | "c")`},
+	{pars.Any(pars.Seq('a', 'b'), 'c'), `"a" "b" | "c"`},
+	{pars.Byte('+', '-'), `"+" | "-"`},
+	{pars.Many(pars.Maybe("x")), `{ [ "x" ] }`},
+	{pars.Delim(pars.Digit, pars.Any(',', ';')), `"0" … "9" { ("," | ";") "0" … "9" }`},
+	{pars.Seq('"', pars.Until('"')), `"\"" /* until "\"" */`},
+	{pars.Seq(pars.Not("--"), pars.Byte()), `/* not "--" */ /* any byte */`},
+	{pars.Seq("ä", pars.RuneRange('α', 'ω')), `"ä" "α" … "ω"`},
+	{pars.Epsilon, `/* Epsilon */`},
+	{pars.Any("a", pars.Int, pars.Seq(pars.Not("b"), pars.Byte())), `"a" | opaque /* Int */ | opaque /* not "b" */ /* any byte */`},
+	{pars.Many(pars.Number), `{ opaque /* Number */ }`},
+	{pars.Delim(pars.Int, ','), `opaque /* Int */ { "," opaque /* Int */ }`},
+	{pars.Nested(pars.Seq('a', 'b'), 3), `"a" "b"`},
+	{pars.Memoize(pars.Maybe('a')), `[ "a" ]`},
+	{pars.LengthPrefixed(pars.Uint8, nil, 255), `/* Uint8 */ /* prefixed bytes */`},
+	{pars.LengthPrefixed(pars.Uint8, pars.Many('a'), 255), `/* Uint8 */ /* prefixed { "a" } */`},
+}
+
+func TestExpr(t *testing.T) {
+	for _, tt := range exprTests {
+		e := ruleExpr(pars.Inspect(pars.Named("rule", tt.q)))
+		if s := e.String(); s != tt.out {
+			t.Errorf("expr = %s, want %s", s, tt.out)
+		}
+	}
+}
+
+func TestUnnamed(t *testing.T) {
+	if err := EBNF(ioutil.Discard, pars.Seq('a')); err == nil {
+		t.Errorf("EBNF(): expected error")
+	}
+	if err := SVG(ioutil.Discard, pars.Seq('a')); err == nil {
+		t.Errorf("SVG(): expected error")
+	}
+}
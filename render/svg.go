@@ -0,0 +1,272 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// Dimensions of the railroad diagrams in pixels.
+const (
+	charWidth  = 8
+	boxHeight  = 22
+	padding    = 10
+	gap        = 10
+	arc        = 10
+	spacing    = 10
+	margin     = 20
+	titleSpace = 30
+)
+
+const svgStyle = `<style>
+path { fill: none; stroke: #222; stroke-width: 1.5; }
+rect { fill: #fff; stroke: #222; stroke-width: 1.5; }
+rect.terminal { fill: #eef; }
+text { font-family: monospace; font-size: 13px; text-anchor: middle; }
+text.title { font-weight: bold; text-anchor: start; }
+text.comment { font-style: italic; }
+</style>
+`
+
+// layout is an expression with its computed size. The entry and exit of a
+// layout are on its baseline, with up and down being the extent above and
+// below the baseline. The offsets are the baselines of the branches of an
+// alternative or the loop line of a repetition relative to the baseline.
+type layout struct {
+	e        *expr
+	w        int
+	up       int
+	down     int
+	children []*layout
+	offsets  []int
+}
+
+func textWidth(s string) int {
+	return utf8.RuneCountInString(s)*charWidth + 2*padding
+}
+
+func max(a, b int) int {
+	if a < b {
+		return b
+	}
+	return a
+}
+
+func newAlt(e *expr, children []*layout) *layout {
+	l := &layout{e: e, children: children}
+	l.up, l.down = children[0].up, children[0].down
+	l.offsets = []int{0}
+	for _, child := range children {
+		l.w = max(l.w, child.w)
+	}
+	for _, child := range children[1:] {
+		off := max(l.down+spacing+child.up, 2*arc)
+		l.offsets = append(l.offsets, off)
+		l.down = off + child.down
+	}
+	l.w += 4 * arc
+	return l
+}
+
+func newLoop(e *expr, item, delim *layout) *layout {
+	l := &layout{e: e, children: []*layout{item}, up: item.up}
+	l.w = item.w
+	off := item.down + spacing
+	if delim != nil {
+		l.children = append(l.children, delim)
+		l.w = max(l.w, delim.w)
+		off += delim.up
+	}
+	off = max(off, 2*arc)
+	l.offsets = []int{off}
+	l.down = off
+	if delim != nil {
+		l.down += delim.down
+	}
+	l.w += 4 * arc
+	return l
+}
+
+func newLayout(e *expr) *layout {
+	switch e.kind {
+	case exprEmpty:
+		return &layout{e: e}
+	case exprName, exprTerminal, exprComment:
+		return &layout{e: e, w: textWidth(e.text), up: boxHeight / 2, down: boxHeight / 2}
+	case exprSeq:
+		l := &layout{e: e}
+		for i, child := range e.children {
+			c := newLayout(child)
+			l.children = append(l.children, c)
+			if i > 0 {
+				l.w += gap
+			}
+			l.w += c.w
+			l.up, l.down = max(l.up, c.up), max(l.down, c.down)
+		}
+		return l
+	case exprAlt:
+		children := make([]*layout, len(e.children))
+		for i, child := range e.children {
+			children[i] = newLayout(child)
+		}
+		return newAlt(e, children)
+	case exprOpt:
+		return newAlt(e, []*layout{newLayout(e.children[0]), newLayout(empty)})
+	case exprRep:
+		loop := newLoop(&expr{kind: exprList, children: e.children}, newLayout(e.children[0]), nil)
+		return newAlt(e, []*layout{loop, newLayout(empty)})
+	default:
+		return newLoop(e, newLayout(e.children[0]), newLayout(e.children[1]))
+	}
+}
+
+type svgWriter struct {
+	bytes.Buffer
+}
+
+func (b *svgWriter) path(format string, args ...interface{}) {
+	fmt.Fprintf(b, `<path d="`+format+`"/>`+"\n", args...)
+}
+
+func (b *svgWriter) line(x0, x1, y int) {
+	if x0 != x1 {
+		b.path("M%d %dH%d", x0, y, x1)
+	}
+}
+
+func escape(s string) string {
+	buf := bytes.Buffer{}
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+func (b *svgWriter) text(class string, x, y int, s string) {
+	fmt.Fprintf(b, `<text class="%s" x="%d" y="%d">%s</text>`+"\n", class, x, y, escape(s))
+}
+
+func (b *svgWriter) draw(l *layout, x, y int) {
+	switch l.e.kind {
+	case exprEmpty:
+
+	case exprName, exprTerminal:
+		class, radius := "nonterminal", 0
+		if l.e.kind == exprTerminal {
+			class, radius = "terminal", boxHeight/2
+		}
+		fmt.Fprintf(
+			b, `<rect class="%s" x="%d" y="%d" width="%d" height="%d" rx="%d"/>`+"\n",
+			class, x, y-boxHeight/2, l.w, boxHeight, radius,
+		)
+		b.text(class, x+l.w/2, y+4, l.e.text)
+
+	case exprComment:
+		b.line(x, x+l.w, y)
+		b.text("comment", x+l.w/2, y-4, l.e.text)
+
+	case exprSeq:
+		for i, child := range l.children {
+			if i > 0 {
+				b.line(x, x+gap, y)
+				x += gap
+			}
+			b.draw(child, x, y)
+			x += child.w
+		}
+
+	case exprAlt, exprOpt, exprRep:
+		left, right := x+2*arc, x+l.w-2*arc
+		for i, child := range l.children {
+			yi := y + l.offsets[i]
+			if i == 0 {
+				b.line(x, left, y)
+			} else {
+				b.path("M%d %dQ%d %d %d %dV%dQ%d %d %d %d",
+					x, y, x+arc, y, x+arc, y+arc, yi-arc, x+arc, yi, left, yi)
+			}
+			b.draw(child, left, yi)
+			b.line(left+child.w, right, yi)
+			if i == 0 {
+				b.line(right, x+l.w, y)
+			} else {
+				b.path("M%d %dQ%d %d %d %dV%dQ%d %d %d %d",
+					right, yi, right+arc, yi, right+arc, yi-arc, y+arc, right+arc, y, x+l.w, y)
+			}
+		}
+
+	default:
+		left, right := x+2*arc, x+l.w-2*arc
+		item, yl := l.children[0], y+l.offsets[0]
+		b.line(x, left, y)
+		b.draw(item, left, y)
+		b.line(left+item.w, x+l.w, y)
+		b.path("M%d %dQ%d %d %d %dV%dQ%d %d %d %d",
+			right, y, right+arc, y, right+arc, y+arc, yl-arc, right+arc, yl, right, yl)
+		if len(l.children) > 1 {
+			delim := l.children[1]
+			dx := left + (right-left-delim.w)/2
+			b.line(dx+delim.w, right, yl)
+			b.draw(delim, dx, yl)
+			b.line(left, dx, yl)
+		} else {
+			b.line(left, right, yl)
+		}
+		b.path("M%d %dQ%d %d %d %dV%dQ%d %d %d %d",
+			left, yl, x+arc, yl, x+arc, yl-arc, y+arc, x+arc, y, left, y)
+	}
+}
+
+// SVG writes a single SVG image with a railroad diagram for each of the given
+// rules stacked in the given order. Each rule must be created with pars.Named
+// and references to other named Parsers are drawn as rectangles containing
+// their names, terminals as rounded rectangles, and constructs which cannot
+// be expressed in EBNF as italic comments.
+func SVG(w io.Writer, qs ...interface{}) error {
+	nodes, err := rules(qs)
+	if err != nil {
+		return err
+	}
+
+	layouts := make([]*layout, len(nodes))
+	width, height := 0, margin
+	for i, n := range nodes {
+		l := newLayout(ruleExpr(n))
+		layouts[i] = l
+		width = max(width, l.w+2*gap+2*margin)
+		width = max(width, textWidth(n.Name)+2*margin)
+		height += titleSpace + l.up + l.down + margin
+	}
+
+	b := &svgWriter{}
+	fmt.Fprintf(
+		b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width, height, width, height,
+	)
+	b.WriteString(svgStyle)
+
+	y := margin
+	for i, n := range nodes {
+		l := layouts[i]
+		fmt.Fprintf(b, `<g id="%s">`+"\n", escape(n.Name))
+		b.text("title", margin, y+14, n.Name)
+		y += titleSpace + l.up
+
+		// Start and end markers with the diagram between them.
+		x := margin
+		b.path("M%d %dV%d", x, y-arc, y+arc)
+		b.line(x, x+gap, y)
+		b.draw(l, x+gap, y)
+		x += gap + l.w
+		b.line(x, x+gap, y)
+		b.path("M%d %dV%d", x+gap, y-arc, y+arc)
+
+		b.WriteString("</g>\n")
+		y += l.down + margin
+	}
+	b.WriteString("</svg>\n")
+
+	_, err = w.Write(b.Bytes())
+	return err
+}
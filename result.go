@@ -1,6 +1,9 @@
 package pars
 
 // Void is a global Result for storing unused parsing results.
+//
+// Deprecated: Void is shared by every parser which writes into it, so using it
+// from multiple goroutines is a data race. Pass a new Result instead.
 var Void = &Result{}
 
 // Result is the output of a parser.
@@ -9,10 +12,19 @@ var Void = &Result{}
 //   Value: any value, useful for constructing complex objects.
 //   Children: results for individual child parsers.
 // Use one of the Set* methods to mutually set fields.
+//
+// The Token set by the primitive parsers is not a copy: it aliases the buffer
+// of the state, which is the input itself for a state created with FromBytes.
+// The Token must not be modified, and it is only valid until the state is
+// compacted with Compact or its input is released by a Scanner. Use Clone or
+// copy the Token to keep it beyond that point.
+// The Name field is set by the Capture combinator and is left untouched by
+// the Set* methods so a named result can be looked up with Get by the parent.
 type Result struct {
 	Token    []byte
 	Value    interface{}
 	Children []Result
+	Name     string
 }
 
 // SetToken sets the token and clears other fields.
@@ -36,6 +48,47 @@ func (r *Result) SetChildren(c []Result) {
 	r.Children = c
 }
 
+// Get returns the first child result captured with the given name, or nil if
+// there is no such child.
+func (r *Result) Get(name string) *Result {
+	for i := range r.Children {
+		if r.Children[i].Name == name {
+			return &r.Children[i]
+		}
+	}
+	return nil
+}
+
+// Named returns the children captured with a name keyed by their names. If
+// multiple children share a name, the first child is used.
+func (r *Result) Named() map[string]*Result {
+	m := make(map[string]*Result)
+	for i := range r.Children {
+		child := &r.Children[i]
+		if _, ok := m[child.Name]; child.Name != "" && !ok {
+			m[child.Name] = child
+		}
+	}
+	return m
+}
+
+// Clone returns a deep copy of the result. The Token and Children of the copy
+// do not share memory with the result, so the copy remains valid after the
+// buffer of the state is compacted or the Arena of the state is reset.
+func (r *Result) Clone() Result {
+	c := Result{Value: r.Value, Name: r.Name}
+	if r.Token != nil {
+		c.Token = append([]byte{}, r.Token...)
+	}
+	if r.Children != nil {
+		c.Children = make([]Result, len(r.Children))
+		for i := range r.Children {
+			c.Children[i] = r.Children[i].Clone()
+		}
+	}
+	return c
+}
+
 // NewTokenResult creates a new result with the given token.
 func NewTokenResult(p []byte) *Result { return &Result{Token: p} }
 
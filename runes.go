@@ -48,7 +48,7 @@ func readRune(state *State) (rune, error) {
 func Rune(rs ...rune) Parser {
 	switch len(rs) {
 	case 0:
-		return func(state *State, result *Result) error {
+		return describe(&Node{Kind: KindRune}, func(state *State, result *Result) error {
 			r, err := readRune(state)
 			if err != nil {
 				return NewNestedError("Rune", err)
@@ -56,7 +56,7 @@ func Rune(rs ...rune) Parser {
 			result.SetValue(r)
 			state.Advance()
 			return nil
-		}
+		})
 	case 1:
 		r := rs[0]
 		rep := runeRep(r)
@@ -66,18 +66,19 @@ func Rune(rs ...rune) Parser {
 		n := utf8.RuneLen(r)
 		p := make([]byte, n)
 		utf8.EncodeRune(p, r)
+		var v interface{} = r
 
-		return func(state *State, result *Result) error {
+		return describe(&Node{Kind: KindRune, Runes: rs}, func(state *State, result *Result) error {
 			if err := state.Request(n); err != nil {
 				return NewNestedError(name, err)
 			}
 			if !bytes.Equal(state.Buffer(), p) {
 				return NewError(what, state.Position())
 			}
-			result.SetValue(r)
+			result.SetValue(v)
 			state.Advance()
 			return nil
-		}
+		})
 	default:
 		reps := strings.Join(runeReps(rs), ", ")
 		name := fmt.Sprintf("Rune(%s)", reps)
@@ -86,7 +87,7 @@ func Rune(rs ...rune) Parser {
 		s := string(rs)
 		mismatch := func(r rune) bool { return !strings.ContainsRune(s, r) }
 
-		return func(state *State, result *Result) error {
+		return describe(&Node{Kind: KindRune, Runes: rs}, func(state *State, result *Result) error {
 			r, err := readRune(state)
 			if err != nil {
 				return NewNestedError(name, err)
@@ -97,7 +98,7 @@ func Rune(rs ...rune) Parser {
 			result.SetValue(r)
 			state.Advance()
 			return nil
-		}
+		})
 	}
 }
 
@@ -109,7 +110,7 @@ func RuneRange(begin, end rune) Parser {
 		name := fmt.Sprintf("RuneRange(%s, %s)", rbegin, rend)
 		what := fmt.Sprintf("expected in range %s-%s", rbegin, rend)
 
-		return func(state *State, result *Result) error {
+		return describe(&Node{Kind: KindRuneRange, Runes: []rune{begin, end}}, func(state *State, result *Result) error {
 			r, err := readRune(state)
 			if err != nil {
 				return NewNestedError(name, err)
@@ -120,7 +121,7 @@ func RuneRange(begin, end rune) Parser {
 			result.SetValue(r)
 			state.Advance()
 			return nil
-		}
+		})
 	}
 	panic("invalid rune range")
 }
@@ -131,16 +132,17 @@ func Runes(rs []rune) Parser {
 	name := fmt.Sprintf("Rune(%s)", reps)
 	what := fmt.Sprintf("expected [%s]", reps)
 	p := []byte(string(rs))
+	var v interface{} = rs
 
-	return func(state *State, result *Result) error {
+	return describe(&Node{Kind: KindRunes, Runes: rs}, func(state *State, result *Result) error {
 		if err := state.Request(len(p)); err != nil {
 			return NewNestedError(name, err)
 		}
 		if !bytes.Equal(state.Buffer(), p) {
 			return NewError(what, state.Position())
 		}
-		result.SetValue(rs)
+		result.SetValue(v)
 		state.Advance()
 		return nil
-	}
+	})
 }
@@ -0,0 +1,153 @@
+package pars
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+var errNoProgress = errors.New("record did not consume any input")
+
+// RecordError is an error for a record which failed to parse in a Scanner.
+type RecordError struct {
+	index int
+	pos   Position
+	err   error
+}
+
+// Error satisfies the error interface.
+func (e RecordError) Error() string {
+	return fmt.Sprintf("in record %d at %s:\n%s", e.index+1, e.pos, e.err)
+}
+
+// Record returns the zero based index of the record.
+func (e RecordError) Record() int { return e.index }
+
+// Position returns the position at which the record starts.
+func (e RecordError) Position() Position { return e.pos }
+
+// Unwrap returns the internal error value.
+func (e RecordError) Unwrap() error { return e.err }
+
+// Scanner reads a sequence of records from an io.Reader by repeatedly
+// applying a Parser. The state is cleared and compacted between records so
+// the memory used by the Scanner is bounded by the size of the largest record
+// rather than the size of the input.
+type Scanner struct {
+	state   *State
+	parser  Parser
+	recover Parser
+	report  func(error)
+	result  Result
+	index   int
+	err     error
+}
+
+// NewScanner creates a new Scanner for the given io.Reader which will parse
+// each record with the given Parser.
+func NewScanner(r io.Reader, q interface{}) *Scanner {
+	return &Scanner{state: NewState(r), parser: AsParser(q)}
+}
+
+// Recover sets the Parser used to skip a record which fails to parse. When a
+// record fails, the Scanner will backtrack to the beginning of the record,
+// report the error to the given function if it is not nil, apply the given
+// Parser, and continue with the next record. A common choice is Line to skip
+// to the next line. By default, the Scanner stops at the first error.
+func (s *Scanner) Recover(q interface{}, report func(error)) {
+	s.recover, s.report = AsParser(q), report
+}
+
+// SetArena sets the Arena from which the results of the records are
+// allocated. The Arena is reset between records.
+func (s *Scanner) SetArena(a *Arena) { s.state.SetArena(a) }
+
+// fail handles the error for the current record and tests if the Scanner can
+// continue to the next record.
+func (s *Scanner) fail(pos Position, err error) bool {
+	err = RecordError{s.index, pos, err}
+	s.index++
+	if s.recover == nil {
+		s.err = err
+		return false
+	}
+	if s.report != nil {
+		s.report(err)
+	}
+	s.state.Push()
+	if s.recover(s.state, &Result{}) != nil || s.state.Position() == pos {
+		s.state.Pop()
+		s.err = err
+		return false
+	}
+	s.state.Drop()
+	return true
+}
+
+// Scan advances the Scanner to the next record, which will then be available
+// through the Result method. It returns false when the input is exhausted or
+// an error occurs, after which Err will report the error if any.
+func (s *Scanner) Scan() bool {
+	for s.err == nil {
+		// Release the buffer of the previous record.
+		s.state.Clear()
+		s.state.Compact()
+		if s.state.arena != nil {
+			s.state.arena.Reset()
+		}
+		s.result = Result{}
+
+		if err := s.state.Request(1); err != nil {
+			if err != io.EOF {
+				s.err = err
+			}
+			return false
+		}
+
+		pos := s.state.Position()
+		s.state.Push()
+		if err := s.parser(s.state, &s.result); err != nil {
+			s.state.Pop()
+			if !s.fail(pos, err) {
+				return false
+			}
+			continue
+		}
+		s.state.Drop()
+
+		if s.state.Position() == pos {
+			if !s.fail(pos, errNoProgress) {
+				return false
+			}
+			continue
+		}
+		s.index++
+		return true
+	}
+	return false
+}
+
+// Result returns the result of the most recent record. The Result is only
+// valid until the next call to Scan.
+func (s *Scanner) Result() *Result { return &s.result }
+
+// Err returns the first error which stopped the Scanner. Reaching the end of
+// the input is not an error.
+func (s *Scanner) Err() error { return s.err }
+
+// Each calls the given function with the result of each record until the
+// input is exhausted, an error occurs, or the function returns an error. The
+// Result is only valid for the duration of the call.
+func (s *Scanner) Each(f func(result *Result) error) error {
+	for s.Scan() {
+		if err := f(s.Result()); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
+
+// Records is a shorthand for creating a Scanner and calling Each.
+func Records(r io.Reader, q interface{}, f func(result *Result) error) error {
+	return NewScanner(r, q).Each(f)
+}
@@ -0,0 +1,164 @@
+package pars
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/go-ascii/ascii"
+)
+
+var record = Seq(Word(ascii.IsLetter), '=', Int, EOL).Map(func(result *Result) error {
+	result.SetValue(fmt.Sprintf("%s:%d", result.Children[0].Token, result.Children[2].Value))
+	return nil
+})
+
+func scanAll(s *Scanner) []string {
+	v := []string{}
+	for s.Scan() {
+		v = append(v, s.Result().Value.(string))
+	}
+	return v
+}
+
+func TestScanner(t *testing.T) {
+	s := NewScanner(strings.NewReader("a=1\nb=2\r\nc=3"), record)
+	if v, e := scanAll(s), []string{"a:1", "b:2", "c:3"}; !same(v, e) {
+		t.Errorf("records = %v, want %v", v, e)
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("s.Err() = %v", err)
+	}
+	if s.Scan() {
+		t.Errorf("s.Scan() = true after end of input")
+	}
+
+	s = NewScanner(strings.NewReader(""), record)
+	if s.Scan() || s.Err() != nil {
+		t.Errorf("expected no records and no error for empty input")
+	}
+}
+
+func TestScannerError(t *testing.T) {
+	s := NewScanner(strings.NewReader("a=1\nb=x\nc=3\n"), record)
+	if v, e := scanAll(s), []string{"a:1"}; !same(v, e) {
+		t.Errorf("records = %v, want %v", v, e)
+	}
+
+	var re RecordError
+	if err := s.Err(); !errors.As(err, &re) {
+		t.Fatalf("s.Err() = %v, want RecordError", err)
+	}
+	if re.Record() != 1 || re.Position() != (Position{1, 0}) {
+		t.Errorf("error at record %d %v, want record 1 at %v", re.Record(), re.Position(), Position{1, 0})
+	}
+	var pe Error
+	if !errors.As(re, &pe) || pe.Position() != (Position{1, 2}) {
+		t.Errorf("cause = %v, want error at %v", re.Unwrap(), Position{1, 2})
+	}
+
+	s = NewScanner(strings.NewReader("a=1\n"), Epsilon)
+	if s.Scan() || !errors.Is(s.Err(), errNoProgress) {
+		t.Errorf("s.Err() = %v, want %v", s.Err(), errNoProgress)
+	}
+}
+
+func TestScannerRecover(t *testing.T) {
+	errs := []error{}
+	s := NewScanner(strings.NewReader("a=1\nb=x\n\nc=3\nd"), record)
+	s.Recover(Line, func(err error) { errs = append(errs, err) })
+
+	if v, e := scanAll(s), []string{"a:1", "c:3"}; !same(v, e) {
+		t.Errorf("records = %v, want %v", v, e)
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("s.Err() = %v", err)
+	}
+
+	records := []int{}
+	for _, err := range errs {
+		records = append(records, err.(RecordError).Record())
+	}
+	if e := []int{1, 2, 4}; !same(records, e) {
+		t.Errorf("failed records = %v, want %v", records, e)
+	}
+}
+
+func TestRecords(t *testing.T) {
+	v := []string{}
+	err := Records(strings.NewReader("a=1\nb=2\nc=3\n"), record, func(result *Result) error {
+		v = append(v, result.Value.(string))
+		if len(v) == 2 {
+			return io.ErrUnexpectedEOF
+		}
+		return nil
+	})
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("Records() = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if e := []string{"a:1", "b:2"}; !same(v, e) {
+		t.Errorf("records = %v, want %v", v, e)
+	}
+}
+
+// repeatReader yields the given record n times.
+type repeatReader struct {
+	p []byte
+	i int
+	n int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	m := 0
+	for m < len(p) && r.n > 0 {
+		k := copy(p[m:], r.p[r.i:])
+		m, r.i = m+k, r.i+k
+		if r.i == len(r.p) {
+			r.i, r.n = 0, r.n-1
+		}
+	}
+	if m == 0 {
+		return 0, io.EOF
+	}
+	return m, nil
+}
+
+func TestScannerMemory(t *testing.T) {
+	const n = 100000
+	s := NewScanner(&repeatReader{p: []byte("key=12345\n"), n: n}, record)
+	count, size := 0, 0
+	for s.Scan() {
+		count++
+		if len(s.state.buf) > size {
+			size = len(s.state.buf)
+		}
+	}
+	if s.Err() != nil || count != n {
+		t.Fatalf("scanned %d records with error %v, want %d", count, s.Err(), n)
+	}
+	if size > 2*bufferReadSize {
+		t.Errorf("buffer grew to %d bytes", size)
+	}
+}
+
+func TestScannerMemoMemory(t *testing.T) {
+	const n = 100000
+	s := NewScanner(&repeatReader{p: []byte("key=12345\n"), n: n}, Memoize(record))
+	count, size := 0, 0
+	for s.Scan() {
+		count++
+		if len(s.state.memo.entries) > size {
+			size = len(s.state.memo.entries)
+		}
+	}
+	if s.Err() != nil || count != n {
+		t.Fatalf("scanned %d records with error %v, want %d", count, s.Err(), n)
+	}
+
+	// Only the records within the buffer are remembered.
+	if size > 2*bufferReadSize/10 {
+		t.Errorf("memo table grew to %d entries", size)
+	}
+}
@@ -0,0 +1,150 @@
+package sexpr
+
+import (
+	"reflect"
+
+	"github.com/go-pars/pars"
+)
+
+// Symbol is the Go value of a symbol.
+type Symbol string
+
+// Keyword is the Go value of a keyword without the leading colon.
+type Keyword string
+
+// String returns the keyword with the leading colon.
+func (k Keyword) String() string { return ":" + string(k) }
+
+// Char is the Go value of a character literal.
+type Char rune
+
+// List is the Go value of a list.
+type List []interface{}
+
+// Vector is the Go value of a vector.
+type Vector []interface{}
+
+// Set is the Go value of a set.
+type Set []interface{}
+
+// Entry is a key/value pair of a Map.
+type Entry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// Map is the Go value of a map. The entries are kept in the order they were
+// read because the keys may be of any type, including lists and vectors,
+// which cannot be the keys of a Go map.
+type Map []Entry
+
+// Get returns the value of the first entry with a key deeply equal to the
+// given key.
+func (m Map) Get(key interface{}) (interface{}, bool) {
+	for _, e := range m {
+		if reflect.DeepEqual(e.Key, key) {
+			return e.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Tagged is the Go value of a tagged element such as `#inst "1985-04-12"`.
+type Tagged struct {
+	Tag   Symbol
+	Value interface{}
+}
+
+// Kind is the kind of a Node.
+type Kind int
+
+// Node kinds.
+const (
+	KindNil Kind = iota
+	KindBool
+	KindInt
+	KindFloat
+	KindString
+	KindChar
+	KindSymbol
+	KindKeyword
+	KindList
+	KindVector
+	KindMap
+	KindSet
+	KindTagged
+	KindMacro
+)
+
+var kindNames = [...]string{
+	KindNil:     "nil",
+	KindBool:    "bool",
+	KindInt:     "int",
+	KindFloat:   "float",
+	KindString:  "string",
+	KindChar:    "char",
+	KindSymbol:  "symbol",
+	KindKeyword: "keyword",
+	KindList:    "list",
+	KindVector:  "vector",
+	KindMap:     "map",
+	KindSet:     "set",
+	KindTagged:  "tagged",
+	KindMacro:   "macro",
+}
+
+func (k Kind) String() string {
+	if 0 <= int(k) && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "unknown"
+}
+
+// Node is a form in the syntax tree. The Value of an atom is its Go value:
+// nil, a bool, an int64 or a *big.Int, a float64 or a *big.Float, a string,
+// a Char, a Symbol, or a Keyword. The Value of a tagged element is the tag
+// and that of a reader macro is the Symbol it expands to, such as `quote`,
+// and both have a single child. The children of a map alternate between keys
+// and values. Start is the position of the first byte of the form and End is
+// the position after its last byte.
+type Node struct {
+	Kind     Kind
+	Value    interface{}
+	Children []*Node
+	Start    pars.Position
+	End      pars.Position
+}
+
+func values(nodes []*Node) []interface{} {
+	v := make([]interface{}, len(nodes))
+	for i, node := range nodes {
+		v[i] = node.Interface()
+	}
+	return v
+}
+
+// Interface returns the Go value of the form. Collections are converted to a
+// List, a Vector, a Map, or a Set, a tagged element to a Tagged, and a reader
+// macro to the List it expands to, so 'x becomes (quote x).
+func (n *Node) Interface() interface{} {
+	switch n.Kind {
+	case KindList:
+		return List(values(n.Children))
+	case KindVector:
+		return Vector(values(n.Children))
+	case KindSet:
+		return Set(values(n.Children))
+	case KindMap:
+		m := make(Map, len(n.Children)/2)
+		for i := range m {
+			m[i] = Entry{n.Children[2*i].Interface(), n.Children[2*i+1].Interface()}
+		}
+		return m
+	case KindTagged:
+		return Tagged{n.Value.(Symbol), n.Children[0].Interface()}
+	case KindMacro:
+		return List{n.Value, n.Children[0].Interface()}
+	default:
+		return n.Value
+	}
+}
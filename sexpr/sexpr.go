@@ -0,0 +1,558 @@
+// Package sexpr implements a reader for s-expressions in the extensible data
+// notation (EDN) used by Clojure, which is a superset of the s-expressions of
+// most Lisp dialects. The following forms are read:
+//
+//   nil true false                 constants
+//   42 -7 12345678901234567890N    integers
+//   3.14 1e-3 1.5M                 floats
+//   "a\tb"                         strings
+//   \a \newline \u03BB             characters
+//   foo my.ns/bar +                symbols
+//   :foo :my.ns/bar                keywords
+//   (a b) [a b] {k v} #{a b}       lists, vectors, maps, and sets
+//   #inst "1985-04-12T23:20:50Z"   tagged elements
+//   'x `x ~x ~@x @x #'x            reader macros
+//   #_ x                           discarded forms
+//
+// Commas are whitespace and a `;` starts a comment up to the end of the line.
+// Forms may be nested at most MaxDepth deep.
+// The reader macros expand to lists headed by the symbols quote, quasiquote,
+// unquote, unquote-splicing, deref, and var.
+//
+// A form is read into a Node, which keeps the span of the form in the input
+// and can be converted to plain Go values with Interface. A Reader reads the
+// top-level forms of an io.Reader one at a time.
+package sexpr
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-pars/pars"
+)
+
+const unexpectedEOF = "unexpected end of input"
+
+// MaxDepth is the maximum nesting of forms within collections, reader
+// macros, tagged elements, and discarded forms.
+const MaxDepth = 10000
+
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == ','
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+// isConstituent tests if the byte may be a part of a symbol, a keyword, or a
+// number. Bytes of multi-byte UTF-8 sequences are constituents.
+func isConstituent(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', isDigit(c), c >= 0x80:
+		return true
+	}
+	return strings.IndexByte(".*+!-_?$%&=<>/:#'", c) >= 0
+}
+
+// skipIgnored skips whitespace, comments, and discarded forms.
+func skipIgnored(state *pars.State) error {
+	for {
+		c, err := pars.Next(state)
+		switch {
+		case err != nil:
+			return nil
+		case isWhitespace(c):
+			state.Advance()
+		case c == ';':
+			for err == nil && c != '\n' {
+				state.Advance()
+				c, err = pars.Next(state)
+			}
+		case c == '#' && state.Request(2) == nil && state.Buffer()[1] == '_':
+			state.Advance()
+			if _, err := form(state); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// token matches a sequence of constituent bytes.
+func token(state *pars.State) string {
+	state.Push()
+	for {
+		c, err := pars.Next(state)
+		if err != nil || !isConstituent(c) {
+			break
+		}
+		state.Advance()
+	}
+	p, _ := pars.Trail(state)
+	return string(p)
+}
+
+// isNumber tests if the token starts like a number.
+func isNumber(s string) bool {
+	if len(s) > 1 && (s[0] == '+' || s[0] == '-') {
+		s = s[1:]
+	}
+	return isDigit(s[0])
+}
+
+// digits returns the length of the leading digits of the string.
+func digits(s string) int {
+	n := 0
+	for n < len(s) && isDigit(s[n]) {
+		n++
+	}
+	return n
+}
+
+// number converts a token to a number. A number is defined to be as follows
+// in EBNF:
+//
+//   integer  = [ `+` | `-` ], ( `0` | ( digit - `0` ), { digit } )
+//   exponent = ( `e` | `E` ), [ `+` | `-` ], digit, { digit }
+//   int      = integer, [ `N` ]
+//   float    = integer, ( `.`, { digit }, [ exponent ] | exponent ), [ `M` ]
+//
+// An int which does not fit in an int64 is read as a *big.Int, as is an int
+// with the `N` suffix. A float with the `M` suffix is read as a *big.Float.
+func number(s string, pos pars.Position) (*Node, error) {
+	invalid := func() (*Node, error) {
+		return nil, pars.NewError(fmt.Sprintf("invalid number %q", s), pos)
+	}
+
+	body, suffix := s, byte(0)
+	if c := s[len(s)-1]; c == 'N' || c == 'M' {
+		body, suffix = s[:len(s)-1], c
+	}
+	i := 0
+	if body[0] == '+' || body[0] == '-' {
+		i++
+	}
+	n := digits(body[i:])
+	if n == 0 || n > 1 && body[i] == '0' {
+		return invalid()
+	}
+	i += n
+	float := false
+	if i < len(body) && body[i] == '.' {
+		float = true
+		i++
+		i += digits(body[i:])
+	}
+	if i < len(body) && (body[i] == 'e' || body[i] == 'E') {
+		float = true
+		i++
+		if i < len(body) && (body[i] == '+' || body[i] == '-') {
+			i++
+		}
+		if n = digits(body[i:]); n == 0 {
+			return invalid()
+		}
+		i += n
+	}
+	if i != len(body) || float && suffix == 'N' || !float && suffix == 'M' {
+		return invalid()
+	}
+
+	node := &Node{Kind: KindInt, Start: pos}
+	switch {
+	case float && suffix == 'M':
+		f, _, err := big.ParseFloat(body, 10, 64, big.ToNearestEven)
+		if err != nil {
+			return invalid()
+		}
+		node.Kind, node.Value = KindFloat, f
+	case float:
+		f, err := strconv.ParseFloat(body, 64)
+		if err != nil {
+			return invalid()
+		}
+		node.Kind, node.Value = KindFloat, f
+	default:
+		v, err := strconv.ParseInt(body, 10, 64)
+		if err != nil || suffix == 'N' {
+			b, _ := new(big.Int).SetString(body, 10)
+			node.Value = b
+		} else {
+			node.Value = v
+		}
+	}
+	return node, nil
+}
+
+// atom matches a constant, a number, or a symbol.
+func atom(state *pars.State) (*Node, error) {
+	pos := state.Position()
+	s := token(state)
+	if s == "" {
+		c, _ := pars.Next(state)
+		return nil, pars.NewError(fmt.Sprintf("unexpected %q", c), pos)
+	}
+	if isNumber(s) {
+		return number(s, pos)
+	}
+	switch s {
+	case "nil":
+		return &Node{Kind: KindNil, Start: pos}, nil
+	case "true", "false":
+		return &Node{Kind: KindBool, Value: s == "true", Start: pos}, nil
+	}
+	if s[0] == ':' || s[0] == '#' || s[0] == '\'' {
+		return nil, pars.NewError(fmt.Sprintf("invalid symbol %q", s), pos)
+	}
+	return &Node{Kind: KindSymbol, Value: Symbol(s), Start: pos}, nil
+}
+
+// keyword matches a keyword.
+func keyword(state *pars.State) (*Node, error) {
+	pos := state.Position()
+	state.Advance()
+	s := token(state)
+	if s == "" || s[0] == ':' {
+		return nil, pars.NewError("expected a keyword", pos)
+	}
+	return &Node{Kind: KindKeyword, Value: Keyword(s), Start: pos}, nil
+}
+
+// str matches a string.
+func str(state *pars.State) (*Node, error) {
+	pos := state.Position()
+	state.Advance()
+	var b strings.Builder
+	for {
+		at := state.Position()
+		c, err := pars.Next(state)
+		if err != nil {
+			return nil, pars.NewError("unterminated string", pos)
+		}
+		state.Advance()
+		switch c {
+		case '"':
+			s := b.String()
+			if !utf8.ValidString(s) {
+				return nil, pars.NewError("invalid UTF-8 in string", pos)
+			}
+			return &Node{Kind: KindString, Value: s, Start: pos}, nil
+		case '\\':
+			c, err = pars.Next(state)
+			if err != nil {
+				return nil, pars.NewError("unterminated string", pos)
+			}
+			state.Advance()
+			switch c {
+			case '"', '\\', '/':
+				b.WriteByte(c)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if state.Request(4) != nil {
+					return nil, pars.NewError("invalid unicode escape", at)
+				}
+				r, err := strconv.ParseUint(string(state.Buffer()), 16, 16)
+				if err != nil {
+					return nil, pars.NewError("invalid unicode escape", at)
+				}
+				state.Advance()
+				b.WriteRune(rune(r))
+			default:
+				return nil, pars.NewError(fmt.Sprintf("invalid escape %q", c), at)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+var charNames = map[string]rune{
+	"newline":   '\n',
+	"space":     ' ',
+	"tab":       '\t',
+	"return":    '\r',
+	"formfeed":  '\f',
+	"backspace": '\b',
+}
+
+// char matches a character literal.
+func char(state *pars.State) (*Node, error) {
+	pos := state.Position()
+	state.Advance()
+	state.Push()
+	if c, err := pars.Next(state); err != nil || isWhitespace(c) {
+		state.Pop()
+		return nil, pars.NewError("expected a character", pos)
+	}
+	state.Advance()
+	token(state)
+	p, _ := pars.Trail(state)
+	s := string(p)
+
+	node := &Node{Kind: KindChar, Start: pos}
+	if r, n := utf8.DecodeRuneInString(s); r != utf8.RuneError && n == len(s) {
+		node.Value = Char(r)
+		return node, nil
+	}
+	if r, ok := charNames[s]; ok {
+		node.Value = Char(r)
+		return node, nil
+	}
+	if len(s) == 5 && s[0] == 'u' {
+		if r, err := strconv.ParseUint(s[1:], 16, 16); err == nil {
+			node.Value = Char(r)
+			return node, nil
+		}
+	}
+	return nil, pars.NewError(fmt.Sprintf("invalid character %q", s), pos)
+}
+
+// collection matches the forms of a collection up to the closing byte. The
+// state must be at the first form.
+func collection(state *pars.State, node *Node, close byte) error {
+	for {
+		if err := skipIgnored(state); err != nil {
+			return err
+		}
+		c, err := pars.Next(state)
+		if err != nil {
+			return pars.NewError(fmt.Sprintf("unterminated %s", node.Kind), node.Start)
+		}
+		if c == close {
+			state.Advance()
+			return nil
+		}
+		child, err := form(state)
+		if err != nil {
+			return err
+		}
+		node.Children = append(node.Children, child)
+	}
+}
+
+var macros = map[byte]Symbol{
+	'\'': "quote",
+	'`':  "quasiquote",
+	'~':  "unquote",
+	'@':  "deref",
+}
+
+// macro matches the form after a reader macro of the given length.
+func macro(state *pars.State, n int, name Symbol) (*Node, error) {
+	node := &Node{Kind: KindMacro, Value: name, Start: state.Position()}
+	pars.Skip(state, n)
+	child, err := form(state)
+	if err != nil {
+		return nil, err
+	}
+	node.Children = []*Node{child}
+	return node, nil
+}
+
+// dispatch matches the forms starting with `#`.
+func dispatch(state *pars.State) (*Node, error) {
+	pos := state.Position()
+	if state.Request(2) == nil {
+		switch state.Buffer()[1] {
+		case '{':
+			node := &Node{Kind: KindSet, Start: pos}
+			pars.Skip(state, 2)
+			return node, collection(state, node, '}')
+		case '\'':
+			return macro(state, 2, "var")
+		}
+	}
+	pars.Skip(state, 1)
+	c, err := pars.Next(state)
+	if err != nil || !isConstituent(c) || isDigit(c) || c == ':' || c == '#' {
+		return nil, pars.NewError("expected a tag", state.Position())
+	}
+	tag, err := atom(state)
+	if err != nil {
+		return nil, err
+	}
+	if tag.Kind != KindSymbol {
+		return nil, pars.NewError("expected a tag", tag.Start)
+	}
+	child, err := form(state)
+	if err != nil {
+		return nil, err
+	}
+	return &Node{Kind: KindTagged, Value: tag.Value, Children: []*Node{child}, Start: pos}, nil
+}
+
+// nested matches a form, bounding the recursion of the forms within it by
+// MaxDepth.
+var nested pars.Parser
+
+func init() {
+	nested = pars.Nested(func(state *pars.State, result *pars.Result) error {
+		node, err := read(state)
+		if err != nil {
+			return err
+		}
+		result.SetValue(node)
+		return nil
+	}, MaxDepth)
+}
+
+// form matches a form after skipping whitespace, comments, and discarded
+// forms.
+func form(state *pars.State) (*Node, error) {
+	result := pars.Result{}
+	if err := nested(state, &result); err != nil {
+		return nil, err
+	}
+	return result.Value.(*Node), nil
+}
+
+// read matches a form as form does without bounding its depth.
+func read(state *pars.State) (node *Node, err error) {
+	if err := skipIgnored(state); err != nil {
+		return nil, err
+	}
+	pos := state.Position()
+	c, err := pars.Next(state)
+	if err != nil {
+		return nil, pars.NewError(unexpectedEOF, pos)
+	}
+
+	switch c {
+	case '(', '[', '{':
+		node = &Node{Kind: KindList, Start: pos}
+		close := byte(')')
+		if c == '[' {
+			node.Kind, close = KindVector, ']'
+		}
+		if c == '{' {
+			node.Kind, close = KindMap, '}'
+		}
+		state.Advance()
+		if err = collection(state, node, close); err == nil && len(node.Children)%2 != 0 && c == '{' {
+			err = pars.NewError("map must contain an even number of forms", pos)
+		}
+	case ')', ']', '}':
+		err = pars.NewError(fmt.Sprintf("unexpected %q", c), pos)
+	case '"':
+		node, err = str(state)
+	case '\\':
+		node, err = char(state)
+	case ':':
+		node, err = keyword(state)
+	case '\'', '`', '@':
+		node, err = macro(state, 1, macros[c])
+	case '~':
+		if state.Request(2) == nil && state.Buffer()[1] == '@' {
+			node, err = macro(state, 2, "unquote-splicing")
+		} else {
+			node, err = macro(state, 1, macros[c])
+		}
+	case '#':
+		node, err = dispatch(state)
+	default:
+		node, err = atom(state)
+	}
+	if err != nil {
+		return nil, err
+	}
+	node.End = state.Position()
+	return node, nil
+}
+
+// Form matches a form, skipping the whitespace, comments, and discarded forms
+// before it, and sets the value to a *Node.
+func Form(state *pars.State, result *pars.Result) error {
+	node, err := form(state)
+	if err != nil {
+		return err
+	}
+	result.SetValue(node)
+	return nil
+}
+
+// record matches a top-level form, or the whitespace, comments, and
+// discarded forms at the end of the input, in which case the value is nil.
+func record(state *pars.State, result *pars.Result) error {
+	if err := skipIgnored(state); err != nil {
+		return err
+	}
+	if _, err := pars.Next(state); err != nil {
+		return nil
+	}
+	return Form(state, result)
+}
+
+// Reader reads the top-level forms of an io.Reader one at a time, so a
+// stream of any number of forms can be processed with memory bounded by the
+// size of the largest form.
+type Reader struct {
+	scanner *pars.Scanner
+}
+
+// NewReader creates a new Reader for the given io.Reader.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{pars.NewScanner(r, record)}
+}
+
+// Read reads the next top-level form. It returns io.EOF if there are no more
+// forms. A malformed form results in a pars.RecordError wrapping a
+// pars.Error at the position of the offending byte.
+func (r *Reader) Read() (*Node, error) {
+	for r.scanner.Scan() {
+		if node, ok := r.scanner.Result().Value.(*Node); ok {
+			return node, nil
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// ReadValue reads the next top-level form and returns its Go value.
+func (r *Reader) ReadValue() (interface{}, error) {
+	node, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	return node.Interface(), nil
+}
+
+// ReadAll reads all of the remaining top-level forms.
+func (r *Reader) ReadAll() ([]*Node, error) {
+	v := []*Node{}
+	for {
+		node, err := r.Read()
+		switch err {
+		case nil:
+			v = append(v, node)
+		case io.EOF:
+			return v, nil
+		default:
+			return v, err
+		}
+	}
+}
+
+// Unmarshal reads all of the top-level forms of the given io.Reader and
+// returns their Go values.
+func Unmarshal(r io.Reader) ([]interface{}, error) {
+	nodes, err := NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return values(nodes), nil
+}
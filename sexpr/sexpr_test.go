@@ -0,0 +1,256 @@
+package sexpr
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-pars/pars"
+)
+
+func bigInt(s string) *big.Int {
+	b, _ := new(big.Int).SetString(s, 10)
+	return b
+}
+
+var unmarshalTests = []struct {
+	in  string
+	out interface{}
+}{
+	{"nil", nil},
+	{"true", true},
+	{"false", false},
+	{"42", int64(42)},
+	{"-7", int64(-7)},
+	{"+0", int64(0)},
+	{"42N", bigInt("42")},
+	{"12345678901234567890", bigInt("12345678901234567890")},
+	{"3.14", 3.14},
+	{"-1e-3", -0.001},
+	{"1.", 1.0},
+	{`"a\tb\"é"`, "a\tb\"é"},
+	{"\"multi\nline\"", "multi\nline"},
+	{`\a`, Char('a')},
+	{`\newline`, Char('\n')},
+	{`\u03BB`, Char('λ')},
+	{"\\λ", Char('λ')},
+	{`\(`, Char('(')},
+	{"foo", Symbol("foo")},
+	{"my.ns/bar", Symbol("my.ns/bar")},
+	{"-", Symbol("-")},
+	{"-a", Symbol("-a")},
+	{"<=", Symbol("<=")},
+	{":foo", Keyword("foo")},
+	{":my.ns/bar", Keyword("my.ns/bar")},
+	{"()", List{}},
+	{"(a 1 :b)", List{Symbol("a"), int64(1), Keyword("b")}},
+	{"[1, 2 ,3]", Vector{int64(1), int64(2), int64(3)}},
+	{"(a [b (c)])", List{Symbol("a"), Vector{Symbol("b"), List{Symbol("c")}}}},
+	{`{:a 1 "b" [2]}`, Map{{Keyword("a"), int64(1)}, {"b", Vector{int64(2)}}}},
+	{"{[1 2] nil}", Map{{Vector{int64(1), int64(2)}, nil}}},
+	{"#{1 2}", Set{int64(1), int64(2)}},
+	{`#inst "1985-04-12T23:20:50Z"`, Tagged{"inst", "1985-04-12T23:20:50Z"}},
+	{"#my.app/point [1 2]", Tagged{"my.app/point", Vector{int64(1), int64(2)}}},
+	{"'x", List{Symbol("quote"), Symbol("x")}},
+	{"`(a ~b ~@c)", List{Symbol("quasiquote"), List{
+		Symbol("a"),
+		List{Symbol("unquote"), Symbol("b")},
+		List{Symbol("unquote-splicing"), Symbol("c")},
+	}}},
+	{"@a", List{Symbol("deref"), Symbol("a")}},
+	{"#'a", List{Symbol("var"), Symbol("a")}},
+	{"'(1 2)", List{Symbol("quote"), List{int64(1), int64(2)}}},
+	{"(1 #_ 2 3)", List{int64(1), int64(3)}},
+	{"(1 #_ #_ 2 3 4)", List{int64(1), int64(4)}},
+	{"(1 ; comment\n 2)", List{int64(1), int64(2)}},
+	{"[#_ 1]", Vector{}},
+}
+
+func TestUnmarshal(t *testing.T) {
+	for _, tt := range unmarshalTests {
+		v, err := Unmarshal(strings.NewReader(tt.in))
+		if err != nil {
+			t.Errorf("Unmarshal(%q): %v", tt.in, err)
+			continue
+		}
+		if len(v) != 1 {
+			t.Errorf("Unmarshal(%q) read %d forms, want 1", tt.in, len(v))
+			continue
+		}
+		if !reflect.DeepEqual(v[0], tt.out) {
+			t.Errorf("Unmarshal(%q) = %#v, want %#v", tt.in, v[0], tt.out)
+		}
+	}
+}
+
+func TestBigFloat(t *testing.T) {
+	v, err := Unmarshal(strings.NewReader("1.5M"))
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	f, ok := v[0].(*big.Float)
+	if !ok || f.Cmp(big.NewFloat(1.5)) != 0 {
+		t.Errorf("Unmarshal(%q) = %#v, want 1.5", "1.5M", v[0])
+	}
+}
+
+var unmarshalErrorTests = []struct {
+	in  string
+	pos pars.Position
+}{
+	{"(1 2", pars.Position{Byte: 0}},
+	{"[1 (2]", pars.Position{Byte: 5}},
+	{"{:a}", pars.Position{Byte: 0}},
+	{"#{1 2", pars.Position{Byte: 0}},
+	{")", pars.Position{Byte: 0}},
+	{`"abc`, pars.Position{Byte: 0}},
+	{`"a\qb"`, pars.Position{Byte: 2}},
+	{`"\u12"`, pars.Position{Byte: 1}},
+	{"007", pars.Position{Byte: 0}},
+	{"1.5N", pars.Position{Byte: 0}},
+	{"12M", pars.Position{Byte: 0}},
+	{"1e", pars.Position{Byte: 0}},
+	{"1a", pars.Position{Byte: 0}},
+	{"::a", pars.Position{Byte: 0}},
+	{":", pars.Position{Byte: 0}},
+	{`\foo`, pars.Position{Byte: 0}},
+	{`\ `, pars.Position{Byte: 0}},
+	{"# x", pars.Position{Byte: 1}},
+	{"#1 x", pars.Position{Byte: 1}},
+	{"#inst", pars.Position{Byte: 5}},
+	{"'", pars.Position{Byte: 1}},
+	{"#_", pars.Position{Byte: 2}},
+	{"(a\n  ^b)", pars.Position{Line: 1, Byte: 2}},
+	{strings.Repeat("(", 3000000), pars.Position{Byte: MaxDepth}},
+	{strings.Repeat("'", 3000000), pars.Position{Byte: MaxDepth}},
+	{strings.Repeat("#_", 3000000), pars.Position{Byte: 2 * (MaxDepth + 1)}},
+}
+
+func TestUnmarshalError(t *testing.T) {
+	for _, tt := range unmarshalErrorTests {
+		_, err := Unmarshal(strings.NewReader(tt.in))
+		if err == nil {
+			t.Errorf("Unmarshal(%q) expected an error", tt.in)
+			continue
+		}
+		var perr pars.Error
+		if !errors.As(err, &perr) {
+			t.Errorf("Unmarshal(%q): %v is not a pars.Error", tt.in, err)
+			continue
+		}
+		if perr.Position() != tt.pos {
+			t.Errorf("Unmarshal(%q): error %q at %v, want %v", tt.in, err, perr.Position(), tt.pos)
+		}
+	}
+}
+
+func TestSpans(t *testing.T) {
+	in := "; header\n(defn f [x]\n  'x)"
+	node, err := NewReader(strings.NewReader(in)).Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if node.Kind != KindList || len(node.Children) != 4 {
+		t.Fatalf("Read() = %v with %d children, want a list of 4", node.Kind, len(node.Children))
+	}
+	spans := []struct {
+		node       *Node
+		start, end pars.Position
+	}{
+		{node, pars.Position{Line: 1, Byte: 0}, pars.Position{Line: 2, Byte: 5}},
+		{node.Children[1], pars.Position{Line: 1, Byte: 6}, pars.Position{Line: 1, Byte: 7}},
+		{node.Children[2], pars.Position{Line: 1, Byte: 8}, pars.Position{Line: 1, Byte: 11}},
+		{node.Children[3], pars.Position{Line: 2, Byte: 2}, pars.Position{Line: 2, Byte: 4}},
+		{node.Children[3].Children[0], pars.Position{Line: 2, Byte: 3}, pars.Position{Line: 2, Byte: 4}},
+	}
+	for i, tt := range spans {
+		if tt.node.Start != tt.start || tt.node.End != tt.end {
+			t.Errorf("span %d = %v-%v, want %v-%v", i, tt.node.Start, tt.node.End, tt.start, tt.end)
+		}
+	}
+	if node.Children[3].Kind != KindMacro || node.Children[3].Value != Symbol("quote") {
+		t.Errorf("Children[3] = %v %v, want macro quote", node.Children[3].Kind, node.Children[3].Value)
+	}
+}
+
+func TestReader(t *testing.T) {
+	in := "(a) [b]\n; comment\n:c 1 #_ (d)\n\"e\"  \n"
+	r := NewReader(strings.NewReader(in))
+	want := []interface{}{
+		List{Symbol("a")},
+		Vector{Symbol("b")},
+		Keyword("c"),
+		int64(1),
+		"e",
+	}
+	for i, w := range want {
+		v, err := r.ReadValue()
+		if err != nil {
+			t.Fatalf("ReadValue() %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(v, w) {
+			t.Errorf("ReadValue() %d = %#v, want %#v", i, v, w)
+		}
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("Read() at end = %v, want io.EOF", err)
+	}
+}
+
+func TestReaderError(t *testing.T) {
+	r := NewReader(strings.NewReader("(a)\n(b\n"))
+	if _, err := r.Read(); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	_, err := r.Read()
+	var rerr pars.RecordError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("Read() = %v, want a pars.RecordError", err)
+	}
+	if rerr.Record() != 1 {
+		t.Errorf("Record() = %d, want 1", rerr.Record())
+	}
+	var perr pars.Error
+	if !errors.As(err, &perr) || perr.Position() != (pars.Position{Line: 1, Byte: 0}) {
+		t.Errorf("Read() = %v, want an error at line 2", err)
+	}
+}
+
+func TestMapGet(t *testing.T) {
+	v, err := Unmarshal(strings.NewReader("{:a 1 [1 2] :b}"))
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	m := v[0].(Map)
+	if x, ok := m.Get(Vector{int64(1), int64(2)}); !ok || x != Keyword("b") {
+		t.Errorf("Get([1 2]) = %v, %v, want :b, true", x, ok)
+	}
+	if _, ok := m.Get(Keyword("c")); ok {
+		t.Error("Get(:c) found a missing key")
+	}
+}
+
+func TestForm(t *testing.T) {
+	p := pars.Seq(Form, Form, pars.Spaces, pars.End)
+	result, err := p.Parse(pars.FromString(" (a) b "))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if node := result.Children[1].Value.(*Node); node.Value != Symbol("b") {
+		t.Errorf("second form = %v, want b", node.Value)
+	}
+}
+
+func BenchmarkReader(b *testing.B) {
+	p := []byte(strings.Repeat(`{:id 42 :name "widget" :tags #{:a :b} :dims [1.5 2.5] :at #inst "2020-01-01"} `, 64))
+	b.ReportAllocs()
+	b.SetBytes(int64(len(p)))
+	for i := 0; i < b.N; i++ {
+		if _, err := NewReader(pars.FromBytes(p)).ReadAll(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
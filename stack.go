@@ -5,6 +5,7 @@ const stackGrowthSize = 16
 type frame struct {
 	Off int
 	Pos Position
+	Bit int
 }
 
 type stack struct {
@@ -16,18 +17,18 @@ func newStack() *stack { return &stack{make([]frame, stackGrowthSize), 0} }
 
 func (s stack) Empty() bool { return s.i == 0 }
 
-func (s *stack) Push(i int, position Position) {
+func (s *stack) Push(i int, position Position, bit int) {
 	if s.i == len(s.v) {
 		s.v = append(s.v, make([]frame, stackGrowthSize)...)
 	}
-	s.v[s.i] = frame{i, position}
+	s.v[s.i] = frame{i, position, bit}
 	s.i++
 }
 
-func (s *stack) Pop() (int, Position) {
+func (s *stack) Pop() (int, Position, int) {
 	s.i--
 	f := s.v[s.i]
-	return f.Off, f.Pos
+	return f.Off, f.Pos, f.Bit
 }
 
 func (s *stack) Reset() { s.i = 0 }
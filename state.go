@@ -10,46 +10,92 @@ const (
 	bufferReadSize = 4096
 )
 
+var newline = []byte{'\n'}
+
 // State represents a parser state, which is a convenience wrapper for an
 // io.Reader object with buffering and backtracking.
+//
+// The bytes read from the io.Reader are kept in a buffer for as long as they
+// may be needed for backtracking. The buffer is bounded by the commit point,
+// which is the position the state may not backtrack beyond. The commit point
+// is moved forward by Commit and by Clear, which is called automatically when
+// nothing is pushed, so the memory used by the buffer is proportional to the
+// lookahead required by the parser rather than to the size of the input.
 type State struct {
-	rd  io.Reader
-	buf []byte
-	off int
-	end int
-	err error
-	pos Position
-	stk *stack
+	rd   io.Reader
+	buf  []byte
+	off  int
+	end  int
+	err  error
+	pos  Position
+	stk  *stack
+	size int
+
+	// Offset of the first buffered byte from the beginning of the input.
+	base int
+
+	// Number of bits of the current byte consumed by the bit-level parsers.
+	bit int
+
+	// Offset and position of the commit point from the beginning of the input.
+	mark int
+	mpos Position
+	mbit int
+
+	arena *Arena
+
+	// Number of Nested Parsers being matched.
+	depth int
+
+	// Memoized results and the furthest offset examined by Request.
+	memo *memoTable
+	far  int
+
+	// Set when a push state runs out of input before it is closed.
+	starved bool
 }
 
 // NewState creates a new state from the given io.Reader.
 func NewState(r io.Reader) *State {
+	return NewStateSize(r, bufferReadSize)
+}
+
+// NewStateSize creates a new state from the given io.Reader which will read
+// the given number of bytes at a time. If the io.Reader is already a State,
+// it is returned as is.
+func NewStateSize(r io.Reader, size int) *State {
+	if size <= 0 {
+		size = bufferReadSize
+	}
 	switch rd := r.(type) {
 	case *State:
 		return rd
 	default:
 		return &State{
-			rd:  r,
-			buf: make([]byte, 0),
-			off: 0,
-			end: -1,
-			err: nil,
-			pos: Position{0, 0},
-			stk: newStack(),
+			rd:   r,
+			buf:  nil,
+			off:  0,
+			end:  -1,
+			err:  nil,
+			pos:  Position{0, 0},
+			stk:  newStack(),
+			size: size,
 		}
 	}
 }
 
-// FromBytes creates a new state from the given bytes.
+// FromBytes creates a new state from the given bytes. The state will never
+// modify the given bytes.
 func FromBytes(p []byte) *State {
 	return &State{
-		rd:  &bytes.Buffer{},
-		buf: p,
-		off: 0,
-		end: -1,
-		err: nil,
-		pos: Position{0, 0},
-		stk: newStack(),
+		rd:   nil,
+		buf:  p,
+		off:  0,
+		end:  -1,
+		err:  io.EOF,
+		pos:  Position{0, 0},
+		stk:  newStack(),
+		size: bufferReadSize,
 	}
 }
 
@@ -79,14 +125,24 @@ func (s *State) ReadByte() (byte, error) {
 // Request checks if the state contains at least the given number of bytes,
 // additionally reading from the io.Reader object as necessary when the
 // internal buffer is exhausted. If the call to Read for the io.Reader object
-// returns an error, Request will return the corresponding error. A subsequent
-// call to Advance will advance the state offset as far as possible.
+// returns an error, Request will return the corresponding error. For a state
+// created with NewPushState, Request will return ErrNeedMore if the bytes have
+// not been fed yet. A subsequent call to Advance will advance the state offset
+// as far as possible.
 func (s *State) Request(n int) error {
+	if far := s.base + s.off + n; far > s.far {
+		s.far = far
+	}
 	for len(s.buf) < s.off+n && s.err == nil {
-		p := make([]byte, bufferReadSize)
+		if s.rd == nil {
+			s.starved = true
+			s.end = len(s.buf)
+			return ErrNeedMore
+		}
+		s.grow()
 		var m int
-		m, s.err = s.rd.Read(p)
-		s.buf = append(s.buf, p[:m]...)
+		m, s.err = s.rd.Read(s.buf[len(s.buf):cap(s.buf)])
+		s.buf = s.buf[:len(s.buf)+m]
 	}
 
 	switch {
@@ -99,25 +155,87 @@ func (s *State) Request(n int) error {
 	}
 }
 
-// Advance the state by the amount given in a previous Request call.
+// grow ensures the buffer has room for reading at least the read size. The
+// buffer is doubled in size as the bytes are read rather than allocated for a
+// requested length up front, so a Request for more bytes than the input holds
+// uses memory proportional to the input. The bytes before the commit point
+// and their memoized results are discarded by moving the remaining bytes to a
+// new chunk. The previous chunk is left as is because slices returned by
+// Buffer may still refer to it.
+func (s *State) grow() {
+	if cap(s.buf)-len(s.buf) >= s.size {
+		return
+	}
+	keep := s.mark - s.base
+	if keep > 0 && s.memo != nil {
+		s.memo.release(s.mark)
+	}
+	live := s.buf[keep:]
+	size := len(live) + s.size
+	if size < 2*len(live) {
+		size = 2 * len(live)
+	}
+	buf := make([]byte, len(live), size)
+	copy(buf, live)
+	s.buf = buf
+	s.base += keep
+	s.off -= keep
+	if s.end >= 0 {
+		s.end -= keep
+	}
+}
+
+// Compact moves the bytes after the commit point to the beginning of the
+// buffer if it has run out of room for reading, so its memory is reused
+// instead of allocating a new chunk, and discards the memoized results before
+// the commit point. This invalidates all slices previously
+// returned by Buffer, Dump, or Trail, including the Tokens of any Result
+// parsed from the state, so it should only be called once the results have
+// been consumed. Compact has no effect for a state created with FromBytes or
+// FromString.
+func (s *State) Compact() {
+	keep := s.mark - s.base
+	if s.rd == nil || keep == 0 || cap(s.buf)-len(s.buf) >= s.size {
+		return
+	}
+	if s.memo != nil {
+		s.memo.release(s.mark)
+	}
+	s.buf = s.buf[:copy(s.buf, s.buf[keep:])]
+	s.base += keep
+	s.off -= keep
+	if s.end >= 0 {
+		s.end -= keep
+	}
+}
+
+// Advance the state by the amount given in a previous Request call. A byte
+// partially consumed by the bit-level parsers counts as a whole byte.
 func (s *State) Advance() {
 	if s.end < 0 {
 		panic("no previous call to Request")
 	}
-	for _, b := range s.buf[s.off:s.end] {
-		if b == '\n' {
-			s.pos.Line++
-			s.pos.Byte = 0
-		} else {
-			s.pos.Byte++
-		}
+	p := s.buf[s.off:s.end]
+	if len(p) > 0 {
+		s.bit = 0
+	}
+	switch {
+	case len(p) == 1 && p[0] != '\n':
+		// Most parsers advance a single byte at a time.
+		s.pos.Byte++
+	case bytes.IndexByte(p, '\n') < 0:
+		s.pos.Byte += len(p)
+	default:
+		s.pos.Line += bytes.Count(p, newline)
+		s.pos.Byte = len(p) - bytes.LastIndexByte(p, '\n') - 1
 	}
 	s.off, s.end = s.end, -1
 	s.autoclear()
 }
 
-// Buffer returns the range of bytes guaranteed by a Request call.
-func (s State) Buffer() []byte { return s.buf[s.off:s.end] }
+// Buffer returns the range of bytes guaranteed by a Request call. The bytes
+// are not copied so they must not be modified.
+func (s State) Buffer() []byte { return s.buf[s.off:s.end:s.end] }
 
 // Dump returns the entire remaining buffer content. Note that the returned
 // byte slice will not always contain the entirety of the bytes that can be
@@ -127,19 +245,37 @@ func (s State) Dump() []byte { return s.buf[s.off:] }
 // Offset returns the current state offset.
 func (s State) Offset() int { return s.off }
 
+// InputOffset returns the number of bytes between the beginning of the input
+// and the current position. Unlike Offset, it is not reset when the buffer is
+// cleared or compacted.
+func (s State) InputOffset() int { return s.base + s.off }
+
 // Position returns the current line and byte position of the state.
 func (s State) Position() Position { return s.pos }
 
+// Bit returns the number of bits of the current byte consumed by the
+// bit-level parsers, which is zero at a byte boundary.
+func (s State) Bit() int { return s.bit }
+
+// consumed returns the number of bits of the input consumed, which increases
+// whenever a Parser makes progress at the byte or the bit level.
+func (s *State) consumed() int { return (s.base+s.off)*8 + s.bit }
+
 // Push the current state position for backtracking.
-func (s *State) Push() { s.stk.Push(s.off, s.pos) }
+func (s *State) Push() { s.stk.Push(s.InputOffset(), s.pos, s.bit) }
 
 // Pushed tests if the state has been pushed at least once.
 func (s State) Pushed() bool { return !s.stk.Empty() }
 
-// Pop will backtrack to the most recently pushed state.
+// Pop will backtrack to the most recently pushed state. If the pushed state
+// is before the commit point, the state will backtrack to the commit point.
 func (s *State) Pop() {
 	if !s.stk.Empty() {
-		s.off, s.pos = s.stk.Pop()
+		off, pos, bit := s.stk.Pop()
+		if off < s.mark || off == s.mark && bit < s.mbit {
+			off, pos, bit = s.mark, s.mpos, s.mbit
+		}
+		s.off, s.pos, s.bit = off-s.base, pos, bit
 		s.autoclear()
 	}
 }
@@ -158,11 +294,18 @@ func (s *State) autoclear() {
 	}
 }
 
+// Commit will set the commit point to the current state position, allowing
+// the buffer contents prior to the current position to be discarded. Pushed
+// states are kept but popping a state before the commit point will backtrack
+// to the commit point instead.
+func (s *State) Commit() {
+	s.mark, s.mpos, s.mbit = s.InputOffset(), s.pos, s.bit
+}
+
 // Clear will discard the buffer contents prior to the current state offset
 // and drop all pushed states.
 func (s *State) Clear() {
-	s.buf = s.buf[s.off:]
-	s.off = 0
+	s.Commit()
 	s.stk.Reset()
 }
 
@@ -11,15 +11,18 @@ func String(s string) Parser {
 	what := fmt.Sprintf(`expected "%s"`, s)
 	p := []byte(s)
 
-	return func(state *State, result *Result) error {
+	// Convert to an interface value once to avoid allocating for each match.
+	var v interface{} = s
+
+	return describe(&Node{Kind: KindString, Literal: p}, func(state *State, result *Result) error {
 		if err := state.Request(len(p)); err != nil {
 			return NewNestedError(name, err)
 		}
 		if !bytes.Equal(state.Buffer(), p) {
 			return NewError(what, state.Position())
 		}
-		result.SetValue(s)
+		result.SetValue(v)
 		state.Advance()
 		return nil
-	}
+	})
 }
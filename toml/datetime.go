@@ -0,0 +1,155 @@
+package toml
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-pars/pars"
+)
+
+// LocalDate is a date without a time or an offset.
+type LocalDate struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// String returns the date in RFC 3339 format.
+func (d LocalDate) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, int(d.Month), d.Day)
+}
+
+// LocalTime is a time of day without a date or an offset.
+type LocalTime struct {
+	Hour       int
+	Minute     int
+	Second     int
+	Nanosecond int
+}
+
+// String returns the time in RFC 3339 format.
+func (t LocalTime) String() string {
+	s := fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	if t.Nanosecond != 0 {
+		s += fmt.Sprintf(".%09d", t.Nanosecond)
+	}
+	return s
+}
+
+// LocalDateTime is a date and a time without an offset.
+type LocalDateTime struct {
+	Date LocalDate
+	Time LocalTime
+}
+
+// String returns the date-time in RFC 3339 format.
+func (dt LocalDateTime) String() string {
+	return dt.Date.String() + "T" + dt.Time.String()
+}
+
+// In returns the time.Time of the date-time in the given location.
+func (dt LocalDateTime) In(loc *time.Location) time.Time {
+	d, t := dt.Date, dt.Time
+	return time.Date(d.Year, d.Month, d.Day, t.Hour, t.Minute, t.Second, t.Nanosecond, loc)
+}
+
+var digit = pars.Filter(isDigit)
+
+// shape creates a Parser which will match bytes following the given pattern,
+// in which a `9` matches any digit and any other byte matches itself. The
+// Token is set to the matched bytes.
+func shape(pattern string) pars.Parser {
+	qs := make([]interface{}, len(pattern))
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == '9' {
+			qs[i] = digit
+		} else {
+			qs[i] = pattern[i]
+		}
+	}
+	return pars.Seq(qs...).Map(pars.Cat)
+}
+
+// normalize will replace the Token with the given string.
+func normalize(s string) pars.Map {
+	return func(result *pars.Result) error {
+		result.SetToken([]byte(s))
+		return nil
+	}
+}
+
+// Date-time parser parts. The Token of each is set to the text of the part
+// in the layouts given to pars.Time.
+var (
+	date = shape("9999-99-99")
+
+	// clock matches a time with optional fractional seconds.
+	clock = pars.Seq(shape("99:99:99"), pars.Maybe(pars.Seq(pars.Byte('.'), pars.Word(isDigit)).Map(pars.Cat))).Map(pars.Cat)
+
+	// delimiter matches the `T` between a date and a time, which may also be
+	// a lower case `t` or a space.
+	delimiter = pars.Byte('T', 't', ' ').Map(normalize("T"))
+
+	offset = pars.Any(pars.Byte('Z', 'z').Map(normalize("Z")), shape("+99:99"), shape("-99:99"))
+
+	dateTimeStart = pars.Dry(pars.Any(shape("9999-"), shape("99:")))
+)
+
+// isDateTime tests if the state is at a date or a time rather than a number.
+func isDateTime(state *pars.State) bool {
+	return dateTimeStart(state, &pars.Result{}) == nil
+}
+
+// format is a kind of date-time given by the layout of its text and the
+// conversion from the time.Time parsed by pars.Time.
+type format struct {
+	layout  string
+	convert func(t time.Time) interface{}
+}
+
+func dateOf(t time.Time) LocalDate { return LocalDate{t.Year(), t.Month(), t.Day()} }
+
+func timeOf(t time.Time) LocalTime {
+	return LocalTime{t.Hour(), t.Minute(), t.Second(), t.Nanosecond()}
+}
+
+var (
+	offsetDateTime = format{"2006-01-02T15:04:05Z07:00", func(t time.Time) interface{} { return t }}
+	localDateTime  = format{"2006-01-02T15:04:05", func(t time.Time) interface{} { return LocalDateTime{dateOf(t), timeOf(t)} }}
+	localDate      = format{"2006-01-02", func(t time.Time) interface{} { return dateOf(t) }}
+	localTime      = format{"15:04:05", func(t time.Time) interface{} { return timeOf(t) }}
+)
+
+// stamp is the text of a date-time with its format.
+type stamp struct {
+	text []byte
+	format
+}
+
+// of creates a Parser which will match the given Parsers in sequence and set
+// the Value to a stamp of the format with the concatenated text.
+func (f format) of(qs ...interface{}) pars.Parser {
+	return pars.Seq(qs...).Map(pars.Cat).Map(func(result *pars.Result) error {
+		result.SetValue(stamp{result.Token, f})
+		return nil
+	})
+}
+
+// dateTime matches an offset date-time, a local date-time, a local date, or a
+// local time. The text is matched by the longest format before its components
+// are validated by pars.Time, so that an invalid date-time is reported instead
+// of a shorter format being tried.
+var dateTime = positioned(pars.Any(
+	offsetDateTime.of(date, delimiter, clock, offset),
+	localDateTime.of(date, delimiter, clock),
+	localDate.of(date),
+	localTime.of(clock),
+), func(result *pars.Result, pos pars.Position) error {
+	s := result.Value.(stamp)
+	result.SetToken(s.text)
+	if err := pars.Time(s.layout)(result); err != nil {
+		return fmt.Errorf("invalid date-time %q: %v", s.text, err)
+	}
+	result.SetValue(s.convert(result.Value.(time.Time)))
+	return nil
+})
@@ -0,0 +1,265 @@
+package toml
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/go-pars/pars"
+)
+
+// kind describes how a table was defined, which determines how it may be
+// extended later in the document.
+type kind int
+
+const (
+	// kindImplicit tables are created as the parents of a table header and
+	// may be defined by a header once.
+	kindImplicit kind = iota
+
+	// kindExplicit tables are defined by a table header.
+	kindExplicit
+
+	// kindDotted tables are defined by dotted keys and may only be extended
+	// by dotted keys or have sub-tables defined by headers.
+	kindDotted
+
+	// kindInline tables are inline tables which may not be extended.
+	kindInline
+)
+
+// table keeps the values of a table along with how its keys were defined.
+type table struct {
+	values map[string]interface{}
+	kind   kind
+	at     pars.Position
+	tables map[string]*table
+	arrays map[string][]*table
+	inline map[string]*table
+	pos    map[string]pars.Position
+}
+
+func newTable(k kind, at pars.Position) *table {
+	return &table{
+		values: make(map[string]interface{}),
+		kind:   k,
+		at:     at,
+		tables: make(map[string]*table),
+		arrays: make(map[string][]*table),
+		inline: make(map[string]*table),
+		pos:    make(map[string]pars.Position),
+	}
+}
+
+func redefined(k string, pos pars.Position) error {
+	return pars.NewError(fmt.Sprintf("key %q is already defined", k), pos)
+}
+
+// set adds the key/value pair to the table. The tables named by the dotted
+// key are created as needed.
+func (t *table) set(p pair) error {
+	last := len(p.keys) - 1
+	for _, k := range p.keys[:last] {
+		if sub, ok := t.tables[k.name]; ok {
+			if sub.kind != kindDotted {
+				return pars.NewError(fmt.Sprintf("cannot add keys to table %q with dotted keys", k.name), k.pos)
+			}
+			t = sub
+			continue
+		}
+		if _, ok := t.values[k.name]; ok {
+			return redefined(k.name, k.pos)
+		}
+		sub := newTable(kindDotted, k.pos)
+		t.tables[k.name], t.values[k.name], t.pos[k.name] = sub, sub.values, k.pos
+		t = sub
+	}
+
+	k, v := p.keys[last], p.value
+	if _, ok := t.values[k.name]; ok {
+		return redefined(k.name, k.pos)
+	}
+	if sub, ok := v.(*table); ok {
+		t.inline[k.name] = sub
+		v = sub.values
+	}
+	t.values[k.name], t.pos[k.name] = v, k.pos
+	return nil
+}
+
+// descend returns the table with the given key for a table header, creating
+// an implicit table if needed. The last table of an array of tables is used.
+func descend(t *table, k string, pos pars.Position) (*table, error) {
+	if sub, ok := t.tables[k]; ok {
+		return sub, nil
+	}
+	if a, ok := t.arrays[k]; ok {
+		return a[len(a)-1], nil
+	}
+	if _, ok := t.values[k]; ok {
+		return nil, redefined(k, pos)
+	}
+	sub := newTable(kindImplicit, pos)
+	t.tables[k], t.values[k], t.pos[k] = sub, sub.values, pos
+	return sub, nil
+}
+
+// Table header parsers. The Value is set to the []keyPart of the key.
+var (
+	tableHeader = pars.Seq('[', space, key, space, ']').Child(2)
+	arrayHeader = pars.Seq("[[", space, key, space, "]]").Child(2)
+)
+
+// header makes the table named by the keys of a table header or an array of
+// tables header the current table.
+func (d *decoder) header(keys []keyPart, array bool) error {
+	t := d.root
+	last := len(keys) - 1
+	for _, k := range keys[:last] {
+		var err error
+		if t, err = descend(t, k.name, k.pos); err != nil {
+			return err
+		}
+	}
+	k, pos := keys[last].name, keys[last].pos
+
+	if array {
+		if _, ok := t.values[k]; ok && t.arrays[k] == nil {
+			return redefined(k, pos)
+		}
+		sub := newTable(kindExplicit, pos)
+		if t.arrays[k] == nil {
+			t.values[k], t.pos[k] = []interface{}{}, pos
+		}
+		t.arrays[k] = append(t.arrays[k], sub)
+		t.values[k] = append(t.values[k].([]interface{}), sub.values)
+		d.current = sub
+		return nil
+	}
+
+	if sub, ok := t.tables[k]; ok {
+		if sub.kind != kindImplicit {
+			return pars.NewError(fmt.Sprintf("table %q is already defined", k), pos)
+		}
+		sub.kind, sub.at = kindExplicit, pos
+		d.current = sub
+		return nil
+	}
+	if _, ok := t.values[k]; ok {
+		return redefined(k, pos)
+	}
+	sub := newTable(kindExplicit, pos)
+	t.tables[k], t.values[k], t.pos[k] = sub, sub.values, pos
+	d.current = sub
+	return nil
+}
+
+// decoder keeps the tables of the document being decoded.
+type decoder struct {
+	root    *table
+	current *table
+}
+
+// document matches the lines of a document up to the end of the input.
+func (d *decoder) document(state *pars.State, result *pars.Result) error {
+	for {
+		if err := blank(state, result); err != nil {
+			return err
+		}
+		c, err := pars.Next(state)
+		if err != nil {
+			return nil
+		}
+		what := "value"
+		if c == '[' {
+			what = "table header"
+			array := peekString(state, "[[")
+			header := tableHeader
+			if array {
+				header = arrayHeader
+			}
+			if err := header(state, result); err != nil {
+				return err
+			}
+			if err := d.header(result.Value.([]keyPart), array); err != nil {
+				return err
+			}
+		} else {
+			if err := keyValue(state, result); err != nil {
+				return err
+			}
+			if err := d.current.set(result.Value.(pair)); err != nil {
+				return err
+			}
+		}
+		if err := endOfLine(state, what); err != nil {
+			return err
+		}
+	}
+}
+
+// Document is a decoded TOML document.
+type Document struct {
+	root *table
+}
+
+// Map returns the root table of the document.
+func (doc *Document) Map() map[string]interface{} { return doc.root.values }
+
+// Position returns the position at which the value of the given key was
+// defined. The keys name the tables from the root of the document, and an
+// element of an array of tables is named by its index, so the position of
+// `name` in the second `[[fruit]]` table is given by:
+//   doc.Position("fruit", "1", "name")
+// The position of a table is that of its header, or that of the key which
+// defined it. Keys inside inline tables are found as well, but the elements
+// of other arrays are not.
+func (doc *Document) Position(keys ...string) (pars.Position, bool) {
+	t := doc.root
+	for i := 0; i < len(keys); i++ {
+		k := keys[i]
+		if i == len(keys)-1 {
+			pos, ok := t.pos[k]
+			return pos, ok
+		}
+		switch {
+		case t.tables[k] != nil:
+			t = t.tables[k]
+		case t.inline[k] != nil:
+			t = t.inline[k]
+		case t.arrays[k] != nil:
+			i++
+			n, err := strconv.Atoi(keys[i])
+			if err != nil || n < 0 || n >= len(t.arrays[k]) {
+				return pars.Position{}, false
+			}
+			t = t.arrays[k][n]
+			if i == len(keys)-1 {
+				return t.at, true
+			}
+		default:
+			return pars.Position{}, false
+		}
+	}
+	return pars.Position{}, false
+}
+
+// Parse decodes the TOML document read from the given io.Reader.
+func Parse(r io.Reader) (*Document, error) {
+	root := newTable(kindExplicit, pars.Position{})
+	d := &decoder{root: root, current: root}
+	if err := d.document(pars.NewState(r), &pars.Result{}); err != nil {
+		return nil, err
+	}
+	return &Document{root}, nil
+}
+
+// Unmarshal decodes the TOML document read from the given io.Reader into a
+// map[string]interface{}.
+func Unmarshal(r io.Reader) (map[string]interface{}, error) {
+	doc, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Map(), nil
+}
@@ -0,0 +1,689 @@
+// Package toml implements a parser for TOML 1.0 documents. A document is
+// decoded into a map[string]interface{} where the values are string, int64,
+// float64, bool, time.Time for offset date-times, LocalDateTime, LocalDate,
+// LocalTime, []interface{}, and map[string]interface{}. The positions of the
+// keys are kept in a Document so that errors found while validating the
+// values can be reported at the right place. Arrays and inline tables may be
+// nested at most MaxDepth deep.
+package toml
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-pars/pars"
+)
+
+const unexpectedEOF = "unexpected end of input"
+
+// peekRune returns the next rune, requesting its bytes so that a call to
+// Advance will consume it. Invalid UTF-8 is reported as an error.
+func peekRune(state *pars.State) (rune, error) {
+	if err := state.Request(1); err != nil {
+		return 0, err
+	}
+	if c := state.Buffer()[0]; c < utf8.RuneSelf {
+		return rune(c), nil
+	}
+	state.Request(utf8.UTFMax)
+	r, size := utf8.DecodeRune(state.Buffer())
+	if r == utf8.RuneError && size == 1 {
+		return 0, pars.NewError("invalid UTF-8", state.Position())
+	}
+	state.Request(size)
+	return r, nil
+}
+
+// isControl tests if the rune is a control character which may not appear
+// in strings and comments. The tab is allowed everywhere.
+func isControl(r rune) bool {
+	return (r < 0x20 && r != '\t') || r == 0x7f
+}
+
+// peekString tests if the state is at the given string.
+func peekString(state *pars.State, s string) bool {
+	return state.Request(len(s)) == nil && string(state.Buffer()) == s
+}
+
+// skipSpace skips any spaces and tabs.
+func skipSpace(state *pars.State) {
+	for {
+		c, err := pars.Next(state)
+		if err != nil || (c != ' ' && c != '\t') {
+			return
+		}
+		state.Advance()
+	}
+}
+
+// newline matches a LF or a CRLF and reports if one was matched.
+func newline(state *pars.State) (bool, error) {
+	c, err := pars.Next(state)
+	switch {
+	case err != nil:
+		return false, nil
+	case c == '\n':
+		state.Advance()
+		return true, nil
+	case c == '\r':
+		if peekString(state, "\r\n") {
+			state.Advance()
+			return true, nil
+		}
+		return false, pars.NewError("expected LF after CR", state.Position())
+	default:
+		return false, nil
+	}
+}
+
+// comment matches a comment up to the end of the line if the state is at
+// one.
+func comment(state *pars.State) error {
+	if c, err := pars.Next(state); err != nil || c != '#' {
+		return nil
+	}
+	state.Advance()
+	for {
+		r, err := peekRune(state)
+		switch {
+		case err != nil:
+			if _, ok := err.(pars.Error); ok {
+				return err
+			}
+			return nil
+		case r == '\n' || r == '\r':
+			return nil
+		case isControl(r):
+			return pars.NewError("invalid control character in comment", state.Position())
+		}
+		state.Advance()
+	}
+}
+
+// blank matches any whitespace, newlines, and comments.
+func blank(state *pars.State, result *pars.Result) error {
+	for {
+		skipSpace(state)
+		if err := comment(state); err != nil {
+			return err
+		}
+		ok, err := newline(state)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+}
+
+// endOfLine matches the optional whitespace and comment at the end of a line
+// followed by a newline or the end of the input.
+func endOfLine(state *pars.State, what string) error {
+	skipSpace(state)
+	if err := comment(state); err != nil {
+		return err
+	}
+	if _, err := pars.Next(state); err != nil {
+		return nil
+	}
+	ok, err := newline(state)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return pars.NewError(fmt.Sprintf("expected newline after %s", what), state.Position())
+	}
+	return nil
+}
+
+func isBareKey(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '_' || c == '-'
+}
+
+func hexValue(c byte) rune {
+	switch {
+	case '0' <= c && c <= '9':
+		return rune(c - '0')
+	case 'a' <= c && c <= 'f':
+		return rune(c - 'a' + 10)
+	case 'A' <= c && c <= 'F':
+		return rune(c - 'A' + 10)
+	default:
+		return -1
+	}
+}
+
+// escape matches an escape sequence following a backslash and appends the
+// decoded rune to the given bytes.
+func escape(state *pars.State, p []byte) ([]byte, error) {
+	c, err := pars.Next(state)
+	if err != nil {
+		return nil, pars.NewError(unexpectedEOF, state.Position())
+	}
+	n := 0
+	switch c {
+	case 'b':
+		p = append(p, '\b')
+	case 't':
+		p = append(p, '\t')
+	case 'n':
+		p = append(p, '\n')
+	case 'f':
+		p = append(p, '\f')
+	case 'r':
+		p = append(p, '\r')
+	case '"', '\\':
+		p = append(p, c)
+	case 'u':
+		n = 4
+	case 'U':
+		n = 8
+	default:
+		return nil, pars.NewError("invalid escape sequence", state.Position())
+	}
+	state.Advance()
+	if n == 0 {
+		return p, nil
+	}
+
+	pos := state.Position()
+	if err := state.Request(n); err != nil {
+		return nil, pars.NewError(unexpectedEOF, pos)
+	}
+	r := rune(0)
+	for _, c := range state.Buffer() {
+		v := hexValue(c)
+		if v < 0 {
+			return nil, pars.NewError(fmt.Sprintf("expected %d hexadecimal digits", n), pos)
+		}
+		r = r<<4 | v
+	}
+	if !utf8.ValidRune(r) {
+		return nil, pars.NewError(fmt.Sprintf("invalid Unicode scalar value U+%04X", r), pos)
+	}
+	state.Advance()
+	return append(p, string(r)...), nil
+}
+
+// basicString matches a basic string enclosed in double quotes.
+func basicString(state *pars.State) (string, error) {
+	pars.Skip(state, 1)
+	p := []byte{}
+	for {
+		r, err := peekRune(state)
+		switch {
+		case err != nil:
+			if _, ok := err.(pars.Error); ok {
+				return "", err
+			}
+			return "", pars.NewError("unterminated string", state.Position())
+		case r == '"':
+			state.Advance()
+			return string(p), nil
+		case r == '\\':
+			state.Advance()
+			if p, err = escape(state, p); err != nil {
+				return "", err
+			}
+			continue
+		case r == '\n' || r == '\r':
+			return "", pars.NewError("unterminated string", state.Position())
+		case isControl(r):
+			return "", pars.NewError("invalid control character in string", state.Position())
+		}
+		state.Advance()
+		p = append(p, string(r)...)
+	}
+}
+
+// literalString matches a literal string enclosed in single quotes.
+func literalString(state *pars.State) (string, error) {
+	pars.Skip(state, 1)
+	p := []byte{}
+	for {
+		r, err := peekRune(state)
+		switch {
+		case err != nil:
+			if _, ok := err.(pars.Error); ok {
+				return "", err
+			}
+			return "", pars.NewError("unterminated string", state.Position())
+		case r == '\'':
+			state.Advance()
+			return string(p), nil
+		case r == '\n' || r == '\r':
+			return "", pars.NewError("unterminated string", state.Position())
+		case isControl(r):
+			return "", pars.NewError("invalid control character in string", state.Position())
+		}
+		state.Advance()
+		p = append(p, string(r)...)
+	}
+}
+
+// closingQuotes tests if the state is at the closing delimiter of a
+// multi-line string. Up to two quotes may directly precede the delimiter,
+// which are appended to the given bytes.
+func closingQuotes(state *pars.State, q byte, p []byte) ([]byte, bool, error) {
+	n := 0
+	for n < 6 && state.Request(n+1) == nil && state.Buffer()[n] == q {
+		n++
+	}
+	switch {
+	case n < 3:
+		state.Request(1)
+		return p, false, nil
+	case n == 6:
+		return nil, false, pars.NewError("too many quotes", state.Position())
+	}
+	for i := 3; i < n; i++ {
+		p = append(p, q)
+	}
+	pars.Skip(state, n)
+	return p, true, nil
+}
+
+// multiline matches a multi-line string after the opening delimiter. A
+// newline immediately following the delimiter is trimmed.
+func multiline(state *pars.State, q byte) (string, error) {
+	pars.Skip(state, 3)
+	if _, err := newline(state); err != nil {
+		return "", err
+	}
+	p := []byte{}
+	for {
+		r, err := peekRune(state)
+		switch {
+		case err != nil:
+			if _, ok := err.(pars.Error); ok {
+				return "", err
+			}
+			return "", pars.NewError("unterminated string", state.Position())
+		case r == rune(q):
+			var ok bool
+			if p, ok, err = closingQuotes(state, q, p); err != nil || ok {
+				return string(p), err
+			}
+		case r == '\\' && q == '"':
+			state.Advance()
+			if !continuation(state) {
+				if p, err = escape(state, p); err != nil {
+					return "", err
+				}
+			}
+			continue
+		case r == '\n' || r == '\r':
+			if _, err := newline(state); err != nil {
+				return "", err
+			}
+			p = append(p, '\n')
+			continue
+		case isControl(r):
+			return "", pars.NewError("invalid control character in string", state.Position())
+		}
+		state.Advance()
+		p = append(p, string(r)...)
+	}
+}
+
+// continuation matches the rest of a line ending backslash, which trims the
+// following whitespace and newlines.
+func continuation(state *pars.State) bool {
+	state.Push()
+	skipSpace(state)
+	if ok, err := newline(state); !ok || err != nil {
+		state.Pop()
+		return false
+	}
+	state.Drop()
+	for {
+		skipSpace(state)
+		if ok, _ := newline(state); !ok {
+			return true
+		}
+	}
+}
+
+// str matches any of the four kinds of strings and sets the Value to the
+// decoded string.
+func str(state *pars.State, result *pars.Result) error {
+	q, err := pars.Next(state)
+	if err != nil {
+		return pars.NewError(unexpectedEOF, state.Position())
+	}
+	var s string
+	switch {
+	case peekString(state, strings.Repeat(string(q), 3)):
+		s, err = multiline(state, q)
+	case q == '"':
+		s, err = basicString(state)
+	default:
+		s, err = literalString(state)
+	}
+	if err != nil {
+		return err
+	}
+	result.SetValue(s)
+	return nil
+}
+
+// positioned creates a Parser which will match the given Parser and call f
+// with the result and the position where the match began. An error returned
+// by f is reported at that position unless it is already a pars.Error.
+func positioned(q interface{}, f func(result *pars.Result, pos pars.Position) error) pars.Parser {
+	p := pars.AsParser(q)
+	return func(state *pars.State, result *pars.Result) error {
+		pos := state.Position()
+		if err := p(state, result); err != nil {
+			return err
+		}
+		if err := f(result, pos); err != nil {
+			if _, ok := err.(pars.Error); ok {
+				return err
+			}
+			return pars.NewError(err.Error(), pos)
+		}
+		return nil
+	}
+}
+
+// keyPart is a simple key of a dotted key with its position.
+type keyPart struct {
+	name string
+	pos  pars.Position
+}
+
+var bareKey = pars.Word(isBareKey)
+
+// simpleKey matches a bare, basic string, or literal string key and sets the
+// Value to a keyPart.
+var simpleKey = positioned(func(state *pars.State, result *pars.Result) error {
+	c, err := pars.Next(state)
+	var s string
+	switch {
+	case err != nil:
+		return pars.NewError(unexpectedEOF, state.Position())
+	case c == '"':
+		s, err = basicString(state)
+	case c == '\'':
+		s, err = literalString(state)
+	case !isBareKey(c):
+		return pars.NewError("expected a key", state.Position())
+	default:
+		bareKey(state, result)
+		s = string(result.Token)
+	}
+	if err != nil {
+		return err
+	}
+	result.SetValue(s)
+	return nil
+}, func(result *pars.Result, pos pars.Position) error {
+	result.SetValue(keyPart{result.Value.(string), pos})
+	return nil
+})
+
+// key matches a dotted key and sets the Value to its []keyPart.
+var key = pars.Delim(simpleKey, pars.Seq(space, '.', space)).Map(func(result *pars.Result) error {
+	parts := make([]keyPart, len(result.Children))
+	for i, child := range result.Children {
+		parts[i] = child.Value.(keyPart)
+	}
+	result.SetValue(parts)
+	return nil
+})
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+// digits tests if the string consists of digits satisfying the given function
+// where each underscore is surrounded by digits.
+func digits(s string, digit func(byte) bool) bool {
+	if s == "" || s[0] == '_' || s[len(s)-1] == '_' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '_':
+			if s[i-1] == '_' {
+				return false
+			}
+		case !digit(s[i]):
+			return false
+		}
+	}
+	return true
+}
+
+var prefixes = []struct {
+	prefix string
+	base   int
+	digit  func(byte) bool
+}{
+	{"0x", 16, func(c byte) bool { return hexValue(c) >= 0 }},
+	{"0o", 8, func(c byte) bool { return '0' <= c && c <= '7' }},
+	{"0b", 2, func(c byte) bool { return c == '0' || c == '1' }},
+}
+
+// number converts an integer or a float. A number is defined to be as follows
+// in EBNF, where the digits may be separated by single underscores:
+//
+//   integer  = [ `+` | `-` ], ( `0` | ( digit - `0` ), { digit } )
+//   prefixed = `0x`, hexdigit, { hexdigit } | `0o`, octdigit, { octdigit }
+//            | `0b`, bindigit, { bindigit }
+//   fraction = `.`, digit, { digit }
+//   exponent = ( `e` | `E` ), [ `+` | `-` ], digit, { digit }
+//   float    = integer, ( fraction, [ exponent ] | exponent )
+//            | [ `+` | `-` ], ( `inf` | `nan` )
+func number(s string) (interface{}, error) {
+	sign, rest := "", s
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		sign, rest = s[:1], s[1:]
+	}
+	switch rest {
+	case "inf":
+		if sign == "-" {
+			return math.Inf(-1), nil
+		}
+		return math.Inf(1), nil
+	case "nan":
+		return math.NaN(), nil
+	}
+
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(rest, prefix.prefix) {
+			if sign != "" || !digits(rest[2:], prefix.digit) {
+				return nil, fmt.Errorf("invalid number %q", s)
+			}
+			n, err := strconv.ParseInt(strings.Replace(rest[2:], "_", "", -1), prefix.base, 64)
+			if err != nil {
+				return nil, fmt.Errorf("integer %s is out of range", s)
+			}
+			return n, nil
+		}
+	}
+
+	integer, fraction, exponent := rest, "", ""
+	if i := strings.IndexAny(integer, "eE"); i >= 0 {
+		integer, exponent = integer[:i], integer[i+1:]
+		if exponent == "" {
+			return nil, fmt.Errorf("invalid number %q", s)
+		}
+		if exponent[0] == '+' || exponent[0] == '-' {
+			exponent = exponent[1:]
+		}
+		if !digits(exponent, isDigit) {
+			return nil, fmt.Errorf("invalid number %q", s)
+		}
+	}
+	if i := strings.IndexByte(integer, '.'); i >= 0 {
+		integer, fraction = integer[:i], integer[i+1:]
+		if !digits(fraction, isDigit) {
+			return nil, fmt.Errorf("invalid number %q", s)
+		}
+	}
+	if !digits(integer, isDigit) || (integer[0] == '0' && len(integer) > 1) {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+
+	clean := strings.Replace(s, "_", "", -1)
+	if len(integer) == len(rest) {
+		n, err := strconv.ParseInt(clean, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("integer %s is out of range", s)
+		}
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(clean, 64)
+	if err != nil {
+		return nil, fmt.Errorf("float %s is out of range", s)
+	}
+	return f, nil
+}
+
+func isNumberByte(c byte) bool {
+	return isBareKey(c) || c == '+' || c == '.'
+}
+
+// scalar matches an integer or a float.
+var scalar = positioned(pars.Word(isNumberByte), func(result *pars.Result, pos pars.Position) error {
+	v, err := number(string(result.Token))
+	if err != nil {
+		return err
+	}
+	result.SetValue(v)
+	return nil
+})
+
+// MaxDepth is the maximum nesting of arrays and inline tables.
+const MaxDepth = 10000
+
+var (
+	// space matches the spaces and tabs within a line.
+	space = pars.Many(pars.Byte(' ', '\t'))
+
+	// comma matches the comma between the elements of an array, which may
+	// be surrounded by newlines and comments.
+	comma = pars.Seq(blank, ',', blank)
+
+	// separator matches the comma between the pairs of an inline table.
+	separator = pars.Seq(space, ',', space)
+)
+
+// pair is a key/value pair. An inline table is kept as a *table so that the
+// positions of its keys are kept.
+type pair struct {
+	keys  []keyPart
+	value interface{}
+}
+
+// keyValue matches a key/value pair and sets the Value to a pair.
+var keyValue = pars.Seq(key, space, '=', space, &value).Map(func(result *pars.Result) error {
+	keys, v := result.Children[0].Value.([]keyPart), result.Children[4].Value
+	result.SetValue(pair{keys, v})
+	return nil
+})
+
+// elements returns the results of the elements matched by a container.
+func elements(result *pars.Result) []pars.Result {
+	if body := result.Children[2]; body.Children != nil {
+		return body.Children[0].Children
+	}
+	return []pars.Result{}
+}
+
+// array matches an array, which may span multiple lines and contain comments,
+// and sets the Value to a []interface{}. The elements are followed by an
+// alternative which matches a comma and an element again. It never matches as
+// the elements would have included it, but it reports an error in an element
+// after a comma where the error occurs rather than at the comma.
+var array = pars.Nested(pars.Seq('[', blank, pars.Any(']', pars.Seq(
+	pars.Delim(&value, comma),
+	pars.Any(pars.Seq(pars.Maybe(comma), blank, ']'), pars.Seq(comma, &value)),
+))).Map(func(result *pars.Result) error {
+	items := elements(result)
+	v := make([]interface{}, len(items))
+	for i, item := range items {
+		v[i] = item.Value
+		if t, ok := v[i].(*table); ok {
+			v[i] = t.values
+		}
+	}
+	result.SetValue(v)
+	return nil
+}), MaxDepth)
+
+// inline matches an inline table, which must be on a single line, and sets
+// the Value to a *table. The pairs are followed by an alternative reporting
+// an error after a comma as with array.
+var inline = pars.Nested(positioned(pars.Seq('{', space, pars.Any('}', pars.Seq(
+	pars.Delim(keyValue, separator),
+	pars.Any(pars.Seq(space, '}'), pars.Seq(separator, keyValue)),
+))), func(result *pars.Result, pos pars.Position) error {
+	t := newTable(kindInline, pos)
+	for _, item := range elements(result) {
+		if err := t.set(item.Value.(pair)); err != nil {
+			return err
+		}
+	}
+	result.SetValue(t)
+	return nil
+}), MaxDepth)
+
+var (
+	trueValue  = pars.String("true").Bind(true)
+	falseValue = pars.String("false").Bind(false)
+)
+
+// value matches any value. It is set to dispatch in init since the arrays and
+// inline tables which dispatch matches refer to it in turn.
+var value pars.Parser
+
+func init() {
+	value = dispatch
+}
+
+// dispatch matches a value by the parser for its first byte, so an error is
+// reported where the value is malformed rather than at its start.
+func dispatch(state *pars.State, result *pars.Result) error {
+	c, err := pars.Next(state)
+	if err != nil {
+		return pars.NewError(unexpectedEOF, state.Position())
+	}
+	switch {
+	case c == '"' || c == '\'':
+		return str(state, result)
+	case c == 't':
+		return trueValue(state, result)
+	case c == 'f':
+		return falseValue(state, result)
+	case c == '[':
+		return array(state, result)
+	case c == '{':
+		return inline(state, result)
+	case isDigit(c) && isDateTime(state):
+		return dateTime(state, result)
+	case c == '+' || c == '-' || c == 'i' || c == 'n' || isDigit(c):
+		return scalar(state, result)
+	default:
+		return pars.NewError("expected a value", state.Position())
+	}
+}
+
+// Value will match any TOML value. Inline tables are converted to
+// map[string]interface{} like the tables of a document.
+func Value(state *pars.State, result *pars.Result) error {
+	state.Push()
+	if err := value(state, result); err != nil {
+		state.Pop()
+		return err
+	}
+	state.Drop()
+	if t, ok := result.Value.(*table); ok {
+		result.SetValue(t.values)
+	}
+	return nil
+}
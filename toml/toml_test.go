@@ -0,0 +1,262 @@
+package toml
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-pars/pars"
+)
+
+func same(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+type m = map[string]interface{}
+type a = []interface{}
+
+var unmarshalTestCases = []struct {
+	name string
+	in   string
+	out  m
+}{
+	{"empty", "", m{}},
+	{"comments", "# comment\n\n  # indented\r\nkey = 1 # trailing\n", m{"key": int64(1)}},
+	{
+		"keys",
+		"bare_key-1 = 1\n\"quoted key\" = 2\n'literal' = 3\n1234 = 4\na . b.'c' = 5\n3.14 = 6\n",
+		m{
+			"bare_key-1": int64(1), "quoted key": int64(2), "literal": int64(3), "1234": int64(4),
+			"a": m{"b": m{"c": int64(5)}}, "3": m{"14": int64(6)},
+		},
+	},
+	{
+		"strings",
+		"basic = \"tab\\t \\\"quote\\\" \\u00e9 \\U0001F600\"\n" +
+			"literal = 'C:\\Users\\nodejs'\n" +
+			"ml = \"\"\"\nRoses\r\nViolets\"\"\"\n" +
+			"trimmed = \"\"\"\\\n    The quick \\\n\n    fox.\\\n    \"\"\"\n" +
+			"quotes = \"\"\"Here are two quotation marks: \"\". Simple.\"\"\"\"\"\n" +
+			"mll = '''\nThe first newline is\ntrimmed in raw strings.\n'''\n" +
+			"mlq = ''''That,' she said.''''\n",
+		m{
+			"basic":   "tab\t \"quote\" é 😀",
+			"literal": `C:\Users\nodejs`,
+			"ml":      "Roses\nViolets",
+			"trimmed": "The quick fox.",
+			"quotes":  `Here are two quotation marks: "". Simple.""`,
+			"mll":     "The first newline is\ntrimmed in raw strings.\n",
+			"mlq":     "'That,' she said.'",
+		},
+	},
+	{
+		"integers",
+		"a = +99\nb = -17\nc = 0\nd = 1_000\ne = 0xDEAD_beef\nf = 0o755\ng = 0b1101\nh = -9223372036854775808\n",
+		m{
+			"a": int64(99), "b": int64(-17), "c": int64(0), "d": int64(1000),
+			"e": int64(0xdeadbeef), "f": int64(0755), "g": int64(13), "h": int64(math.MinInt64),
+		},
+	},
+	{
+		"floats",
+		"a = +1.0\nb = 3.1415\nc = -0.01\nd = 5e+22\ne = 1e06\nf = -2E-2\ng = 6.626e-34\nh = 224_617.445_991\ni = inf\nj = -inf\n",
+		m{
+			"a": 1.0, "b": 3.1415, "c": -0.01, "d": 5e+22, "e": 1e06, "f": -2e-2, "g": 6.626e-34,
+			"h": 224617.445991, "i": math.Inf(1), "j": math.Inf(-1),
+		},
+	},
+	{
+		"date-times",
+		"odt1 = 1979-05-27T07:32:00Z\nodt2 = 1979-05-27T00:32:00.999999-07:00\nodt3 = 1979-05-27 07:32:00z\n" +
+			"ldt = 1979-05-27T07:32:00.5\nld = 1979-05-27\nlt = 00:32:00.999999\n",
+		m{
+			"odt1": time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+			"odt2": time.Date(1979, 5, 27, 0, 32, 0, 999999000, time.FixedZone("", -7*3600)),
+			"odt3": time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+			"ldt":  LocalDateTime{LocalDate{1979, 5, 27}, LocalTime{7, 32, 0, 500000000}},
+			"ld":   LocalDate{1979, 5, 27},
+			"lt":   LocalTime{0, 32, 0, 999999000},
+		},
+	},
+	{
+		"arrays",
+		"a = [ 1, 2, 3 ]\nb = [ \"x\", 'y', ]\nc = [ [ 1, 2 ], [\"a\", 1.5] ]\nd = [\n  1, # one\n  2\n]\ne = []\nf = [ { x = 1 }, { y = [2] } ]\n",
+		m{
+			"a": a{int64(1), int64(2), int64(3)}, "b": a{"x", "y"},
+			"c": a{a{int64(1), int64(2)}, a{"a", 1.5}}, "d": a{int64(1), int64(2)}, "e": a{},
+			"f": a{m{"x": int64(1)}, m{"y": a{int64(2)}}},
+		},
+	},
+	{
+		"tables",
+		"[table-1]\nkey1 = \"some string\"\n\n[ dog . \"tater.man\" ]\ntype.name = \"pug\"\n\n[x.y.z.w]\n[x]\n[fruit]\napple.color = \"red\"\n[fruit.apple.texture]\nsmooth = true\n",
+		m{
+			"table-1": m{"key1": "some string"},
+			"dog":     m{"tater.man": m{"type": m{"name": "pug"}}},
+			"x":       m{"y": m{"z": m{"w": m{}}}},
+			"fruit":   m{"apple": m{"color": "red", "texture": m{"smooth": true}}},
+		},
+	},
+	{
+		"inline tables",
+		"name = { first = \"Tom\", last = \"Preston-Werner\" }\npoint = {x=1,y=2}\nanimal = { type.name = \"pug\" }\nempty = {}\n",
+		m{
+			"name":   m{"first": "Tom", "last": "Preston-Werner"},
+			"point":  m{"x": int64(1), "y": int64(2)},
+			"animal": m{"type": m{"name": "pug"}},
+			"empty":  m{},
+		},
+	},
+	{
+		"arrays of tables",
+		"[[fruits]]\nname = \"apple\"\n[fruits.physical]\ncolor = \"red\"\n[[fruits.varieties]]\nname = \"red delicious\"\n[[fruits.varieties]]\nname = \"granny smith\"\n\n[[fruits]]\nname = \"banana\"\n[[fruits.varieties]]\nname = \"plantain\"\n",
+		m{"fruits": a{
+			m{
+				"name":      "apple",
+				"physical":  m{"color": "red"},
+				"varieties": a{m{"name": "red delicious"}, m{"name": "granny smith"}},
+			},
+			m{"name": "banana", "varieties": a{m{"name": "plantain"}}},
+		}},
+	},
+}
+
+func TestUnmarshal(t *testing.T) {
+	for _, tt := range unmarshalTestCases {
+		out, err := Unmarshal(strings.NewReader(tt.in))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !same(out, tt.out) {
+			t.Errorf("%s: Unmarshal() = %#v, want %#v", tt.name, out, tt.out)
+		}
+	}
+
+	out, err := Unmarshal(strings.NewReader("a = nan\nb = -nan\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"a", "b"} {
+		if f, ok := out[k].(float64); !ok || !math.IsNaN(f) {
+			t.Errorf("%s = %#v, want NaN", k, out[k])
+		}
+	}
+}
+
+var unmarshalErrorTestCases = []struct {
+	in  string
+	pos pars.Position
+}{
+	{"a = 1\na = 2\n", pars.Position{Line: 1, Byte: 0}},
+	{"a = 1 b = 2\n", pars.Position{Line: 0, Byte: 6}},
+	{"a =\n", pars.Position{Line: 0, Byte: 3}},
+	{"= 1\n", pars.Position{Line: 0, Byte: 0}},
+	{"a = 01\n", pars.Position{Line: 0, Byte: 4}},
+	{"a = 1__0\n", pars.Position{Line: 0, Byte: 4}},
+	{"a = 1.\n", pars.Position{Line: 0, Byte: 4}},
+	{"a = +0x1\n", pars.Position{Line: 0, Byte: 4}},
+	{"a = 9223372036854775808\n", pars.Position{Line: 0, Byte: 4}},
+	{"a = 1979-02-29\n", pars.Position{Line: 0, Byte: 4}},
+	{"a = 1979-05-27T25:00:00\n", pars.Position{Line: 0, Byte: 4}},
+	{"a = \"\\x\"\n", pars.Position{Line: 0, Byte: 6}},
+	{"a = \"\\uD800\"\n", pars.Position{Line: 0, Byte: 7}},
+	{"a = \"open\n", pars.Position{Line: 0, Byte: 9}},
+	{"a = \"\"\"x\"\"\"\"\"\"\n", pars.Position{Line: 0, Byte: 8}},
+	{"a = [1 2]\n", pars.Position{Line: 0, Byte: 7}},
+	{"a = {x = 1,}\n", pars.Position{Line: 0, Byte: 11}},
+	{"a = {x = 1\n}\n", pars.Position{Line: 0, Byte: 10}},
+	{"# bad \x01 comment\n", pars.Position{Line: 0, Byte: 6}},
+	{"a = 1\r", pars.Position{Line: 0, Byte: 5}},
+	{"[a]\n[a]\n", pars.Position{Line: 1, Byte: 1}},
+	{"[a]\nb = 1\n[a.b]\n", pars.Position{Line: 2, Byte: 3}},
+	{"[fruit]\napple.color = 1\n[fruit.apple]\n", pars.Position{Line: 2, Byte: 7}},
+	{"[a.b.c]\nz = 9\n[a]\nb.c.t = 1\n", pars.Position{Line: 3, Byte: 0}},
+	{"a = {x = 1}\n[a.y]\n", pars.Position{Line: 1, Byte: 1}},
+	{"a = {x = 1}\na.y = 2\n", pars.Position{Line: 1, Byte: 0}},
+	{"a = []\n[[a]]\n", pars.Position{Line: 1, Byte: 2}},
+	{"[[a]]\n[a]\n", pars.Position{Line: 1, Byte: 1}},
+	{"[a]\n[[a]]\n", pars.Position{Line: 1, Byte: 2}},
+	{"[a]]\n", pars.Position{Line: 0, Byte: 3}},
+	{"[[a]\n", pars.Position{Line: 0, Byte: 3}},
+}
+
+func TestUnmarshalError(t *testing.T) {
+	for _, tt := range unmarshalErrorTestCases {
+		_, err := Unmarshal(strings.NewReader(tt.in))
+		var pe pars.Error
+		if !errors.As(err, &pe) || pe.Position() != tt.pos {
+			t.Errorf("Unmarshal(%q) = %v, want error at %v", tt.in, err, tt.pos)
+		}
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	for _, tt := range []struct {
+		in  string
+		pos pars.Position
+	}{
+		{"a = " + strings.Repeat("[", 20<<20), pars.Position{Line: 0, Byte: 4 + MaxDepth}},
+		{"a = " + strings.Repeat("{b = ", 1<<20), pars.Position{Line: 0, Byte: 4 + 5*MaxDepth}},
+	} {
+		_, err := Unmarshal(strings.NewReader(tt.in))
+		var pe pars.Error
+		if !errors.As(err, &pe) || pe.Position() != tt.pos {
+			t.Errorf("Unmarshal(%.8q...) = %.80v, want error at %v", tt.in, err, tt.pos)
+		}
+	}
+}
+
+func TestDocumentPosition(t *testing.T) {
+	in := `title = "example"
+
+[owner]
+name = "Tom"
+dob = 1979-05-27T07:32:00-08:00
+
+[[servers]]
+host = "alpha"
+
+[[servers]]
+host = "beta"
+limits = { cpu = 2 }
+`
+	doc, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		keys []string
+		pos  pars.Position
+	}{
+		{[]string{"title"}, pars.Position{Line: 0, Byte: 0}},
+		{[]string{"owner"}, pars.Position{Line: 2, Byte: 1}},
+		{[]string{"owner", "dob"}, pars.Position{Line: 4, Byte: 0}},
+		{[]string{"servers"}, pars.Position{Line: 6, Byte: 2}},
+		{[]string{"servers", "1"}, pars.Position{Line: 9, Byte: 2}},
+		{[]string{"servers", "1", "host"}, pars.Position{Line: 10, Byte: 0}},
+		{[]string{"servers", "1", "limits", "cpu"}, pars.Position{Line: 11, Byte: 11}},
+	} {
+		if pos, ok := doc.Position(tt.keys...); !ok || pos != tt.pos {
+			t.Errorf("Position(%q) = %v, %t, want %v", tt.keys, pos, ok, tt.pos)
+		}
+	}
+	for _, keys := range [][]string{{"missing"}, {"servers", "2", "host"}, {"title", "x"}} {
+		if pos, ok := doc.Position(keys...); ok {
+			t.Errorf("Position(%q) = %v, want none", keys, pos)
+		}
+	}
+}
+
+func TestValue(t *testing.T) {
+	result, err := pars.Seq(Value, pars.End).Parse(pars.FromString(`{ a = [1, "x"] }`))
+	if err != nil || !same(result.Children[0].Value, m{"a": a{int64(1), "x"}}) {
+		t.Errorf("Value = %#v, %v", result.Children[0].Value, err)
+	}
+	if s := (LocalDateTime{LocalDate{2020, 1, 2}, LocalTime{3, 4, 5, 600}}).String(); s != "2020-01-02T03:04:05.000000600" {
+		t.Errorf("String() = %q", s)
+	}
+}